@@ -15,11 +15,6 @@ type SimVar struct {
 	Name, Unit string
 }
 
-type SimObjectValue struct {
-	simconnect.RecvSimObjectDataByType
-	Value float64
-}
-
 var (
 	requestDataInterval = time.Millisecond * 250
 	receiveDataInterval = time.Millisecond * 1
@@ -97,19 +92,16 @@ func HandleEvents(done chan bool) {
 			}
 
 		case <-recvDataTicker.C:
-			ppData, r1, err := simConnect.GetNextDispatch()
-			if r1 < 0 {
-				if uint32(r1) != simconnect.EFail {
-					fmt.Printf("GetNextDispatch error: %d %s\n", r1, err)
-					return
-				}
-				if ppData == nil {
-					break
-				}
+			msg, err := simConnect.NextMessage()
+			if err != nil {
+				fmt.Printf("GetNextDispatch error: %s\n", err)
+				return
+			}
+			if msg == nil {
+				break
 			}
 
-			recv := *(*simconnect.Recv)(ppData)
-			switch recv.ID {
+			switch msg.ID() {
 			case simconnect.RecvIDOpen:
 				fmt.Println("Connected.")
 
@@ -118,14 +110,27 @@ func HandleEvents(done chan bool) {
 				done <- true
 
 			case simconnect.RecvIDException:
-				recvException := *(*simconnect.RecvException)(ppData)
+				recvException, err := msg.Exception()
+				if err != nil {
+					fmt.Println(err)
+					break
+				}
 				fmt.Println("Something exceptional happened.", recvException.Exception)
 
 			case simconnect.RecvIDSimObjectDataByType:
-				data := *(*SimObjectValue)(ppData)
+				data, block, err := msg.SimObjectDataByType()
+				if err != nil {
+					fmt.Println(err)
+					break
+				}
+				var value float64
+				if err := simconnect.Unmarshal(block, &value); err != nil {
+					fmt.Println(err)
+					break
+				}
 				for _, simVar := range simVars {
 					if simVar.DefineID == data.DefineID {
-						fmt.Printf("[%d] %s %s %f\n", data.RequestID, simVar.Name, simVar.Unit, data.Value)
+						fmt.Printf("[%d] %s %s %f\n", data.RequestID, simVar.Name, simVar.Unit, value)
 						break
 					}
 				}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -23,14 +25,12 @@ type Var struct {
 type App struct {
 	mate          *simconnect.SimMate
 	vars          []*Var
-	done          chan interface{}
 	counter       uint32
 	eventListener *simconnect.EventListener
 }
 
 var (
 	requestDataInterval = time.Millisecond * 250
-	receiveDataInterval = time.Millisecond * 1
 	mate                *simconnect.SimMate
 )
 
@@ -51,9 +51,6 @@ func main() {
 }
 
 func (app *App) run() {
-	app.done = make(chan interface{}, 1)
-	defer close(app.done)
-
 	app.eventListener = &simconnect.EventListener{
 		OnOpen:      app.OnOpen,
 		OnQuit:      app.OnQuit,
@@ -83,33 +80,17 @@ func (app *App) run() {
 		app.vars = append(app.vars, &Var{defineID, request.Name})
 	}
 
-	go app.handleTerminationSignal()
-
-	stop := make(chan interface{}, 1)
-	defer close(stop)
-	go app.mate.HandleEvents(requestDataInterval, receiveDataInterval, stop, app.eventListener)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	<-app.done
-	stop <- true
+	err := app.mate.Run(ctx, requestDataInterval, app.eventListener)
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, simconnect.ErrQuit) {
+		fmt.Println("Error:", err)
+	}
 
 	app.mate.Close()
 }
 
-func (app *App) handleTerminationSignal() {
-	sigterm := make(chan os.Signal, 1)
-	defer close(sigterm)
-
-	signal.Notify(sigterm, os.Interrupt, syscall.SIGTERM)
-
-	for {
-		select {
-		case <-sigterm:
-			app.done <- true
-			return
-		}
-	}
-}
-
 func (app *App) OnOpen(applName, applVersion, applBuild, simConnectVersion, simConnectBuild string) {
 	fmt.Println("\nConnected.")
 	flightSimVersion := fmt.Sprintf(
@@ -121,7 +102,6 @@ func (app *App) OnOpen(applName, applVersion, applBuild, simConnectVersion, simC
 
 func (app *App) OnQuit() {
 	fmt.Println("Disconnected.")
-	app.done <- true
 }
 
 func (app *App) OnEventID(eventID simconnect.DWord) {
@@ -133,11 +113,13 @@ func (app *App) OnException(exceptionCode simconnect.DWord) {
 }
 
 func (app *App) OnDataReady() {
-	fmt.Printf("\nUpdate %d...\n", app.counter)
+	// all values of a snapshot were received in the same request round
+	snapshot := app.mate.Snapshot()
+	fmt.Printf("\nUpdate %d (snapshot %d)...\n", app.counter, snapshot.Sequence)
 	app.counter++
 	for _, v := range app.vars {
-		value, _, ok := app.mate.SimVarValueAndDataType(v.DefineID)
-		if !ok || value == nil {
+		value, ok := snapshot.Value(v.DefineID)
+		if !ok {
 			continue
 		}
 		fmt.Printf("%s = %v\n", v.Name, value)
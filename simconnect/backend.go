@@ -0,0 +1,81 @@
+package simconnect
+
+import (
+	"unsafe"
+)
+
+// Backend is the transport SimConnect talks through.
+// There is one method per SimConnect function, minus the connection handle which is owned by the backend.
+// The DLL backend loaded by Initialize is the default; ScriptedBackend runs without a simulator.
+type Backend interface {
+	// General
+	Open(name string) error
+	Close() error
+	// GetNextDispatch returns the next message, its size in bytes and the raw HRESULT.
+	// If there is no message pending, r1 is E_FAIL and ppData is nil.
+	GetNextDispatch() (ppData unsafe.Pointer, size DWord, r1 int32, err error)
+	RequestSystemState(requestID DWord, state string) error
+	MapClientEventToSimEvent(eventID DWord, eventName string) error
+	SubscribeToSystemEvent(eventID DWord, systemEventName string) error
+	SetSystemEventState(eventID, state DWord) error
+	UnsubscribeFromSystemEvent(eventID DWord) error
+	SetNotificationGroupPriority(groupID, priority DWord) error
+	Text(text string, textType DWord, timeSeconds float32, eventID DWord) error
+	// Events and Data
+	RequestDataOnSimObject(requestID, defineID, objectID, period, flags DWord) error
+	RequestDataOnSimObjectType(requestID, defineID, radius, simobjectType DWord) error
+	AddClientEventToNotificationGroup(groupID, eventID DWord, maskable bool) error
+	RemoveClientEvent(groupID, eventID DWord) error
+	TransmitClientEvent(objectID uint32, eventID uint32, data DWord, groupID DWord, flags DWord) error
+	MapClientDataNameToID(clientDataName string, clientDataID DWord) error
+	RequestClientData(clientDataID, requestID, defineID, period, flags DWord) error
+	CreateClientData(clientDataID, size, flags DWord) error
+	AddToClientDataDefinition(defineID, offset, sizeOrType DWord) error
+	AddToDataDefinition(defineID DWord, datumName string, unitName string, datumType DWord, epsilon float32, datumID DWord) error
+	SetClientData(clientDataID, defineID, flags DWord, unitSize DWord, buf unsafe.Pointer) error
+	SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize DWord, buf unsafe.Pointer) error
+	ClearClientDataDefinition(defineID DWord) error
+	ClearDataDefinition(defineID DWord) error
+	MapInputEventToClientEvent(groupID DWord, inputDefinition string, downEventID DWord) error
+	RequestNotificationGroup(groupID DWord) error
+	ClearInputGroup(groupID DWord) error
+	ClearNotificationGroup(groupID DWord) error
+	SetInputGroupPriority(groupID, priority DWord) error
+	SetInputGroupState(groupID, state DWord) error
+	RemoveInputEvent(groupID DWord, inputDefinition string) error
+	// AI Objects
+	AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber int, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error
+	AICreateNonATCAircraft(containerTitle, tailNumber string, initPos InitPosition, requestID DWord) error
+	AICreateParkedATCAircraft(containerTitle, tailNumber, airportID string, requestID DWord) error
+	AICreateSimulatedObject(containerTitle string, initPos InitPosition, requestID DWord) error
+	AIReleaseControl(objectID, requestID DWord) error
+	AIRemoveObject(objectID, requestID DWord) error
+	AISetAircraftFlightPlan(objectID, requestID DWord, flightPlanPath string) error
+	// Flights
+	FlightLoad(fileName string) error
+	FlightSave(fileName, title, description string, flags DWord) error
+	FlightPlanLoad(fileName string) error
+	// Debug
+	GetLastSentPacketID(pdwError *DWord) error
+	// Facilities
+	RequestFacilitiesList(facilityListType, requestID DWord) error
+	SubscribeToFacilities(facilityListType, requestID DWord) error
+	UnsubscribeToFacilities(facilityListType DWord) error
+	// Menu
+	MenuAddItem(menuItem string, menuEventID, data DWord) error
+	MenuAddSubItem(menuEventID DWord, menuItem string, subMenuEventID, data DWord) error
+	MenuDeleteItem(menuEventID DWord) error
+	MenuDeleteSubItem(menuEventID, subMenuEventID DWord) error
+	// Undocumented
+	CameraSetRelative6DOF(deltaX, deltaY, deltaZ, pitchDeg, bankDeg, headingDeg float64) error
+	SetSystemState(state string, integerValue DWord, floatValue float32, stringValue string) error
+}
+
+func copyBuffer(buf unsafe.Pointer, size DWord) []byte {
+	if buf == nil || size == 0 {
+		return []byte{}
+	}
+	data := make([]byte, size)
+	copy(data, unsafe.Slice((*byte)(buf), size))
+	return data
+}
@@ -0,0 +1,903 @@
+package simconnect
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// dllBackend is the Backend that calls into SimConnect.dll.
+// The library has to be loaded with Initialize before any of its methods are used.
+type dllBackend struct {
+	handle unsafe.Pointer
+}
+
+func (dll *dllBackend) Open(name string) error {
+	// SimConnect_Open(
+	// 	HANDLE * phSimConnect,
+	// 	LPCSTR szName,
+	// 	HWND hWnd,
+	// 	DWORD UserEventWin32,
+	// 	HANDLE hEventHandle,
+	// 	DWORD ConfigIndex)
+
+	const hwnd DWord = 0
+	const userEventWin32 = WmUserSimConnect
+	const eventHandle DWord = 0
+	const configIndex DWord = 0 // TODO: make this a function parameter
+
+	namePtr, namePtrErr := toUTF16Ptr(name)
+	if namePtrErr != nil {
+		return namePtrErr
+	}
+
+	args := []uintptr{
+		uintptr(unsafe.Pointer(&dll.handle)),
+		namePtr,
+		uintptr(hwnd),
+		uintptr(userEventWin32),
+		uintptr(eventHandle),
+		uintptr(configIndex),
+	}
+	return callProc(scOpen, args...)
+}
+
+func (dll *dllBackend) Close() error {
+	// SimConnect_Close(
+	//  HANDLE hSimConnect)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+	}
+	return callProc(scClose, args...)
+}
+
+func (dll *dllBackend) GetNextDispatch() (unsafe.Pointer, DWord, int32, error) {
+	// SimConnect_GetNextDispatch(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_RECV ** ppData,
+	// 	DWORD * pcbData)
+
+	var ppData unsafe.Pointer
+	var ppDataLength DWord
+	r1, err := procCall(scGetNextDispatch,
+		uintptr(dll.handle),
+		uintptr(unsafe.Pointer(&ppData)),
+		uintptr(unsafe.Pointer(&ppDataLength)),
+	)
+	return ppData, ppDataLength, int32(r1), err
+}
+
+func (dll *dllBackend) RequestSystemState(requestID DWord, state string) error {
+	// SimConnect_RequestSystemState(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID,
+	//  const char * szState)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(requestID),
+		uintptr(toCharPtr(state)),
+	}
+	return callProc(scRequestSystemState, args...)
+}
+
+func (dll *dllBackend) MapClientEventToSimEvent(eventID DWord, eventName string) error {
+	// SimConnect_MapClientEventToSimEvent(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//  const char * EventName = "")
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(eventID),
+		toCharPtr(eventName),
+	}
+	return callProc(scMapClientEventToSimEvent, args...)
+}
+
+func (dll *dllBackend) SubscribeToSystemEvent(eventID DWord, systemEventName string) error {
+	// SimConnect_SubscribeToSystemEvent(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//  const char * SystemEventName)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(eventID),
+		toCharPtr(systemEventName),
+	}
+	return callProc(scSubscribeToSystemEvent, args...)
+}
+
+func (dll *dllBackend) SetSystemEventState(eventID, state DWord) error {
+	// SIMCONNECTAPI SimConnect_SetSystemEventState(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//  SIMCONNECT_STATE dwState)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(eventID),
+		uintptr(state),
+	}
+	return callProc(scSetSystemEventState, args...)
+}
+
+func (dll *dllBackend) UnsubscribeFromSystemEvent(eventID DWord) error {
+	// SimConnect_UnsubscribeFromSystemEvent(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_EVENT_ID EventID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(eventID),
+	}
+	return callProc(scUnsubscribeFromSystemEvent, args...)
+}
+
+func (dll *dllBackend) SetNotificationGroupPriority(groupID, priority DWord) error {
+	// SimConnect_SetNotificationGroupPriority(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_NOTIFICATION_GROUP_ID GroupID,
+	//  DWORD uPriority)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		uintptr(priority),
+	}
+	return callProc(scSetNotificationGroupPriority, args...)
+}
+
+func (dll *dllBackend) Text(text string, textType DWord, timeSeconds float32, eventID DWord) error {
+	// SimConnect_Text(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_TEXT_TYPE type,
+	// 	float fTimeSeconds,
+	// 	SIMCONNECT_CLIENT_EVENT_ID EventID,
+	// 	DWORD cbUnitSize,
+	// 	void * pDataSet)
+
+	size := len(text)
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(textType),
+		uintptr(timeSeconds),
+		uintptr(eventID),
+		uintptr(DWord(size)),
+		toCharPtr(text),
+	}
+	return callProc(scText, args...)
+}
+
+func (dll *dllBackend) RequestDataOnSimObject(requestID, defineID, objectID, period, flags DWord) error {
+	// SimConnect_RequestDataOnSimObject(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID,
+	//  SIMCONNECT_DATA_DEFINITION_ID DefineID,
+	//  SIMCONNECT_OBJECT_ID ObjectID,
+	//  SIMCONNECT_PERIOD Period,
+	//  SIMCONNECT_DATA_REQUEST_FLAG Flags = 0,
+	//  DWORD origin = 0,
+	//  DWORD interval = 0,
+	//  DWORD limit = 0)
+
+	const origin DWord = 0
+	const interval DWord = 0
+	const limit DWord = 0
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(requestID),
+		uintptr(defineID),
+		uintptr(objectID),
+		uintptr(period),
+		uintptr(flags),
+		uintptr(origin),
+		uintptr(interval),
+		uintptr(limit),
+	}
+	return callProc(scRequestDataOnSimObject, args...)
+}
+
+func (dll *dllBackend) RequestDataOnSimObjectType(requestID, defineID, radius, simobjectType DWord) error {
+	// SimConnect_RequestDataOnSimObjectType(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_DATA_REQUEST_ID RequestID,
+	// 	SIMCONNECT_DATA_DEFINITION_ID DefineID,
+	// 	DWORD dwRadiusMeters,
+	// 	SIMCONNECT_SIMOBJECT_TYPE type)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(requestID),
+		uintptr(defineID),
+		uintptr(radius),
+		uintptr(simobjectType),
+	}
+	return callProc(scRequestDataOnSimObjectType, args...)
+}
+
+func (dll *dllBackend) AddClientEventToNotificationGroup(groupID, eventID DWord, maskable bool) error {
+	// SimConnect_AddClientEventToNotificationGroup(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_NOTIFICATION_GROUP_ID GroupID,
+	//  SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//  BOOL bMaskable = FALSE)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		uintptr(eventID),
+		uintptr(toBoolPtr(maskable)),
+	}
+	return callProc(scAddClientEventToNotificationGroup, args...)
+}
+
+func (dll *dllBackend) RemoveClientEvent(groupID, eventID DWord) error {
+	// SimConnect_RemoveClientEvent(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_NOTIFICATION_GROUP_ID GroupID,
+	//  SIMCONNECT_CLIENT_EVENT_ID EventID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		uintptr(eventID),
+	}
+	return callProc(scRemoveClientEvent, args...)
+}
+
+func (dll *dllBackend) TransmitClientEvent(objectID uint32, eventID uint32, data DWord, groupID DWord, flags DWord) error {
+	// SimConnect_TransmitClientEvent(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_OBJECT_ID ObjectID,
+	//  SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//  DWORD dwData,
+	//  SIMCONNECT_NOTIFICATION_GROUP_ID GroupID,
+	//  SIMCONNECT_EVENT_FLAG Flags)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(objectID),
+		uintptr(eventID),
+		uintptr(data),
+		uintptr(groupID),
+		uintptr(flags),
+	}
+	return callProc(scTransmitClientEvent, args...)
+}
+
+func (dll *dllBackend) MapClientDataNameToID(clientDataName string, clientDataID DWord) error {
+	// SimConnect_MapClientDataNameToID(
+	//  HANDLE hSimConnect,
+	//  const char * szClientDataName,
+	//  SIMCONNECT_CLIENT_DATA_ID ClientDataID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		toCharPtr(clientDataName),
+		uintptr(clientDataID),
+	}
+	return callProc(scMapClientDataNameToID, args...)
+}
+
+func (dll *dllBackend) RequestClientData(clientDataID, requestID, defineID, period, flags DWord) error {
+	// SimConnect_RequestClientData(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_DATA_ID ClientDataID,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID,
+	//  SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID,
+	//  SIMCONNECT_CLIENT_DATA_PERIOD Period = SIMCONNECT_CLIENT_DATA_PERIOD_ONCE,
+	//  SIMCONNECT_CLIENT_DATA_REQUEST_FLAG Flags = 0,
+	//  DWORD origin = 0,
+	//  DWORD interval = 0,
+	//  DWORD limit = 0)
+
+	const origin DWord = 0
+	const interval DWord = 0
+	const limit DWord = 0
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(clientDataID),
+		uintptr(requestID),
+		uintptr(defineID),
+		uintptr(period),
+		uintptr(flags),
+		uintptr(origin),
+		uintptr(interval),
+		uintptr(limit),
+	}
+	return callProc(scRequestClientData, args...)
+}
+
+func (dll *dllBackend) CreateClientData(clientDataID, size, flags DWord) error {
+	// SimConnect_CreateClientData(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_DATA_ID ClientDataID,
+	//  DWORD dwSize,
+	//  SIMCONNECT_CREATE_CLIENT_DATA_FLAG Flags)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(clientDataID),
+		uintptr(size),
+		uintptr(flags),
+	}
+	return callProc(scCreateClientData, args...)
+}
+
+func (dll *dllBackend) AddToClientDataDefinition(defineID, offset, sizeOrType DWord) error {
+	// SimConnect_AddToClientDataDefinition(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID,
+	//  DWORD dwOffset,
+	//  DWORD dwSizeOrType,
+	//  float fEpsilon = 0,
+	//  DWORD DatumID = SIMCONNECT_UNUSED)
+
+	const epsilon float32 = 0
+	const datumID = Unused
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(defineID),
+		uintptr(offset),
+		uintptr(sizeOrType),
+		uintptr(epsilon),
+		uintptr(datumID),
+	}
+	return callProc(scAddToClientDataDefinition, args...)
+}
+
+func (dll *dllBackend) AddToDataDefinition(defineID DWord, datumName string, unitName string, datumType DWord, epsilon float32, datumID DWord) error {
+	// SimConnect_AddToDataDefinition(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_DATA_DEFINITION_ID DefineID,
+	// 	const char * DatumName,
+	// 	const char * UnitsName,
+	// 	SIMCONNECT_DATATYPE DatumType = SIMCONNECT_DATATYPE_FLOAT64,
+	// 	float fEpsilon = 0,
+	// 	DWORD DatumID = SIMCONNECT_UNUSED)
+
+	var unitArg uintptr = 0
+	if len(unitName) > 0 {
+		unitArg = toCharPtr(unitName)
+	}
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(defineID),
+		toCharPtr(datumName),
+		unitArg,
+		uintptr(datumType),
+		uintptr(math.Float32bits(epsilon)), // passed on the stack, so the bits are all it takes
+		uintptr(datumID),
+	}
+	return callProc(scAddToDataDefinition, args...)
+}
+
+func (dll *dllBackend) SetClientData(clientDataID, defineID, flags DWord, unitSize DWord, buf unsafe.Pointer) error {
+	// SimConnect_SetClientData(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_DATA_ID ClientDataID,
+	//  SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID,
+	//  SIMCONNECT_CLIENT_DATA_SET_FLAG Flags,
+	//  DWORD dwReserved,
+	//  DWORD cbUnitSize,
+	//  void * pDataSet)
+
+	const reserved DWord = 0
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(clientDataID),
+		uintptr(defineID),
+		uintptr(flags),
+		uintptr(reserved),
+		uintptr(unitSize),
+		uintptr(buf),
+	}
+	return callProc(scSetClientData, args...)
+
+}
+
+func (dll *dllBackend) SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize DWord, buf unsafe.Pointer) error {
+	// SimConnect_SetDataOnSimObject(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_DATA_DEFINITION_ID DefineID,
+	// 	SIMCONNECT_OBJECT_ID ObjectID,
+	// 	SIMCONNECT_DATA_SET_FLAG Flags,
+	// 	DWORD ArrayCount,
+	// 	DWORD cbUnitSize,
+	// 	void * pDataSet)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(defineID),
+		uintptr(objectID),
+		uintptr(flags),
+		uintptr(arrayCount),
+		uintptr(unitSize),
+		uintptr(buf),
+	}
+	return callProc(scSetDataOnSimObject, args...)
+}
+
+func (dll *dllBackend) ClearClientDataDefinition(defineID DWord) error {
+	// SimConnect_ClearClientDataDefinition(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(defineID),
+	}
+	return callProc(scClearClientDataDefinition, args...)
+}
+
+func (dll *dllBackend) ClearDataDefinition(defineID DWord) error {
+	// SIMCONNECTAPI SimConnect_ClearDataDefinition(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_DATA_DEFINITION_ID DefineID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(defineID),
+	}
+	return callProc(scClearDataDefinition, args...)
+}
+
+func (dll *dllBackend) MapInputEventToClientEvent(groupID DWord, inputDefinition string, downEventID DWord) error {
+	// SimConnect_MapInputEventToClientEvent(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_INPUT_GROUP_ID GroupID,
+	//  const char * szInputDefinition,
+	//  SIMCONNECT_CLIENT_EVENT_ID DownEventID,
+	//  DWORD DownValue = 0,
+	//  SIMCONNECT_CLIENT_EVENT_ID UpEventID = (SIMCONNECT_CLIENT_EVENT_ID)SIMCONNECT_UNUSED,
+	//  DWORD UpValue = 0,
+	//  BOOL bMaskable = FALSE)
+
+	const downValue DWord = 0
+	const upEventID = Unused
+	const upValue DWord = 0
+	const maskable = false
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		toCharPtr(inputDefinition),
+		uintptr(downEventID),
+		uintptr(downValue),
+		uintptr(upEventID),
+		uintptr(upValue),
+		toBoolPtr(maskable),
+	}
+	return callProc(scMapInputEventToClientEvent, args...)
+}
+
+func (dll *dllBackend) RequestNotificationGroup(groupID DWord) error {
+	// SimConnect_RequestNotificationGroup(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_NOTIFICATION_GROUP_ID GroupID,
+	//  DWORD dwReserved = 0,
+	//  DWORD Flags = 0)
+
+	const reserved DWord = 0
+	const flags DWord = 0
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		uintptr(reserved),
+		uintptr(flags),
+	}
+	return callProc(scRequestNotificationGroup, args...)
+}
+
+func (dll *dllBackend) ClearInputGroup(groupID DWord) error {
+	// SimConnect_ClearInputGroup(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_INPUT_GROUP_ID GroupID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+	}
+	return callProc(scClearInputGroup, args...)
+}
+
+func (dll *dllBackend) ClearNotificationGroup(groupID DWord) error {
+	// SimConnect_ClearNotificationGroup(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_NOTIFICATION_GROUP_ID GroupID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+	}
+	return callProc(scClearNotificationGroup, args...)
+}
+
+func (dll *dllBackend) SetInputGroupPriority(groupID, priority DWord) error {
+	// SimConnect_SetInputGroupPriority(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_INPUT_GROUP_ID GroupID,
+	//  DWORD uPriority)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		uintptr(priority),
+	}
+	return callProc(scSetInputGroupPriority, args...)
+}
+
+func (dll *dllBackend) SetInputGroupState(groupID, state DWord) error {
+	// SimConnect_SetInputGroupState(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_INPUT_GROUP_ID GroupID,
+	//  DWORD dwState)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		uintptr(state),
+	}
+	return callProc(scSetInputGroupState, args...)
+}
+
+func (dll *dllBackend) RemoveInputEvent(groupID DWord, inputDefinition string) error {
+	// SimConnect_RemoveInputEvent(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_INPUT_GROUP_ID GroupID,
+	//  const char * szInputDefinition)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(groupID),
+		toCharPtr(inputDefinition),
+	}
+	return callProc(scRemoveInputEvent, args...)
+}
+
+func (dll *dllBackend) AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber int, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error {
+	// SimConnect_AICreateEnrouteATCAircraft(
+	//  HANDLE hSimConnect,
+	//  const char * szContainerTitle,
+	//  const char * szTailNumber,
+	//  int iFlightNumber,
+	//  const char * szFlightPlanPath,
+	//  double dFlightPlanPosition,
+	//  BOOL bTouchAndGo,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(containerTitle)),
+		uintptr(toCharPtr(tailNumber)),
+		uintptr(flightNumber),
+		uintptr(toCharPtr(flightPlanPath)),
+		uintptr(flightPlanPosition),
+		uintptr(toBoolPtr(touchAndGo)),
+		uintptr(requestID),
+	}
+	return callProc(scAICreateEnrouteATCAircraft, args...)
+}
+
+func (dll *dllBackend) AICreateNonATCAircraft(containerTitle, tailNumber string, initPos InitPosition, requestID DWord) error {
+	// SimConnect_AICreateNonATCAircraft(
+	//  HANDLE hSimConnect,
+	//  const char * szContainerTitle,
+	//  const char * szTailNumber,
+	//  SIMCONNECT_DATA_INITPOSITION InitPos,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(containerTitle)),
+		uintptr(toCharPtr(tailNumber)),
+		uintptr(unsafe.Pointer(&initPos)),
+		uintptr(requestID),
+	}
+	return callProc(scAICreateNonATCAircraft, args...)
+}
+
+func (dll *dllBackend) AICreateParkedATCAircraft(containerTitle, tailNumber, airportID string, requestID DWord) error {
+	// TODO: SimConnect_AICreateParkedATCAircraft(
+	//  HANDLE hSimConnect,
+	//  const char * szContainerTitle,
+	//  const char * szTailNumber,
+	//  const char * szAirportID,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(containerTitle)),
+		uintptr(toCharPtr(tailNumber)),
+		uintptr(toCharPtr(airportID)),
+		uintptr(requestID),
+	}
+	return callProc(scAICreateParkedATCAircraft, args...)
+}
+
+func (dll *dllBackend) AICreateSimulatedObject(containerTitle string, initPos InitPosition, requestID DWord) error {
+	// SimConnect_AICreateSimulatedObject(
+	//  HANDLE hSimConnect,
+	//  const char * szContainerTitle,
+	//  SIMCONNECT_DATA_INITPOSITION InitPos,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(containerTitle)),
+		uintptr(unsafe.Pointer(&initPos)),
+		uintptr(requestID),
+	}
+	return callProc(scAICreateSimulatedObject, args...)
+}
+
+func (dll *dllBackend) AIReleaseControl(objectID, requestID DWord) error {
+	// SimConnect_AIReleaseControl(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_OBJECT_ID ObjectID,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(objectID),
+		uintptr(requestID),
+	}
+	return callProc(scAIReleaseControl, args...)
+}
+
+func (dll *dllBackend) AIRemoveObject(objectID, requestID DWord) error {
+	// SimConnect_AIRemoveObject(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_OBJECT_ID ObjectID,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(objectID),
+		uintptr(requestID),
+	}
+	return callProc(scAIRemoveObject, args...)
+}
+
+func (dll *dllBackend) AISetAircraftFlightPlan(objectID, requestID DWord, flightPlanPath string) error {
+	// SimConnect_AISetAircraftFlightPlan(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_OBJECT_ID ObjectID,
+	//  const char * szFlightPlanPath,
+	//  SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(objectID),
+		uintptr(toCharPtr(flightPlanPath)),
+		uintptr(requestID),
+	}
+	return callProc(scAISetAircraftFlightPlan, args...)
+}
+
+func (dll *dllBackend) FlightLoad(fileName string) error {
+	// SimConnect_FlightLoad(
+	//  HANDLE hSimConnect,
+	//  const char * szFileName)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(fileName)),
+	}
+	return callProc(scFlightLoad, args...)
+}
+
+func (dll *dllBackend) FlightSave(fileName, title, description string, flags DWord) error {
+	// SimConnect_FlightSave(
+	//  HANDLE hSimConnect,
+	//  const char * szFileName,
+	//  const char * szTitle,
+	//  const char * szDescription,
+	//  DWORD Flags)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(fileName)),
+		uintptr(toCharPtr(title)),
+		uintptr(toCharPtr(description)),
+		uintptr(flags),
+	}
+	return callProc(scFlightSave, args...)
+}
+
+func (dll *dllBackend) FlightPlanLoad(fileName string) error {
+	// SimConnect_FlightPlanLoad(
+	// HANDLE hSimConnect,
+	// const char * szFileName)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(fileName)),
+	}
+	return callProc(scFlightPlanLoad, args...)
+}
+
+func (dll *dllBackend) GetLastSentPacketID(pdwError *DWord) error {
+	// SimConnect_GetLastSentPacketID(
+	//  HANDLE hSimConnect,
+	//  DWORD * pdwError);
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(unsafe.Pointer(pdwError)),
+	}
+	return callProc(scGetLastSentPacketID, args...)
+}
+
+func (dll *dllBackend) RequestFacilitiesList(facilityListType, requestID DWord) error {
+	// SimConnect_RequestFacilitiesList(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_FACILITY_LIST_TYPE type,
+	// 	SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(facilityListType),
+		uintptr(requestID),
+	}
+	return callProc(scRequestFacilitiesList, args...)
+}
+
+func (dll *dllBackend) SubscribeToFacilities(facilityListType, requestID DWord) error {
+	// SimConnect_SubscribeToFacilities(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_FACILITY_LIST_TYPE type,
+	// 	SIMCONNECT_DATA_REQUEST_ID RequestID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(facilityListType),
+		uintptr(requestID),
+	}
+	return callProc(scSubscribeToFacilities, args...)
+}
+
+func (dll *dllBackend) UnsubscribeToFacilities(facilityListType DWord) error {
+	// SimConnect_UnsubscribeToFacilities(
+	// 	HANDLE hSimConnect,
+	// 	SIMCONNECT_FACILITY_LIST_TYPE type)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(facilityListType),
+	}
+	return callProc(scUnsubscribeToFacilities, args...)
+}
+
+func (dll *dllBackend) MenuAddItem(menuItem string, menuEventID, data DWord) error {
+	// SimConnect_MenuAddItem(
+	//  HANDLE hSimConnect,
+	//  const char * szMenuItem,
+	//  SIMCONNECT_CLIENT_EVENT_ID MenuEventID,
+	//  DWORD dwData)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(menuItem)),
+		uintptr(menuEventID),
+		uintptr(data),
+	}
+	return callProc(scMenuAddItem, args...)
+}
+
+func (dll *dllBackend) MenuAddSubItem(menuEventID DWord, menuItem string, subMenuEventID, data DWord) error {
+	// SimConnect_MenuAddSubItem(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_EVENT_ID MenuEventID,
+	//  const char * szMenuItem,
+	//  SIMCONNECT_CLIENT_EVENT_ID SubMenuEventID,
+	//  DWORD dwData)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(menuEventID),
+		uintptr(toCharPtr(menuItem)),
+		uintptr(subMenuEventID),
+		uintptr(data),
+	}
+	return callProc(scMenuAddSubItem, args...)
+}
+
+func (dll *dllBackend) MenuDeleteItem(menuEventID DWord) error {
+	// SimConnect_MenuDeleteItem(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_EVENT_ID MenuEventID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(menuEventID),
+	}
+	return callProc(scMenuDeleteItem, args...)
+}
+
+func (dll *dllBackend) MenuDeleteSubItem(menuEventID, subMenuEventID DWord) error {
+	// SimConnect_MenuDeleteSubItem(
+	//  HANDLE hSimConnect,
+	//  SIMCONNECT_CLIENT_EVENT_ID MenuEventID,
+	//  const SIMCONNECT_CLIENT_EVENT_ID SubMenuEventID)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(menuEventID),
+		uintptr(subMenuEventID),
+	}
+	return callProc(scMenuDeleteSubItem, args...)
+}
+
+func (dll *dllBackend) CameraSetRelative6DOF(deltaX, deltaY, deltaZ, pitchDeg, bankDeg, headingDeg float64) error {
+	// SimConnect_CameraSetRelative6DOF(
+	// 	HANDLE hSimConnect,
+	// 	float fDeltaX,
+	// 	float fDeltaY,
+	// 	float fDeltaZ,
+	// 	float fPitchDeg,
+	// 	float fBankDeg,
+	// 	float fHeadingDeg)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(deltaX),
+		uintptr(deltaY),
+		uintptr(deltaZ),
+		uintptr(pitchDeg),
+		uintptr(bankDeg),
+		uintptr(headingDeg),
+	}
+	return callProc(scCameraSetRelative6DOF, args...)
+}
+
+func (dll *dllBackend) SetSystemState(state string, integerValue DWord, floatValue float32, stringValue string) error {
+	// SimConnect_SetSystemState(
+	//  HANDLE hSimConnect,
+	//  const char * szState,
+	//  DWORD dwInteger,
+	//  float fFloat,
+	//  const char * szString)
+
+	args := []uintptr{
+		uintptr(dll.handle),
+		uintptr(toCharPtr(state)),
+		uintptr(integerValue),
+		uintptr(floatValue),
+		uintptr(toCharPtr(stringValue)),
+	}
+	return callProc(scSetSystemState, args...)
+}
+
+func callProc(procName string, args ...uintptr) error {
+	r1, err := procCall(procName, args...)
+	if int32(r1) < 0 {
+		return fmt.Errorf("%s error: %d %s", procName, r1, err)
+	}
+	return nil
+}
+
+func toNullTerminatedBytes(str string) []byte {
+	return []byte(str + "\x00")
+}
+
+func toCharPtr(str string) uintptr {
+	bytes := toNullTerminatedBytes(str)
+	return uintptr(unsafe.Pointer(&bytes[0]))
+}
+
+func toBoolPtr(value bool) uintptr {
+	v := 0
+	if value {
+		v = 1
+	}
+	return uintptr(v)
+}
@@ -0,0 +1,534 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"unsafe"
+)
+
+/*
+	Pure-Go implementation of the SimConnect network protocol.
+	The simulator exposes it when a TCP endpoint is configured in SimConnect.xml.
+
+	Every packet sent to the server starts with a 16-byte header:
+		DWORD dwSize     // total packet size including the header
+		DWORD dwVersion  // protocol version
+		DWORD dwID       // 0xF0000000 | function ID
+		DWORD dwSendID   // packet ID, see SimConnect_GetLastSentPacketID
+	Packets received from the server are the SIMCONNECT_RECV structures from defs.go as is.
+	Strings are sent as zero padded fixed-size fields.
+*/
+
+const (
+	// ProtocolVersionFSXSP2 is the protocol spoken by the FSX SP2/Acceleration SimConnect clients which MSFS still accepts.
+	ProtocolVersionFSXSP2 DWord = 4
+
+	netPacketHeaderSize = 16
+	netPacketMaxSize    = 1 << 24
+	netPacketTypeMask   = 0xF0000000
+	netStringSize       = 256
+	netPathSize         = 260 // MAX_PATH
+	netTailNumberSize   = 12
+	netAirportIDSize    = 5
+
+	hresultBrokenPipe uint32 = 0x8007006D // HRESULT_FROM_WIN32(ERROR_BROKEN_PIPE)
+)
+
+// Function IDs of the SimConnect network protocol
+const (
+	netOpen                              DWord = 0x01
+	netMapClientEventToSimEvent          DWord = 0x04
+	netTransmitClientEvent               DWord = 0x05
+	netSetSystemEventState               DWord = 0x06
+	netAddClientEventToNotificationGroup DWord = 0x07
+	netRemoveClientEvent                 DWord = 0x08
+	netSetNotificationGroupPriority      DWord = 0x09
+	netClearNotificationGroup            DWord = 0x0A
+	netRequestNotificationGroup          DWord = 0x0B
+	netAddToDataDefinition               DWord = 0x0C
+	netClearDataDefinition               DWord = 0x0D
+	netRequestDataOnSimObject            DWord = 0x0E
+	netRequestDataOnSimObjectType        DWord = 0x0F
+	netSetDataOnSimObject                DWord = 0x10
+	netMapInputEventToClientEvent        DWord = 0x11
+	netSetInputGroupPriority             DWord = 0x12
+	netRemoveInputEvent                  DWord = 0x13
+	netClearInputGroup                   DWord = 0x14
+	netSetInputGroupState                DWord = 0x15
+	netSubscribeToSystemEvent            DWord = 0x17
+	netUnsubscribeFromSystemEvent        DWord = 0x18
+	netAICreateParkedATCAircraft         DWord = 0x27
+	netAICreateEnrouteATCAircraft        DWord = 0x28
+	netAICreateNonATCAircraft            DWord = 0x29
+	netAICreateSimulatedObject           DWord = 0x2A
+	netAIReleaseControl                  DWord = 0x2B
+	netAIRemoveObject                    DWord = 0x2C
+	netAISetAircraftFlightPlan           DWord = 0x2D
+	netCameraSetRelative6DOF             DWord = 0x30
+	netMenuAddItem                       DWord = 0x31
+	netMenuDeleteItem                    DWord = 0x32
+	netMenuAddSubItem                    DWord = 0x33
+	netMenuDeleteSubItem                 DWord = 0x34
+	netRequestSystemState                DWord = 0x35
+	netSetSystemState                    DWord = 0x36
+	netMapClientDataNameToID             DWord = 0x37
+	netCreateClientData                  DWord = 0x38
+	netAddToClientDataDefinition         DWord = 0x39
+	netClearClientDataDefinition         DWord = 0x3A
+	netRequestClientData                 DWord = 0x3B
+	netSetClientData                     DWord = 0x3C
+	netFlightLoad                        DWord = 0x3D
+	netFlightSave                        DWord = 0x3E
+	netFlightPlanLoad                    DWord = 0x3F
+	netText                              DWord = 0x40
+	netSubscribeToFacilities             DWord = 0x41
+	netUnsubscribeToFacilities           DWord = 0x42
+	netRequestFacilitiesList             DWord = 0x43
+)
+
+var (
+	errNotConnected     = errors.New("not connected")
+	errAlreadyConnected = errors.New("already connected")
+	errConnClosed       = errors.New("the connection the backend was created with is closed")
+)
+
+// NetBackend is a Backend speaking the SimConnect network protocol, no SimConnect.dll required.
+// It works on every platform Go runs on, e.g. a Linux box next to the simulator PC.
+type NetBackend struct {
+	ProtocolVersion DWord
+	dial            func() (net.Conn, error) // nil for a backend created with a connection
+	conn            net.Conn
+	writeMutex      sync.Mutex
+	mutex           sync.Mutex
+	packetID        DWord
+	dispatches      [][]byte
+	readErr         error
+	open            bool
+	generation      uint64 // counts the connections, so a reader of a closed one can tell it is stale
+}
+
+// NewNetBackend creates a backend which dials the given address (e.g. "tcp", "192.168.1.10:500") on Open.
+func NewNetBackend(network, address string) *NetBackend {
+	return &NetBackend{
+		ProtocolVersion: ProtocolVersionFSXSP2,
+		dial: func() (net.Conn, error) {
+			return net.Dial(network, address)
+		},
+		dispatches: make([][]byte, 0, 16),
+	}
+}
+
+// NewNetBackendConn creates a backend on top of an already established connection.
+// The backend never dials on its own: once the connection was closed, by Close or a failed Open,
+// Open fails and a new backend is needed.
+func NewNetBackendConn(conn net.Conn) *NetBackend {
+	nb := NewNetBackend(conn.RemoteAddr().Network(), conn.RemoteAddr().String())
+	nb.dial = nil
+	nb.conn = conn
+	return nb
+}
+
+// Open dials the server, unless the backend was created with a connection, and sends the open request.
+// A closed backend created with NewNetBackend can be opened again.
+func (nb *NetBackend) Open(name string) error {
+	nb.mutex.Lock()
+	if nb.open {
+		nb.mutex.Unlock()
+		return errAlreadyConnected
+	}
+	if nb.conn == nil {
+		if nb.dial == nil {
+			nb.mutex.Unlock()
+			return errConnClosed
+		}
+		conn, err := nb.dial()
+		if err != nil {
+			nb.mutex.Unlock()
+			return err
+		}
+		nb.conn = conn
+	}
+	nb.open = true
+	nb.generation++
+	nb.readErr = nil
+	nb.dispatches = nb.dispatches[:0]
+	conn, generation := nb.conn, nb.generation
+	nb.mutex.Unlock()
+
+	go nb.receive(conn, generation)
+
+	err := nb.send(netOpen,
+		netString{name, netStringSize},
+		DWord(0),
+		[]byte{0, 'X', 'S', 'F'},
+		DWord(10), DWord(0), DWord(61259), DWord(0), // SimConnect 10.0.61259.0 (FSX SP2)
+	)
+	if err != nil {
+		// leave the backend closed, so Open can be retried
+		nb.mutex.Lock()
+		if nb.generation == generation {
+			nb.closeConn()
+		}
+		nb.mutex.Unlock()
+	}
+	return err
+}
+
+func (nb *NetBackend) Close() error {
+	nb.mutex.Lock()
+	defer nb.mutex.Unlock()
+	if nb.conn == nil {
+		return errNotConnected
+	}
+	return nb.closeConn()
+}
+
+// closeConn closes the connection and drops what was received on it. The caller holds nb.mutex.
+func (nb *NetBackend) closeConn() error {
+	err := nb.conn.Close()
+	nb.conn = nil
+	nb.open = false
+	nb.generation++
+	nb.readErr = nil
+	nb.dispatches = nb.dispatches[:0]
+	return err
+}
+
+func (nb *NetBackend) GetNextDispatch() (unsafe.Pointer, DWord, int32, error) {
+	nb.mutex.Lock()
+	defer nb.mutex.Unlock()
+	if len(nb.dispatches) == 0 {
+		if nb.readErr != nil {
+			r1 := hresultBrokenPipe
+			return nil, 0, int32(r1), nb.readErr
+		}
+		r1 := EFail
+		return nil, 0, int32(r1), errNoDispatch
+	}
+	packet := nb.dispatches[0]
+	nb.dispatches = nb.dispatches[1:]
+	return unsafe.Pointer(&packet[0]), DWord(len(packet)), 0, nil
+}
+
+func (nb *NetBackend) RequestSystemState(requestID DWord, state string) error {
+	return nb.send(netRequestSystemState, requestID, netString{state, netStringSize})
+}
+
+func (nb *NetBackend) MapClientEventToSimEvent(eventID DWord, eventName string) error {
+	return nb.send(netMapClientEventToSimEvent, eventID, netString{eventName, netStringSize})
+}
+
+func (nb *NetBackend) SubscribeToSystemEvent(eventID DWord, systemEventName string) error {
+	return nb.send(netSubscribeToSystemEvent, eventID, netString{systemEventName, netStringSize})
+}
+
+func (nb *NetBackend) SetSystemEventState(eventID, state DWord) error {
+	return nb.send(netSetSystemEventState, eventID, state)
+}
+
+func (nb *NetBackend) UnsubscribeFromSystemEvent(eventID DWord) error {
+	return nb.send(netUnsubscribeFromSystemEvent, eventID)
+}
+
+func (nb *NetBackend) SetNotificationGroupPriority(groupID, priority DWord) error {
+	return nb.send(netSetNotificationGroupPriority, groupID, priority)
+}
+
+func (nb *NetBackend) Text(text string, textType DWord, timeSeconds float32, eventID DWord) error {
+	data := toNullTerminatedBytes(text)
+	return nb.send(netText, textType, timeSeconds, eventID, DWord(len(data)), data)
+}
+
+func (nb *NetBackend) RequestDataOnSimObject(requestID, defineID, objectID, period, flags DWord) error {
+	const origin DWord = 0
+	const interval DWord = 0
+	const limit DWord = 0
+	return nb.send(netRequestDataOnSimObject, requestID, defineID, objectID, period, flags, origin, interval, limit)
+}
+
+func (nb *NetBackend) RequestDataOnSimObjectType(requestID, defineID, radius, simobjectType DWord) error {
+	return nb.send(netRequestDataOnSimObjectType, requestID, defineID, radius, simobjectType)
+}
+
+func (nb *NetBackend) AddClientEventToNotificationGroup(groupID, eventID DWord, maskable bool) error {
+	return nb.send(netAddClientEventToNotificationGroup, groupID, eventID, maskable)
+}
+
+func (nb *NetBackend) RemoveClientEvent(groupID, eventID DWord) error {
+	return nb.send(netRemoveClientEvent, groupID, eventID)
+}
+
+func (nb *NetBackend) TransmitClientEvent(objectID uint32, eventID uint32, data DWord, groupID DWord, flags DWord) error {
+	return nb.send(netTransmitClientEvent, objectID, eventID, data, groupID, flags)
+}
+
+func (nb *NetBackend) MapClientDataNameToID(clientDataName string, clientDataID DWord) error {
+	return nb.send(netMapClientDataNameToID, netString{clientDataName, netStringSize}, clientDataID)
+}
+
+func (nb *NetBackend) RequestClientData(clientDataID, requestID, defineID, period, flags DWord) error {
+	const origin DWord = 0
+	const interval DWord = 0
+	const limit DWord = 0
+	return nb.send(netRequestClientData, clientDataID, requestID, defineID, period, flags, origin, interval, limit)
+}
+
+func (nb *NetBackend) CreateClientData(clientDataID, size, flags DWord) error {
+	return nb.send(netCreateClientData, clientDataID, size, flags)
+}
+
+func (nb *NetBackend) AddToClientDataDefinition(defineID, offset, sizeOrType DWord) error {
+	const epsilon float32 = 0
+	const datumID = Unused
+	return nb.send(netAddToClientDataDefinition, defineID, offset, sizeOrType, epsilon, datumID)
+}
+
+func (nb *NetBackend) AddToDataDefinition(defineID DWord, datumName string, unitName string, datumType DWord, epsilon float32, datumID DWord) error {
+	return nb.send(netAddToDataDefinition, defineID, netString{datumName, netStringSize}, netString{unitName, netStringSize}, datumType, epsilon, datumID)
+}
+
+func (nb *NetBackend) SetClientData(clientDataID, defineID, flags DWord, unitSize DWord, buf unsafe.Pointer) error {
+	const reserved DWord = 0
+	return nb.send(netSetClientData, clientDataID, defineID, flags, reserved, unitSize, copyBuffer(buf, unitSize))
+}
+
+func (nb *NetBackend) SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize DWord, buf unsafe.Pointer) error {
+	count := arrayCount
+	if count == 0 {
+		count = 1
+	}
+	return nb.send(netSetDataOnSimObject, defineID, objectID, flags, arrayCount, unitSize, copyBuffer(buf, count*unitSize))
+}
+
+func (nb *NetBackend) ClearClientDataDefinition(defineID DWord) error {
+	return nb.send(netClearClientDataDefinition, defineID)
+}
+
+func (nb *NetBackend) ClearDataDefinition(defineID DWord) error {
+	return nb.send(netClearDataDefinition, defineID)
+}
+
+func (nb *NetBackend) MapInputEventToClientEvent(groupID DWord, inputDefinition string, downEventID DWord) error {
+	const downValue DWord = 0
+	const upEventID = Unused
+	const upValue DWord = 0
+	const maskable = false
+	return nb.send(netMapInputEventToClientEvent, groupID, netString{inputDefinition, netStringSize}, downEventID, downValue, upEventID, upValue, maskable)
+}
+
+func (nb *NetBackend) RequestNotificationGroup(groupID DWord) error {
+	const reserved DWord = 0
+	const flags DWord = 0
+	return nb.send(netRequestNotificationGroup, groupID, reserved, flags)
+}
+
+func (nb *NetBackend) ClearInputGroup(groupID DWord) error {
+	return nb.send(netClearInputGroup, groupID)
+}
+
+func (nb *NetBackend) ClearNotificationGroup(groupID DWord) error {
+	return nb.send(netClearNotificationGroup, groupID)
+}
+
+func (nb *NetBackend) SetInputGroupPriority(groupID, priority DWord) error {
+	return nb.send(netSetInputGroupPriority, groupID, priority)
+}
+
+func (nb *NetBackend) SetInputGroupState(groupID, state DWord) error {
+	return nb.send(netSetInputGroupState, groupID, state)
+}
+
+func (nb *NetBackend) RemoveInputEvent(groupID DWord, inputDefinition string) error {
+	return nb.send(netRemoveInputEvent, groupID, netString{inputDefinition, netStringSize})
+}
+
+func (nb *NetBackend) AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber int, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error {
+	return nb.send(netAICreateEnrouteATCAircraft,
+		netString{containerTitle, netStringSize}, netString{tailNumber, netTailNumberSize}, int32(flightNumber),
+		netString{flightPlanPath, netPathSize}, flightPlanPosition, touchAndGo, requestID)
+}
+
+func (nb *NetBackend) AICreateNonATCAircraft(containerTitle, tailNumber string, initPos InitPosition, requestID DWord) error {
+	return nb.send(netAICreateNonATCAircraft, netString{containerTitle, netStringSize}, netString{tailNumber, netTailNumberSize}, initPos, requestID)
+}
+
+func (nb *NetBackend) AICreateParkedATCAircraft(containerTitle, tailNumber, airportID string, requestID DWord) error {
+	return nb.send(netAICreateParkedATCAircraft,
+		netString{containerTitle, netStringSize}, netString{tailNumber, netTailNumberSize}, netString{airportID, netAirportIDSize}, requestID)
+}
+
+func (nb *NetBackend) AICreateSimulatedObject(containerTitle string, initPos InitPosition, requestID DWord) error {
+	return nb.send(netAICreateSimulatedObject, netString{containerTitle, netStringSize}, initPos, requestID)
+}
+
+func (nb *NetBackend) AIReleaseControl(objectID, requestID DWord) error {
+	return nb.send(netAIReleaseControl, objectID, requestID)
+}
+
+func (nb *NetBackend) AIRemoveObject(objectID, requestID DWord) error {
+	return nb.send(netAIRemoveObject, objectID, requestID)
+}
+
+func (nb *NetBackend) AISetAircraftFlightPlan(objectID, requestID DWord, flightPlanPath string) error {
+	return nb.send(netAISetAircraftFlightPlan, objectID, netString{flightPlanPath, netPathSize}, requestID)
+}
+
+func (nb *NetBackend) FlightLoad(fileName string) error {
+	return nb.send(netFlightLoad, netString{fileName, netPathSize})
+}
+
+func (nb *NetBackend) FlightSave(fileName, title, description string, flags DWord) error {
+	return nb.send(netFlightSave, netString{fileName, netPathSize}, netString{title, netPathSize}, netString{description, netPathSize}, flags)
+}
+
+func (nb *NetBackend) FlightPlanLoad(fileName string) error {
+	return nb.send(netFlightPlanLoad, netString{fileName, netPathSize})
+}
+
+func (nb *NetBackend) GetLastSentPacketID(pdwError *DWord) error {
+	nb.writeMutex.Lock()
+	defer nb.writeMutex.Unlock()
+	if pdwError != nil {
+		*pdwError = nb.packetID
+	}
+	return nil
+}
+
+func (nb *NetBackend) RequestFacilitiesList(facilityListType, requestID DWord) error {
+	return nb.send(netRequestFacilitiesList, facilityListType, requestID)
+}
+
+func (nb *NetBackend) SubscribeToFacilities(facilityListType, requestID DWord) error {
+	return nb.send(netSubscribeToFacilities, facilityListType, requestID)
+}
+
+func (nb *NetBackend) UnsubscribeToFacilities(facilityListType DWord) error {
+	return nb.send(netUnsubscribeToFacilities, facilityListType)
+}
+
+func (nb *NetBackend) MenuAddItem(menuItem string, menuEventID, data DWord) error {
+	return nb.send(netMenuAddItem, netString{menuItem, netStringSize}, menuEventID, data)
+}
+
+func (nb *NetBackend) MenuAddSubItem(menuEventID DWord, menuItem string, subMenuEventID, data DWord) error {
+	return nb.send(netMenuAddSubItem, menuEventID, netString{menuItem, netStringSize}, subMenuEventID, data)
+}
+
+func (nb *NetBackend) MenuDeleteItem(menuEventID DWord) error {
+	return nb.send(netMenuDeleteItem, menuEventID)
+}
+
+func (nb *NetBackend) MenuDeleteSubItem(menuEventID, subMenuEventID DWord) error {
+	return nb.send(netMenuDeleteSubItem, menuEventID, subMenuEventID)
+}
+
+func (nb *NetBackend) CameraSetRelative6DOF(deltaX, deltaY, deltaZ, pitchDeg, bankDeg, headingDeg float64) error {
+	return nb.send(netCameraSetRelative6DOF,
+		float32(deltaX), float32(deltaY), float32(deltaZ), float32(pitchDeg), float32(bankDeg), float32(headingDeg))
+}
+
+func (nb *NetBackend) SetSystemState(state string, integerValue DWord, floatValue float32, stringValue string) error {
+	return nb.send(netSetSystemState, netString{state, netStringSize}, integerValue, floatValue, netString{stringValue, netStringSize})
+}
+
+// netString is a string sent as a zero padded field of a fixed size.
+type netString struct {
+	value string
+	size  int
+}
+
+func (nb *NetBackend) send(functionID DWord, fields ...interface{}) error {
+	payload, err := encodeNetFields(fields...)
+	if err != nil {
+		return err
+	}
+
+	nb.mutex.Lock()
+	conn := nb.conn
+	nb.mutex.Unlock()
+	if conn == nil {
+		return errNotConnected
+	}
+
+	nb.writeMutex.Lock()
+	defer nb.writeMutex.Unlock()
+	nb.packetID++
+	packet := make([]byte, netPacketHeaderSize, netPacketHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(packet[0:], uint32(netPacketHeaderSize+len(payload)))
+	binary.LittleEndian.PutUint32(packet[4:], uint32(nb.ProtocolVersion))
+	binary.LittleEndian.PutUint32(packet[8:], uint32(netPacketTypeMask|functionID))
+	binary.LittleEndian.PutUint32(packet[12:], uint32(nb.packetID))
+	packet = append(packet, payload...)
+	_, err = conn.Write(packet)
+	return err
+}
+
+// receive queues the packets read from conn until it fails or is closed.
+// Once the connection is closed, its packets and errors are dropped.
+func (nb *NetBackend) receive(conn net.Conn, generation uint64) {
+	for {
+		packet, err := readNetPacket(conn)
+		nb.mutex.Lock()
+		if generation != nb.generation {
+			nb.mutex.Unlock()
+			return
+		}
+		if err != nil {
+			nb.readErr = err
+			nb.mutex.Unlock()
+			return
+		}
+		nb.dispatches = append(nb.dispatches, packet)
+		nb.mutex.Unlock()
+	}
+}
+
+func readNetPacket(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(header[:])
+	if size < 4 || size > netPacketMaxSize {
+		return nil, fmt.Errorf("invalid packet size: %d", size)
+	}
+	packet := make([]byte, size)
+	copy(packet, header[:])
+	if _, err := io.ReadFull(r, packet[4:]); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+func encodeNetFields(fields ...interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for _, field := range fields {
+		switch value := field.(type) {
+		case netString:
+			str := make([]byte, value.size)
+			copy(str[:value.size-1], value.value)
+			buf.Write(str)
+
+		case []byte:
+			buf.Write(value)
+
+		case bool:
+			var v DWord
+			if value {
+				v = 1
+			}
+			binary.Write(buf, binary.LittleEndian, v)
+
+		default:
+			if err := binary.Write(buf, binary.LittleEndian, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+package simconnect
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// brokenConn is a connection which can't be written to.
+type brokenConn struct {
+	net.Conn
+	closed int32
+}
+
+func (c *brokenConn) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func (c *brokenConn) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return c.Conn.Close()
+}
+
+func TestNetBackendOpenAfterFailedOpen(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	nb := NewNetBackend("tcp", listener.Addr().String())
+	dial := nb.dial
+	var broken *brokenConn
+	nb.dial = func() (net.Conn, error) {
+		conn, err := dial()
+		if err != nil || broken != nil {
+			return conn, err
+		}
+		broken = &brokenConn{Conn: conn}
+		return broken, nil
+	}
+	if err := nb.Open("broken"); err == nil {
+		t.Fatal("Open over a broken connection succeeded")
+	}
+	if atomic.LoadInt32(&broken.closed) == 0 {
+		t.Error("the connection of a failed Open wasn't closed")
+	}
+	if err := nb.Close(); !errors.Is(err, errNotConnected) {
+		t.Errorf("Close after a failed Open = %v, want errNotConnected", err)
+	}
+
+	if err := nb.Open("retry"); err != nil {
+		t.Fatalf("Open after a failed Open: %v", err)
+	}
+	defer nb.Close()
+	<-accepted // the broken connection
+	select {
+	case conn := <-accepted:
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		packet, err := readNetPacket(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if functionID := binary.LittleEndian.Uint32(packet[8:]) &^ netPacketTypeMask; functionID != uint32(netOpen) {
+			t.Errorf("got function 0x%02X, want the open request", functionID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Open after a failed Open didn't dial again")
+	}
+}
@@ -0,0 +1,140 @@
+package simconnect_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect/simconnecttest"
+)
+
+// waitForMessage polls the connection until a message arrives.
+func waitForMessage(t *testing.T, simco *SimConnect) *Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		msg, err := simco.NextMessage()
+		if err != nil {
+			t.Fatalf("NextMessage: %v", err)
+		}
+		if msg != nil {
+			return msg
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no message")
+	return nil
+}
+
+func newTestServer(t *testing.T) *simconnecttest.Server {
+	t.Helper()
+	srv, err := simconnecttest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func TestNetBackendOpen(t *testing.T) {
+	srv := newTestServer(t)
+	simco := NewSimConnectWithBackend(srv.Backend())
+	if err := simco.Open("net test"); err != nil {
+		t.Fatal(err)
+	}
+	defer simco.Close()
+
+	open, err := waitForMessage(t, simco).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := CString(open.ApplicationName[:]); name != srv.ApplicationName {
+		t.Errorf("application name = %q, want %q", name, srv.ApplicationName)
+	}
+	if !srv.WaitForCalls("SimConnect_Open", 1, time.Second) {
+		t.Fatal("server got no open request")
+	}
+	if args := srv.Calls()[0].Args; args[0] != "net test" {
+		t.Errorf("open args = %v", args)
+	}
+}
+
+func TestNetBackendOpenTwice(t *testing.T) {
+	srv := newTestServer(t)
+	backend := srv.Backend()
+	if err := backend.Open("first"); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	if err := backend.Open("second"); err == nil {
+		t.Fatal("second Open succeeded")
+	}
+}
+
+func TestNetBackendConnIsNotRedialed(t *testing.T) {
+	srv := newTestServer(t)
+	conn, err := net.Dial("tcp", srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	simco := NewSimConnectWithBackend(NewNetBackendConn(conn))
+	if err := simco.Open("conn"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := waitForMessage(t, simco); msg.ID() != RecvIDOpen {
+		t.Fatalf("got message %d, want RecvIDOpen", msg.ID())
+	}
+	if err := simco.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := simco.Open("again"); err == nil {
+		simco.Close()
+		t.Fatal("Open after Close of a backend created with a connection succeeded")
+	}
+	if !srv.WaitForCalls("SimConnect_Open", 1, time.Second) || len(srv.Calls()) != 1 {
+		t.Errorf("server calls = %v, want a single open", srv.Calls())
+	}
+}
+
+func TestNetBackendReconnect(t *testing.T) {
+	srv := newTestServer(t)
+	simco := NewSimConnectWithBackend(srv.Backend())
+	for i := 0; i < 50; i++ {
+		if err := simco.Open("reconnect"); err != nil {
+			t.Fatalf("open %d: %v", i, err)
+		}
+		if msg := waitForMessage(t, simco); msg.ID() != RecvIDOpen {
+			t.Fatalf("open %d: got message %d, want RecvIDOpen", i, msg.ID())
+		}
+		// leave a message in the queue, it must not survive Close
+		srv.SendException(ExceptionError, 0, 0)
+		time.Sleep(5 * time.Millisecond)
+		if err := simco.Close(); err != nil {
+			t.Fatalf("close %d: %v", i, err)
+		}
+	}
+	if err := simco.Close(); err == nil {
+		t.Fatal("Close of a closed connection succeeded")
+	}
+}
+
+func TestNetBackendServerGone(t *testing.T) {
+	srv := newTestServer(t)
+	simco := NewSimConnectWithBackend(srv.Backend())
+	if err := simco.Open("gone"); err != nil {
+		t.Fatal(err)
+	}
+	defer simco.Close()
+	waitForMessage(t, simco)
+	srv.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := simco.NextMessage(); err != nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no error after the server closed the connection")
+}
@@ -0,0 +1,364 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+var errNoDispatch = errors.New("no dispatch pending")
+
+// BackendCall is a single call recorded by the ScriptedBackend.
+// Name is the SimConnect function name (e.g. "SimConnect_AddToDataDefinition"), Args are the arguments in call order.
+// Buffers passed as unsafe.Pointer are recorded as copied []byte.
+type BackendCall struct {
+	Name string
+	Args []interface{}
+}
+
+// ScriptedBackend is an in-memory Backend that records every call and hands out canned dispatch packets.
+// It allows running SimConnect and SimMate without the simulator, e.g. in tests or on CI machines.
+type ScriptedBackend struct {
+	mutex        sync.Mutex
+	calls        []BackendCall
+	dispatches   [][]byte
+	errs         map[string]error
+	skips        map[string]int // calls which succeed before the error set with SetErrorAfter
+	lastPacketID DWord
+}
+
+func NewScriptedBackend() *ScriptedBackend {
+	return &ScriptedBackend{
+		calls:      make([]BackendCall, 0, 16),
+		dispatches: make([][]byte, 0, 16),
+		errs:       make(map[string]error),
+		skips:      make(map[string]int),
+	}
+}
+
+// Push queues a raw dispatch packet. The packet is handed out unchanged by GetNextDispatch.
+func (b *ScriptedBackend) Push(packet []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.dispatches = append(b.dispatches, packet)
+}
+
+// PushRecv encodes one or more values in little-endian byte order into a single packet and queues it.
+// The first value is expected to start with a Recv header; its Size field is set to the packet length.
+func (b *ScriptedBackend) PushRecv(values ...interface{}) error {
+	buf := new(bytes.Buffer)
+	for _, value := range values {
+		if err := binary.Write(buf, binary.LittleEndian, value); err != nil {
+			return err
+		}
+	}
+	packet := buf.Bytes()
+	if len(packet) >= 4 {
+		binary.LittleEndian.PutUint32(packet, uint32(len(packet)))
+	}
+	b.Push(packet)
+	return nil
+}
+
+// Pending returns the number of queued dispatch packets.
+func (b *ScriptedBackend) Pending() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.dispatches)
+}
+
+// SetError makes every subsequent call of the given SimConnect function fail with err.
+// Passing a nil error clears it. A failing GetNextDispatch reports a broken pipe rather than E_FAIL,
+// which would mean there is no message pending, so NextMessage returns err.
+func (b *ScriptedBackend) SetError(procName string, err error) {
+	b.SetErrorAfter(procName, 0, err)
+}
+
+// SetErrorAfter works like SetError, but lets the next n calls of the function succeed first.
+func (b *ScriptedBackend) SetErrorAfter(procName string, n int, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.skips, procName)
+	if err == nil {
+		delete(b.errs, procName)
+		return
+	}
+	b.errs[procName] = err
+	if n > 0 {
+		b.skips[procName] = n
+	}
+}
+
+// Calls returns a copy of all recorded calls.
+func (b *ScriptedBackend) Calls() []BackendCall {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	calls := make([]BackendCall, len(b.calls))
+	copy(calls, b.calls)
+	return calls
+}
+
+// CallsNamed returns all recorded calls of the given SimConnect function.
+func (b *ScriptedBackend) CallsNamed(procName string) []BackendCall {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	calls := make([]BackendCall, 0)
+	for _, call := range b.calls {
+		if call.Name == procName {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset drops all recorded calls, pending packets and errors.
+func (b *ScriptedBackend) Reset() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.calls = b.calls[:0]
+	b.dispatches = b.dispatches[:0]
+	b.errs = make(map[string]error)
+	b.skips = make(map[string]int)
+}
+
+func (b *ScriptedBackend) record(procName string, args ...interface{}) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.calls = append(b.calls, BackendCall{Name: procName, Args: args})
+	b.lastPacketID++
+	if n := b.skips[procName]; n > 0 {
+		b.skips[procName] = n - 1
+		return nil
+	}
+	return b.errs[procName]
+}
+
+func (b *ScriptedBackend) Open(name string) error {
+	return b.record(scOpen, name)
+}
+
+func (b *ScriptedBackend) Close() error {
+	return b.record(scClose)
+}
+
+func (b *ScriptedBackend) GetNextDispatch() (unsafe.Pointer, DWord, int32, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err := b.errs[scGetNextDispatch]; err != nil {
+		r1 := hresultBrokenPipe
+		return nil, 0, int32(r1), err
+	}
+	if len(b.dispatches) == 0 {
+		r1 := EFail
+		return nil, 0, int32(r1), errNoDispatch
+	}
+	packet := b.dispatches[0]
+	b.dispatches = b.dispatches[1:]
+	if len(packet) == 0 {
+		return nil, 0, 0, nil
+	}
+	return unsafe.Pointer(&packet[0]), DWord(len(packet)), 0, nil
+}
+
+func (b *ScriptedBackend) RequestSystemState(requestID DWord, state string) error {
+	return b.record(scRequestSystemState, requestID, state)
+}
+
+func (b *ScriptedBackend) MapClientEventToSimEvent(eventID DWord, eventName string) error {
+	return b.record(scMapClientEventToSimEvent, eventID, eventName)
+}
+
+func (b *ScriptedBackend) SubscribeToSystemEvent(eventID DWord, systemEventName string) error {
+	return b.record(scSubscribeToSystemEvent, eventID, systemEventName)
+}
+
+func (b *ScriptedBackend) SetSystemEventState(eventID, state DWord) error {
+	return b.record(scSetSystemEventState, eventID, state)
+}
+
+func (b *ScriptedBackend) UnsubscribeFromSystemEvent(eventID DWord) error {
+	return b.record(scUnsubscribeFromSystemEvent, eventID)
+}
+
+func (b *ScriptedBackend) SetNotificationGroupPriority(groupID, priority DWord) error {
+	return b.record(scSetNotificationGroupPriority, groupID, priority)
+}
+
+func (b *ScriptedBackend) Text(text string, textType DWord, timeSeconds float32, eventID DWord) error {
+	return b.record(scText, text, textType, timeSeconds, eventID)
+}
+
+func (b *ScriptedBackend) RequestDataOnSimObject(requestID, defineID, objectID, period, flags DWord) error {
+	return b.record(scRequestDataOnSimObject, requestID, defineID, objectID, period, flags)
+}
+
+func (b *ScriptedBackend) RequestDataOnSimObjectType(requestID, defineID, radius, simobjectType DWord) error {
+	return b.record(scRequestDataOnSimObjectType, requestID, defineID, radius, simobjectType)
+}
+
+func (b *ScriptedBackend) AddClientEventToNotificationGroup(groupID, eventID DWord, maskable bool) error {
+	return b.record(scAddClientEventToNotificationGroup, groupID, eventID, maskable)
+}
+
+func (b *ScriptedBackend) RemoveClientEvent(groupID, eventID DWord) error {
+	return b.record(scRemoveClientEvent, groupID, eventID)
+}
+
+func (b *ScriptedBackend) TransmitClientEvent(objectID uint32, eventID uint32, data DWord, groupID DWord, flags DWord) error {
+	return b.record(scTransmitClientEvent, objectID, eventID, data, groupID, flags)
+}
+
+func (b *ScriptedBackend) MapClientDataNameToID(clientDataName string, clientDataID DWord) error {
+	return b.record(scMapClientDataNameToID, clientDataName, clientDataID)
+}
+
+func (b *ScriptedBackend) RequestClientData(clientDataID, requestID, defineID, period, flags DWord) error {
+	return b.record(scRequestClientData, clientDataID, requestID, defineID, period, flags)
+}
+
+func (b *ScriptedBackend) CreateClientData(clientDataID, size, flags DWord) error {
+	return b.record(scCreateClientData, clientDataID, size, flags)
+}
+
+func (b *ScriptedBackend) AddToClientDataDefinition(defineID, offset, sizeOrType DWord) error {
+	return b.record(scAddToClientDataDefinition, defineID, offset, sizeOrType)
+}
+
+func (b *ScriptedBackend) AddToDataDefinition(defineID DWord, datumName string, unitName string, datumType DWord, epsilon float32, datumID DWord) error {
+	return b.record(scAddToDataDefinition, defineID, datumName, unitName, datumType, epsilon, datumID)
+}
+
+func (b *ScriptedBackend) SetClientData(clientDataID, defineID, flags DWord, unitSize DWord, buf unsafe.Pointer) error {
+	return b.record(scSetClientData, clientDataID, defineID, flags, unitSize, copyBuffer(buf, unitSize))
+}
+
+func (b *ScriptedBackend) SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize DWord, buf unsafe.Pointer) error {
+	count := arrayCount
+	if count == 0 {
+		count = 1
+	}
+	return b.record(scSetDataOnSimObject, defineID, objectID, flags, arrayCount, unitSize, copyBuffer(buf, count*unitSize))
+}
+
+func (b *ScriptedBackend) ClearClientDataDefinition(defineID DWord) error {
+	return b.record(scClearClientDataDefinition, defineID)
+}
+
+func (b *ScriptedBackend) ClearDataDefinition(defineID DWord) error {
+	return b.record(scClearDataDefinition, defineID)
+}
+
+func (b *ScriptedBackend) MapInputEventToClientEvent(groupID DWord, inputDefinition string, downEventID DWord) error {
+	return b.record(scMapInputEventToClientEvent, groupID, inputDefinition, downEventID)
+}
+
+func (b *ScriptedBackend) RequestNotificationGroup(groupID DWord) error {
+	return b.record(scRequestNotificationGroup, groupID)
+}
+
+func (b *ScriptedBackend) ClearInputGroup(groupID DWord) error {
+	return b.record(scClearInputGroup, groupID)
+}
+
+func (b *ScriptedBackend) ClearNotificationGroup(groupID DWord) error {
+	return b.record(scClearNotificationGroup, groupID)
+}
+
+func (b *ScriptedBackend) SetInputGroupPriority(groupID, priority DWord) error {
+	return b.record(scSetInputGroupPriority, groupID, priority)
+}
+
+func (b *ScriptedBackend) SetInputGroupState(groupID, state DWord) error {
+	return b.record(scSetInputGroupState, groupID, state)
+}
+
+func (b *ScriptedBackend) RemoveInputEvent(groupID DWord, inputDefinition string) error {
+	return b.record(scRemoveInputEvent, groupID, inputDefinition)
+}
+
+func (b *ScriptedBackend) AICreateEnrouteATCAircraft(containerTitle, tailNumber string, flightNumber int, flightPlanPath string, flightPlanPosition float64, touchAndGo bool, requestID uint32) error {
+	return b.record(scAICreateEnrouteATCAircraft, containerTitle, tailNumber, flightNumber, flightPlanPath, flightPlanPosition, touchAndGo, requestID)
+}
+
+func (b *ScriptedBackend) AICreateNonATCAircraft(containerTitle, tailNumber string, initPos InitPosition, requestID DWord) error {
+	return b.record(scAICreateNonATCAircraft, containerTitle, tailNumber, initPos, requestID)
+}
+
+func (b *ScriptedBackend) AICreateParkedATCAircraft(containerTitle, tailNumber, airportID string, requestID DWord) error {
+	return b.record(scAICreateParkedATCAircraft, containerTitle, tailNumber, airportID, requestID)
+}
+
+func (b *ScriptedBackend) AICreateSimulatedObject(containerTitle string, initPos InitPosition, requestID DWord) error {
+	return b.record(scAICreateSimulatedObject, containerTitle, initPos, requestID)
+}
+
+func (b *ScriptedBackend) AIReleaseControl(objectID, requestID DWord) error {
+	return b.record(scAIReleaseControl, objectID, requestID)
+}
+
+func (b *ScriptedBackend) AIRemoveObject(objectID, requestID DWord) error {
+	return b.record(scAIRemoveObject, objectID, requestID)
+}
+
+func (b *ScriptedBackend) AISetAircraftFlightPlan(objectID, requestID DWord, flightPlanPath string) error {
+	return b.record(scAISetAircraftFlightPlan, objectID, requestID, flightPlanPath)
+}
+
+func (b *ScriptedBackend) FlightLoad(fileName string) error {
+	return b.record(scFlightLoad, fileName)
+}
+
+func (b *ScriptedBackend) FlightSave(fileName, title, description string, flags DWord) error {
+	return b.record(scFlightSave, fileName, title, description, flags)
+}
+
+func (b *ScriptedBackend) FlightPlanLoad(fileName string) error {
+	return b.record(scFlightPlanLoad, fileName)
+}
+
+func (b *ScriptedBackend) GetLastSentPacketID(pdwError *DWord) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if pdwError != nil {
+		*pdwError = b.lastPacketID
+	}
+	return b.errs[scGetLastSentPacketID]
+}
+
+func (b *ScriptedBackend) RequestFacilitiesList(facilityListType, requestID DWord) error {
+	return b.record(scRequestFacilitiesList, facilityListType, requestID)
+}
+
+func (b *ScriptedBackend) SubscribeToFacilities(facilityListType, requestID DWord) error {
+	return b.record(scSubscribeToFacilities, facilityListType, requestID)
+}
+
+func (b *ScriptedBackend) UnsubscribeToFacilities(facilityListType DWord) error {
+	return b.record(scUnsubscribeToFacilities, facilityListType)
+}
+
+func (b *ScriptedBackend) MenuAddItem(menuItem string, menuEventID, data DWord) error {
+	return b.record(scMenuAddItem, menuItem, menuEventID, data)
+}
+
+func (b *ScriptedBackend) MenuAddSubItem(menuEventID DWord, menuItem string, subMenuEventID, data DWord) error {
+	return b.record(scMenuAddSubItem, menuEventID, menuItem, subMenuEventID, data)
+}
+
+func (b *ScriptedBackend) MenuDeleteItem(menuEventID DWord) error {
+	return b.record(scMenuDeleteItem, menuEventID)
+}
+
+func (b *ScriptedBackend) MenuDeleteSubItem(menuEventID, subMenuEventID DWord) error {
+	return b.record(scMenuDeleteSubItem, menuEventID, subMenuEventID)
+}
+
+func (b *ScriptedBackend) CameraSetRelative6DOF(deltaX, deltaY, deltaZ, pitchDeg, bankDeg, headingDeg float64) error {
+	return b.record(scCameraSetRelative6DOF, deltaX, deltaY, deltaZ, pitchDeg, bankDeg, headingDeg)
+}
+
+func (b *ScriptedBackend) SetSystemState(state string, integerValue DWord, floatValue float32, stringValue string) error {
+	return b.record(scSetSystemState, state, integerValue, floatValue, stringValue)
+}
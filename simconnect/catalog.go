@@ -0,0 +1,331 @@
+package simconnect
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect/units"
+)
+
+//go:embed data/simvars.txt
+var simVarCatalogData string
+
+var (
+	// ErrUnknownSimVar is returned for a simvar name which is not in the catalog.
+	ErrUnknownSimVar = errors.New("unknown simvar")
+	// ErrUnknownUnit is returned for a unit name SimConnect doesn't know.
+	ErrUnknownUnit = errors.New("unknown unit")
+	// ErrIncompatibleUnit is returned when a simvar is requested in a unit of another dimension, e.g. an altitude in knots.
+	ErrIncompatibleUnit = errors.New("incompatible unit")
+	// ErrIncompatibleDataType is returned when a simvar is requested with a datatype which can't hold it,
+	// e.g. TITLE as float64.
+	ErrIncompatibleDataType = errors.New("incompatible datatype")
+	// ErrNotSettable is returned when a simvar of the catalog which isn't settable is written.
+	ErrNotSettable = errors.New("not settable")
+)
+
+// SimVarInfo describes a simvar of the catalog.
+type SimVarInfo struct {
+	Name     string
+	Unit     string // default unit, "string" for strings and the structure name for structures
+	DataType DWord  // DataTypeInvalid for structures without a Go type
+	Indexed  bool   // the simvar takes an index, e.g. "ENG N1 RPM:1"
+	MinIndex int    // lowest index of an indexed simvar, 1 for engines, 0 for exits like "EXIT OPEN:0"
+	MaxIndex int    // highest index of an indexed simvar, 0 if unknown
+	Settable bool   // documented to be writable with SetDataOnSimObject
+}
+
+// IsString reports whether the simvar is a string.
+func (info SimVarInfo) IsString() bool {
+	return IsStringDataType(info.DataType)
+}
+
+// IsNumber reports whether the simvar is a number.
+func (info SimVarInfo) IsNumber() bool {
+	return isNumberDataType(info.DataType)
+}
+
+type simVarCatalog struct {
+	mutex sync.RWMutex
+	vars  map[string]SimVarInfo
+}
+
+var (
+	catalogOnce sync.Once
+	catalog     *simVarCatalog
+)
+
+func theCatalog() *simVarCatalog {
+	catalogOnce.Do(func() {
+		catalog = &simVarCatalog{
+			vars: parseSimVarCatalog(simVarCatalogData),
+		}
+	})
+	return catalog
+}
+
+func parseSimVarCatalog(data string) map[string]SimVarInfo {
+	vars := make(map[string]SimVarInfo)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		for len(fields) < 4 {
+			fields = append(fields, "")
+		}
+		info := SimVarInfo{
+			Name: fields[0],
+			Unit: fields[1],
+		}
+		if fields[2] != "" {
+			info.DataType = StringToDataType(fields[2])
+		}
+		for _, flag := range strings.Split(fields[3], ",") {
+			flag, value, _ := strings.Cut(flag, "=")
+			switch flag {
+			case "indexed":
+				info.Indexed = true
+				info.MinIndex, info.MaxIndex = parseIndexRange(value)
+			case "settable":
+				info.Settable = true
+			}
+		}
+		vars[info.Name] = info
+	}
+	return vars
+}
+
+// parseIndexRange parses the value of the indexed flag: empty for indices from 1, "N" for 1 to N,
+// "M-N" for M to N and "M-" for indices from M.
+func parseIndexRange(value string) (min, max int) {
+	from, to, isRange := strings.Cut(value, "-")
+	if !isRange {
+		max, _ = strconv.Atoi(value)
+		return 1, max
+	}
+	min, _ = strconv.Atoi(from)
+	max, _ = strconv.Atoi(to)
+	return min, max
+}
+
+// LookupSimVar returns the catalog entry of a simvar. The name is case insensitive and may carry an index, e.g. "ENG N1 RPM:1".
+func LookupSimVar(name string) (SimVarInfo, bool) {
+	c := theCatalog()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	info, exists := c.vars[catalogName(name)]
+	return info, exists
+}
+
+// SimVarInfos returns all simvars of the catalog sorted by name.
+func SimVarInfos() []SimVarInfo {
+	c := theCatalog()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	infos := make([]SimVarInfo, 0, len(c.vars))
+	for _, info := range c.vars {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// RegisterSimVarInfo adds a simvar to the catalog or replaces it, e.g. a simvar added by a newer simulator version.
+func RegisterSimVarInfo(info SimVarInfo) {
+	info.Name = catalogName(info.Name)
+	c := theCatalog()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.vars[info.Name] = info
+}
+
+// IsKnownUnit reports whether SimConnect knows the unit. Unit names are case insensitive.
+func IsKnownUnit(unit string) bool {
+	return units.IsSimConnectName(unit)
+}
+
+// ValidateSimVar checks a simvar against the catalog before it is added to a data definition.
+// It rejects unknown simvars and units, suggesting similar names, invalid indices (see SimVarName.Validate),
+// datatypes which can't hold the simvar and units of another dimension than the simvar's default unit.
+// An empty or "NULL" unit selects the default unit and is always accepted.
+// Local variables (e.g. "L:MyVar") aren't in the catalog and are not checked.
+func ValidateSimVar(name, unit string, dataType DWord) error {
+	if isLocalVarName(name) {
+		return nil
+	}
+	info, exists := LookupSimVar(name)
+	if !exists {
+		return fmt.Errorf("%w %q%s", ErrUnknownSimVar, name, didYouMean(catalogName(name), simVarNames()))
+	}
+	parsed, err := ParseSimVarName(name)
+	if err != nil {
+		return err
+	}
+	if err := parsed.Validate(); err != nil {
+		return err
+	}
+	if info.DataType != DataTypeInvalid && !canRequestAs(info.DataType, dataType) {
+		return fmt.Errorf("%s is a %s and can't be requested as %s: %w",
+			info.Name, dataKind(info.DataType), DataTypeToString(dataType), ErrIncompatibleDataType)
+	}
+	if !info.IsNumber() || unit == "" || strings.EqualFold(unit, "NULL") {
+		return nil
+	}
+	if !units.IsSimConnectName(unit) {
+		hint := didYouMean(unit, units.Names())
+		if abbreviated, err := units.Parse(unit); err == nil {
+			hint = ", did you mean " + strconv.Quote(abbreviated.Name) + "?"
+		}
+		return fmt.Errorf("%s: %w %q%s", info.Name, ErrUnknownUnit, unit, hint)
+	}
+	requested := units.MustParse(unit)
+	if info.Unit == "" {
+		return nil
+	}
+	if native, err := units.Parse(info.Unit); err == nil && isPhysical(native) && isPhysical(requested) && !native.Compatible(requested) {
+		return fmt.Errorf("%s is a %s in %s and can't be requested in %s (%s): %w",
+			info.Name, native.Dimension, info.Unit, unit, requested.Dimension, ErrIncompatibleUnit)
+	}
+	return nil
+}
+
+// isPhysical reports whether the unit has a physical dimension. SimConnect converts freely between
+// dimensionless and encoded units like bool, number, percent and enum, so those are never incompatible.
+func isPhysical(unit units.Unit) bool {
+	return unit.Dimension != units.Encoded && unit.Dimension != units.Dimensionless
+}
+
+// canRequestAs reports whether a simvar of the catalog's datatype can be requested as dataType.
+func canRequestAs(catalogType, dataType DWord) bool {
+	switch {
+	case isNumberDataType(catalogType):
+		return isNumberDataType(dataType)
+	case IsStringDataType(catalogType):
+		return IsStringDataType(dataType)
+	}
+	return catalogType == dataType
+}
+
+// dataKind describes a datatype for error messages, e.g. "number" for all numeric datatypes.
+func dataKind(dataType DWord) string {
+	switch {
+	case isNumberDataType(dataType):
+		return "number"
+	case IsStringDataType(dataType):
+		return "string"
+	}
+	return DataTypeToString(dataType)
+}
+
+func isNumberDataType(dataType DWord) bool {
+	switch dataType {
+	case DataTypeInt32, DataTypeInt64, DataTypeFloat32, DataTypeFloat64:
+		return true
+	}
+	return false
+}
+
+// catalogName normalizes a simvar name for the catalog, i.e. upper case with single spaces and without an index.
+func catalogName(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		if _, err := strconv.Atoi(strings.TrimSpace(name[i+1:])); err == nil {
+			name = name[:i]
+		}
+	}
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// isLocalVarName reports whether the name has a variable type prefix like "L:".
+func isLocalVarName(name string) bool {
+	name = strings.TrimSpace(name)
+	return len(name) > 2 && name[1] == ':' && name[0] != ' '
+}
+
+func simVarNames() []string {
+	c := theCatalog()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	names := make([]string, 0, len(c.vars))
+	for name := range c.vars {
+		names = append(names, name)
+	}
+	return names
+}
+
+// maxSuggestions is the number of similar names an error suggests at most.
+const maxSuggestions = 3
+
+// didYouMean returns a hint listing the candidates closest to name, or an empty string if none is close enough.
+// Names are compared case insensitively.
+func didYouMean(name string, candidates []string) string {
+	name = strings.ToLower(name)
+	type match struct {
+		name     string
+		distance int
+	}
+	limit := len(name)/4 + 1
+	matches := make([]match, 0)
+	for _, candidate := range candidates {
+		if d := editDistance(name, strings.ToLower(candidate)); d <= limit {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	quoted := make([]string, len(matches))
+	for i, m := range matches {
+		quoted[i] = strconv.Quote(m.name)
+	}
+	return ", did you mean " + strings.Join(quoted, " or ") + "?"
+}
+
+// editDistance returns the Levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
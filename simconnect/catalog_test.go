@@ -0,0 +1,98 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCatalogUnits(t *testing.T) {
+	for _, info := range SimVarInfos() {
+		if info.Unit == "" {
+			t.Errorf("%s has no unit", info.Name)
+		} else if info.IsNumber() && !IsKnownUnit(info.Unit) {
+			t.Errorf("%s has the unknown unit %q", info.Name, info.Unit)
+		}
+	}
+}
+
+func TestValidateSimVar(t *testing.T) {
+	tests := []struct {
+		name     string
+		unit     string
+		dataType DWord
+		err      error
+	}{
+		{"PLANE ALTITUDE", "feet", DataTypeFloat64, nil},
+		{"plane altitude", "meters", DataTypeInt32, nil},
+		{"ZULU TIME", "seconds", DataTypeFloat64, nil},
+		{"ZULU TIME", "hours", DataTypeFloat64, nil},
+		{"TITLE", "", DataTypeString256, nil},
+		{"L:MyVar", "number", DataTypeFloat64, nil},
+		{"PLANE ALTITUDES", "feet", DataTypeFloat64, ErrUnknownSimVar},
+		{"PLANE ALTITUDE", "fet", DataTypeFloat64, ErrUnknownUnit},
+		{"PLANE ALTITUDE", "knots", DataTypeFloat64, ErrIncompatibleUnit},
+		{"ZULU TIME", "feet", DataTypeFloat64, ErrIncompatibleUnit},
+		{"TITLE", "", DataTypeFloat64, ErrIncompatibleDataType},
+	}
+	for _, test := range tests {
+		if err := ValidateSimVar(test.name, test.unit, test.dataType); !errors.Is(err, test.err) {
+			t.Errorf("ValidateSimVar(%q, %q) = %v, want %v", test.name, test.unit, err, test.err)
+		}
+	}
+}
+
+func TestAddToDataDefinitionValidation(t *testing.T) {
+	const name = "SOME SIMVAR THE CATALOG MISSES"
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	if err := mate.AddToDataDefinition(NewDefineID(), name, "number", DataTypeFloat64); !errors.Is(err, ErrUnknownSimVar) {
+		t.Errorf("AddToDataDefinition = %v, want ErrUnknownSimVar", err)
+	}
+	if err := mate.AddToDataDefinition(NewDefineID(), "PLANE ALTITUDE", "knots", DataTypeFloat64); !errors.Is(err, ErrIncompatibleUnit) {
+		t.Errorf("AddToDataDefinition in knots = %v, want ErrIncompatibleUnit", err)
+	}
+	type misspelled struct {
+		Altitude float64 `simvar:"PLANE ALTITUDES,feet"`
+	}
+	if _, err := mate.RegisterDataDefinition(&misspelled{}); !errors.Is(err, ErrUnknownSimVar) {
+		t.Errorf("RegisterDataDefinition = %v, want ErrUnknownSimVar", err)
+	}
+	if _, err := mate.TryAddSimVar(name, "number", DataTypeFloat64); !errors.Is(err, ErrUnknownSimVar) {
+		t.Errorf("TryAddSimVar = %v, want ErrUnknownSimVar", err)
+	}
+	if err := mate.SetSimObjectData(name, "number", 1.0, DataTypeFloat64); !errors.Is(err, ErrUnknownSimVar) {
+		t.Errorf("SetSimObjectData = %v, want ErrUnknownSimVar", err)
+	}
+	if calls := backend.CallsNamed(scAddToDataDefinition); len(calls) != 0 {
+		t.Fatalf("rejected simvars were added: %v", calls)
+	}
+
+	mate.SkipValidation = true
+	if err := mate.AddToDataDefinition(NewDefineID(), name, "number", DataTypeFloat64); err != nil {
+		t.Errorf("AddToDataDefinition with SkipValidation = %v", err)
+	}
+	if _, err := mate.TryAddSimVar(name, "number", DataTypeFloat64); err != nil {
+		t.Errorf("TryAddSimVar with SkipValidation = %v", err)
+	}
+	if calls := backend.CallsNamed(scAddToDataDefinition); len(calls) != 1 || calls[0].Args[1] != name {
+		t.Fatalf("AddToDataDefinition calls = %v", calls)
+	}
+}
+
+func TestSetSimObjectDataNotSettable(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	if err := mate.SetSimObjectData("TITLE", "", "Cessna", DataTypeString256); !errors.Is(err, ErrNotSettable) {
+		t.Errorf("SetSimObjectData(TITLE) = %v, want ErrNotSettable", err)
+	}
+	if err := mate.SetSimObjectData("L:MyVar", "number", 1.0, DataTypeFloat64); err != nil {
+		t.Errorf("SetSimObjectData(L:MyVar) = %v", err)
+	}
+	if calls := backend.CallsNamed(scSetDataOnSimObject); len(calls) != 1 {
+		t.Errorf("%d SetDataOnSimObject calls, want 1", len(calls))
+	}
+	mate.SkipValidation = true
+	if err := mate.SetSimObjectData("TITLE", "", "Cessna", DataTypeString256); err != nil {
+		t.Errorf("SetSimObjectData(TITLE) with SkipValidation = %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+/*
+	SimConnect.h wraps all structures in #pragma pack(push, 1), i.e. there is no padding between fields.
+	Go structs are naturally aligned though, so casting a message pointer to e.g. *DataFacilityAirport
+	reads garbage after the 9-byte ICAO. The functions below decode and encode the packed little-endian
+	wire layout explicitly instead.
+*/
+
+// ErrShortBuffer is returned when a message is shorter than the structure it is decoded into.
+var ErrShortBuffer = errors.New("buffer too short")
+
+// PackedSize returns the number of bytes v occupies on the wire, or -1 if v is not a fixed-size value.
+func PackedSize(v interface{}) int {
+	return binary.Size(v)
+}
+
+// Unmarshal decodes the packed structure at the start of data into v, which must point to a fixed-size value (e.g. *RecvOpen).
+// Trailing bytes are ignored.
+func Unmarshal(data []byte, v interface{}) error {
+	size := binary.Size(v)
+	if size < 0 {
+		return fmt.Errorf("%T is not a fixed-size value", v)
+	}
+	if len(data) < size {
+		return fmt.Errorf("%T needs %d bytes, got %d: %w", v, size, len(data), ErrShortBuffer)
+	}
+	return binary.Read(bytes.NewReader(data[:size]), binary.LittleEndian, v)
+}
+
+// Marshal encodes v in the packed wire layout.
+func Marshal(v interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalSimObjectData decodes a RecvSimObjectData (or RecvSimObjectDataByType, RecvClientData) header
+// and returns the data block which follows it.
+func UnmarshalSimObjectData(data []byte) (RecvSimObjectData, []byte, error) {
+	var recv RecvSimObjectData
+	if err := Unmarshal(data, &recv); err != nil {
+		return recv, nil, err
+	}
+	return recv, data[PackedSize(&recv):], nil
+}
+
+// UnmarshalAirportList decodes a RecvAirportList and its airports.
+func UnmarshalAirportList(data []byte) (RecvAirportList, []DataFacilityAirport, error) {
+	var recv RecvAirportList
+	if err := Unmarshal(data, &recv); err != nil {
+		return recv, nil, err
+	}
+	rest := data[PackedSize(&recv):]
+	if err := checkArraySize(rest, recv.ArraySize, DataFacilityAirport{}); err != nil {
+		return recv, nil, err
+	}
+	items := make([]DataFacilityAirport, recv.ArraySize)
+	err := binary.Read(bytes.NewReader(rest), binary.LittleEndian, items)
+	return recv, items, err
+}
+
+// UnmarshalWaypointList decodes a RecvWaypointList and its waypoints.
+func UnmarshalWaypointList(data []byte) (RecvWaypointList, []DataFacilityWaypoint, error) {
+	var recv RecvWaypointList
+	if err := Unmarshal(data, &recv); err != nil {
+		return recv, nil, err
+	}
+	rest := data[PackedSize(&recv):]
+	if err := checkArraySize(rest, recv.ArraySize, DataFacilityWaypoint{}); err != nil {
+		return recv, nil, err
+	}
+	items := make([]DataFacilityWaypoint, recv.ArraySize)
+	err := binary.Read(bytes.NewReader(rest), binary.LittleEndian, items)
+	return recv, items, err
+}
+
+// UnmarshalNDBList decodes a RecvNDBList and its NDB stations.
+func UnmarshalNDBList(data []byte) (RecvNDBList, []DataFacilityNDB, error) {
+	var recv RecvNDBList
+	if err := Unmarshal(data, &recv); err != nil {
+		return recv, nil, err
+	}
+	rest := data[PackedSize(&recv):]
+	if err := checkArraySize(rest, recv.ArraySize, DataFacilityNDB{}); err != nil {
+		return recv, nil, err
+	}
+	items := make([]DataFacilityNDB, recv.ArraySize)
+	err := binary.Read(bytes.NewReader(rest), binary.LittleEndian, items)
+	return recv, items, err
+}
+
+// UnmarshalVORList decodes a RecvVORList and its VOR stations.
+func UnmarshalVORList(data []byte) (RecvVORList, []DataFacilityVOR, error) {
+	var recv RecvVORList
+	if err := Unmarshal(data, &recv); err != nil {
+		return recv, nil, err
+	}
+	rest := data[PackedSize(&recv):]
+	if err := checkArraySize(rest, recv.ArraySize, DataFacilityVOR{}); err != nil {
+		return recv, nil, err
+	}
+	items := make([]DataFacilityVOR, recv.ArraySize)
+	err := binary.Read(bytes.NewReader(rest), binary.LittleEndian, items)
+	return recv, items, err
+}
+
+func checkArraySize(data []byte, count DWord, elem interface{}) error {
+	size := uint64(count) * uint64(binary.Size(elem))
+	if uint64(len(data)) < size {
+		return fmt.Errorf("%d x %T needs %d bytes, got %d: %w", count, elem, size, len(data), ErrShortBuffer)
+	}
+	return nil
+}
+
+// TaggedDatum is a single datum of data requested with DataRequestFlagTagged.
+type TaggedDatum struct {
+	DatumID DWord
+	Value   interface{}
+}
+
+// stringVAlignment is the number of bytes a STRINGV is padded to a multiple of.
+const stringVAlignment = 4
+
+// InsertString appends s to dest as a STRINGV, i.e. zero-terminated and padded with zeros to a multiple of 4 bytes,
+// and returns the extended buffer. It is the Go equivalent of SimConnect_InsertString.
+func InsertString(dest []byte, s string) []byte {
+	dest = append(dest, s...)
+	dest = append(dest, 0)
+	for len(dest)%stringVAlignment != 0 {
+		dest = append(dest, 0)
+	}
+	return dest
+}
+
+// StringVSize returns the number of bytes InsertString appends for s.
+func StringVSize(s string) int {
+	n := len(s) + 1
+	return (n + stringVAlignment - 1) / stringVAlignment * stringVAlignment
+}
+
+// RetrieveString reads the STRINGV at the start of data. It returns the string and the number of bytes the STRINGV
+// occupies including its padding, which is where the next datum starts. Padding beyond the end of data is not required.
+// It is the Go equivalent of SimConnect_RetrieveString.
+func RetrieveString(data []byte) (string, int, error) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated stringv: %w", ErrMalformedMessage)
+	}
+	s := string(data[:end])
+	size := StringVSize(s)
+	if size > len(data) {
+		size = len(data)
+	}
+	return s, size, nil
+}
+
+// DecodeTagged decodes the data block of a RecvSimObjectData message which was requested with DataRequestFlagTagged.
+// Tagged data is a sequence of count pairs of a datum ID and its value, and only contains the datums that were sent,
+// e.g. the ones which changed. dataTypeOf returns the DataType* of a datum ID as passed to AddToDataDefinitionWithDatumID.
+func DecodeTagged(data []byte, count DWord, dataTypeOf func(datumID DWord) (DWord, bool)) ([]TaggedDatum, error) {
+	datums := make([]TaggedDatum, 0, count)
+	for i := DWord(0); i < count; i++ {
+		var datumID DWord
+		if err := Unmarshal(data, &datumID); err != nil {
+			return datums, fmt.Errorf("datum %d of %d: %w", i+1, count, err)
+		}
+		data = data[4:]
+		dataType, ok := dataTypeOf(datumID)
+		if !ok {
+			return datums, fmt.Errorf("unknown datum ID %d", datumID)
+		}
+		value, err := DecodeValue(data, dataType)
+		if err != nil {
+			return datums, fmt.Errorf("datum ID %d: %w", datumID, err)
+		}
+		size := DataTypeSize(dataType)
+		if dataType == DataTypeStringV {
+			_, size, _ = RetrieveString(data)
+		}
+		data = data[size:]
+		datums = append(datums, TaggedDatum{datumID, value})
+	}
+	return datums, nil
+}
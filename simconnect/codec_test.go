@@ -0,0 +1,352 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// golden is a byte buffer laid out by hand, with every field at the offset SimConnect.h gives it under #pragma pack(1).
+type golden []byte
+
+func newGolden(size int) golden {
+	return make(golden, size)
+}
+
+func (g golden) dword(offset int, v uint32) golden {
+	binary.LittleEndian.PutUint32(g[offset:], v)
+	return g
+}
+
+func (g golden) float32(offset int, v float32) golden {
+	binary.LittleEndian.PutUint32(g[offset:], math.Float32bits(v))
+	return g
+}
+
+func (g golden) float64(offset int, v float64) golden {
+	binary.LittleEndian.PutUint64(g[offset:], math.Float64bits(v))
+	return g
+}
+
+func (g golden) str(offset int, s string) golden {
+	copy(g[offset:], s)
+	return g
+}
+
+func TestPackedSizes(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		size  int
+	}{
+		{&Recv{}, 12},
+		{&RecvSimObjectData{}, 40},
+		{&RecvEvent{}, 24},
+		{&RecvEventFrame{}, 32},
+		{&RecvEventFilename{}, 288},
+		{&RecvFacilitiesList{}, 28},
+		{&DataFacilityAirport{}, 33},
+		{&DataFacilityWaypoint{}, 37},
+		{&DataFacilityNDB{}, 41},
+		{&DataFacilityVOR{}, 77},
+	}
+	for _, test := range tests {
+		if size := PackedSize(test.value); size != test.size {
+			t.Errorf("PackedSize(%T) = %d, want %d", test.value, size, test.size)
+		}
+	}
+}
+
+// airportBytes lays out a SIMCONNECT_DATA_FACILITY_AIRPORT: szIdent[9], then the doubles without padding.
+func airportBytes() golden {
+	return newGolden(33).
+		str(0, "KSEA").
+		float64(9, 47.449).
+		float64(17, -122.309).
+		float64(25, 131.7)
+}
+
+func TestUnmarshalAirport(t *testing.T) {
+	data := airportBytes()
+	var airport DataFacilityAirport
+	if err := Unmarshal(data, &airport); err != nil {
+		t.Fatal(err)
+	}
+	if icao := CString(airport.Icao[:]); icao != "KSEA" {
+		t.Errorf("Icao = %q", icao)
+	}
+	if airport.Latitude != 47.449 {
+		t.Errorf("Latitude = %v", airport.Latitude)
+	}
+	if airport.Longitude != -122.309 {
+		t.Errorf("Longitude = %v", airport.Longitude)
+	}
+	if airport.Altitude != 131.7 {
+		t.Errorf("Altitude = %v", airport.Altitude)
+	}
+
+	encoded, err := Marshal(&airport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, data) {
+		t.Errorf("Marshal = % x\nwant      % x", encoded, []byte(data))
+	}
+}
+
+func TestUnmarshalShortBuffer(t *testing.T) {
+	var airport DataFacilityAirport
+	if err := Unmarshal(airportBytes()[:32], &airport); !errors.Is(err, ErrShortBuffer) {
+		t.Fatalf("err = %v, want ErrShortBuffer", err)
+	}
+}
+
+func TestUnmarshalVORList(t *testing.T) {
+	const header = 28
+	data := newGolden(header+77).
+		dword(0, header+77).
+		dword(4, 4).
+		dword(8, uint32(RecvIDVORList)).
+		dword(12, 3).       // dwRequestID
+		dword(16, 1).       // dwArraySize
+		dword(20, 0).       // dwEntryNumber
+		dword(24, 1).       // dwOutOf
+		str(header, "SEA"). // icao
+		float64(header+9, 47.435).
+		float64(header+17, -122.31).
+		float64(header+25, 110).
+		float32(header+33, 15.5).    // fMagVar
+		dword(header+37, 116800000). // Frequency
+		dword(header+41, 0x1F).      // Flags
+		float32(header+45, 163.2).   // fLocalizer
+		float64(header+49, 47.44).   // GlideLat
+		float64(header+57, -122.3).  // GlideLon
+		float64(header+65, 120).     // GlideAlt
+		float32(header+73, 3)        // fGlideSlopeAngle
+
+	recv, vors, err := UnmarshalVORList(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recv.Size != header+77 || recv.ID != RecvIDVORList || recv.RequestID != 3 || recv.ArraySize != 1 || recv.OutOf != 1 {
+		t.Errorf("header = %+v", recv)
+	}
+	if len(vors) != 1 {
+		t.Fatalf("got %d VORs, want 1", len(vors))
+	}
+	vor := vors[0]
+	if icao := CString(vor.Icao[:]); icao != "SEA" {
+		t.Errorf("Icao = %q", icao)
+	}
+	if vor.Latitude != 47.435 || vor.Longitude != -122.31 || vor.Altitude != 110 {
+		t.Errorf("position = %v %v %v", vor.Latitude, vor.Longitude, vor.Altitude)
+	}
+	if vor.MagVar != 15.5 {
+		t.Errorf("MagVar = %v", vor.MagVar)
+	}
+	if vor.Frequency != 116800000 {
+		t.Errorf("Frequency = %v", vor.Frequency)
+	}
+	if vor.Flags != 0x1F {
+		t.Errorf("Flags = %x", vor.Flags)
+	}
+	if vor.Localizer != 163.2 {
+		t.Errorf("Localizer = %v", vor.Localizer)
+	}
+	if vor.GlideLat != 47.44 || vor.GlideLon != -122.3 || vor.GlideAlt != 120 {
+		t.Errorf("glide slope = %v %v %v", vor.GlideLat, vor.GlideLon, vor.GlideAlt)
+	}
+	if vor.GlideSlopeAngle != 3 {
+		t.Errorf("GlideSlopeAngle = %v", vor.GlideSlopeAngle)
+	}
+
+	encoded, err := Marshal(&vor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, data[header:]) {
+		t.Errorf("Marshal = % x\nwant      % x", encoded, []byte(data[header:]))
+	}
+}
+
+func TestUnmarshalVORListShort(t *testing.T) {
+	data := newGolden(28+76).dword(16, 1)
+	if _, _, err := UnmarshalVORList(data); !errors.Is(err, ErrShortBuffer) {
+		t.Fatalf("err = %v, want ErrShortBuffer", err)
+	}
+}
+
+func TestUnmarshalSimObjectData(t *testing.T) {
+	data := newGolden(40+8).
+		dword(0, 48).
+		dword(4, 4).
+		dword(8, uint32(RecvIDSimobjectData)).
+		dword(12, 5). // dwRequestID
+		dword(16, 1). // dwObjectID
+		dword(20, 9). // dwDefineID
+		dword(24, 2). // dwFlags
+		dword(28, 1). // dwentrynumber
+		dword(32, 1). // dwoutof
+		dword(36, 1). // dwDefineCount
+		float64(40, 2500)
+
+	recv, rest, err := UnmarshalSimObjectData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RecvSimObjectData{
+		Recv:        Recv{Size: 48, Version: 4, ID: RecvIDSimobjectData},
+		RequestID:   5,
+		ObjectID:    1,
+		DefineID:    9,
+		Flags:       2,
+		EntryNumber: 1,
+		OutOf:       1,
+		DefineCount: 1,
+	}
+	if recv != want {
+		t.Errorf("header = %+v\nwant     %+v", recv, want)
+	}
+	if !bytes.Equal(rest, data[40:]) {
+		t.Errorf("data = % x", rest)
+	}
+	value, err := DecodeValue(rest, DataTypeFloat64)
+	if err != nil || value != float64(2500) {
+		t.Errorf("value = %v, %v", value, err)
+	}
+
+	encoded, err := Marshal(&recv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, data[:40]) {
+		t.Errorf("Marshal = % x\nwant      % x", encoded, []byte(data[:40]))
+	}
+}
+
+func TestStringV(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []byte
+	}{
+		{"", []byte{0, 0, 0, 0}},
+		{"abc", []byte{'a', 'b', 'c', 0}},
+		{"abcd", []byte{'a', 'b', 'c', 'd', 0, 0, 0, 0}},
+		{"C172", []byte{'C', '1', '7', '2', 0, 0, 0, 0}},
+		{"Cessna", []byte{'C', 'e', 's', 's', 'n', 'a', 0, 0}},
+	}
+	for _, test := range tests {
+		encoded := InsertString(nil, test.s)
+		if !bytes.Equal(encoded, test.want) {
+			t.Errorf("InsertString(%q) = % x, want % x", test.s, encoded, test.want)
+		}
+		if size := StringVSize(test.s); size != len(test.want) {
+			t.Errorf("StringVSize(%q) = %d, want %d", test.s, size, len(test.want))
+		}
+	}
+
+	// two strings back to back, followed by a DWORD
+	data := InsertString(nil, "Cessna")
+	data = InsertString(data, "N12345")
+	data = append(data, 0x2A, 0, 0, 0)
+	first, size, err := RetrieveString(data)
+	if err != nil || first != "Cessna" || size != 8 {
+		t.Fatalf("first = %q, %d, %v", first, size, err)
+	}
+	data = data[size:]
+	second, size, err := RetrieveString(data)
+	if err != nil || second != "N12345" || size != 8 {
+		t.Fatalf("second = %q, %d, %v", second, size, err)
+	}
+	if rest := binary.LittleEndian.Uint32(data[size:]); rest != 0x2A {
+		t.Errorf("following DWORD = %x", rest)
+	}
+
+	if _, _, err := RetrieveString([]byte{'a', 'b'}); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("unterminated: err = %v, want ErrMalformedMessage", err)
+	}
+	// the padding of the last string may be missing
+	if s, size, err := RetrieveString([]byte{'a', 'b', 0}); err != nil || s != "ab" || size != 3 {
+		t.Errorf("unpadded = %q, %d, %v", s, size, err)
+	}
+}
+
+// taggedBytes lays out tagged data: every datum is a DWORD datum ID followed by its value.
+func taggedBytes(datums ...interface{}) []byte {
+	data := make([]byte, 0)
+	for i := 0; i < len(datums); i += 2 {
+		data = append(data, newGolden(4).dword(0, uint32(datums[i].(int)))...)
+		switch value := datums[i+1].(type) {
+		case int32:
+			data = append(data, newGolden(4).dword(0, uint32(value))...)
+		case float64:
+			data = append(data, newGolden(8).float64(0, value)...)
+		case string:
+			data = InsertString(data, value)
+		}
+	}
+	return data
+}
+
+// taggedTypes are the datum types of the definition the tagged tests decode: 0 float64, 1 int32, 2 stringv, 3 float64.
+func taggedTypes(datumID DWord) (DWord, bool) {
+	types := []DWord{DataTypeFloat64, DataTypeInt32, DataTypeStringV, DataTypeFloat64}
+	if int(datumID) >= len(types) {
+		return DataTypeInvalid, false
+	}
+	return types[datumID], true
+}
+
+func TestDecodeTagged(t *testing.T) {
+	data := taggedBytes(3, 1.5, 1, int32(-7))
+	if len(data) != 4+8+4+4 {
+		t.Fatalf("tagged data is %d bytes", len(data))
+	}
+	datums, err := DecodeTagged(data, 2, taggedTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TaggedDatum{{3, float64(1.5)}, {1, int32(-7)}}
+	if len(datums) != len(want) {
+		t.Fatalf("datums = %v, want %v", datums, want)
+	}
+	for i := range want {
+		if datums[i] != want[i] {
+			t.Errorf("datum %d = %v, want %v", i, datums[i], want[i])
+		}
+	}
+}
+
+func TestDecodeTaggedStringV(t *testing.T) {
+	// the datum after the STRINGV starts behind its padding
+	data := taggedBytes(2, "Cessna", 0, 3.25)
+	datums, err := DecodeTagged(data, 2, taggedTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datums) != 2 || datums[0] != (TaggedDatum{2, "Cessna"}) || datums[1] != (TaggedDatum{0, float64(3.25)}) {
+		t.Errorf("datums = %v", datums)
+	}
+}
+
+func TestDecodeTaggedErrors(t *testing.T) {
+	data := taggedBytes(0, 1.0, 1, int32(2))
+	tests := []struct {
+		name  string
+		data  []byte
+		count DWord
+		check func(error) bool
+	}{
+		{"unknown datum ID", taggedBytes(4, 1.0), 1, func(err error) bool { return err != nil }},
+		{"missing datum", data, 3, func(err error) bool { return errors.Is(err, ErrShortBuffer) }},
+		{"truncated datum ID", data[:14], 2, func(err error) bool { return errors.Is(err, ErrShortBuffer) }},
+		{"truncated value", data[:8], 1, func(err error) bool { return errors.Is(err, ErrMalformedMessage) }},
+		{"unterminated stringv", taggedBytes(2, "abc")[:7], 1, func(err error) bool { return errors.Is(err, ErrMalformedMessage) }},
+	}
+	for _, test := range tests {
+		if _, err := DecodeTagged(test.data, test.count, taggedTypes); !test.check(err) {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+	}
+}
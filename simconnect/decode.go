@@ -0,0 +1,215 @@
+package simconnect
+
+import (
+	"fmt"
+)
+
+// The following types are returned by Decode for messages which carry variable-length data
+// behind their fixed-size header.
+
+// ObjectData is a decoded RecvIDSimobjectData message.
+type ObjectData struct {
+	RecvSimObjectData
+	Data []byte // DefineCount data items as laid out by the data definition
+}
+
+// ObjectDataByType is a decoded RecvIDSimObjectDataByType message.
+type ObjectDataByType struct {
+	RecvSimObjectDataByType
+	Data []byte
+}
+
+// ClientData is a decoded RecvIDClientData message.
+type ClientData struct {
+	RecvClientData
+	Data []byte
+}
+
+// WeatherObservation is a decoded RecvIDWeatherObservation message.
+type WeatherObservation struct {
+	RecvWeatherObservation
+	Metar string
+}
+
+// CloudState is a decoded RecvIDCloudState message.
+type CloudState struct {
+	RecvCloudState
+	Data []byte // ArraySize bytes, usually CloudStateArraySize
+}
+
+// CustomAction is a decoded RecvIDCustomAction message.
+type CustomAction struct {
+	RecvCustomAction
+	Payload string
+}
+
+// AirportList is a decoded RecvIDAirportList message.
+type AirportList struct {
+	RecvAirportList
+	Airports []DataFacilityAirport
+}
+
+// WaypointList is a decoded RecvIDWaypointList message.
+type WaypointList struct {
+	RecvWaypointList
+	Waypoints []DataFacilityWaypoint
+}
+
+// NDBList is a decoded RecvIDNDBList message.
+type NDBList struct {
+	RecvNDBList
+	NDBs []DataFacilityNDB
+}
+
+// VORList is a decoded RecvIDVORList message.
+type VORList struct {
+	RecvVORList
+	VORs []DataFacilityVOR
+}
+
+// Decode turns a message into a pointer to its concrete type, e.g. *RecvEventFrame for RecvIDEventFrame
+// or *AirportList for RecvIDAirportList. Messages with an unknown ID decode to their *Recv header.
+func Decode(msg *Message) (interface{}, error) {
+	var v interface{}
+	switch msg.ID() {
+	case RecvIDException:
+		v = &RecvException{}
+	case RecvIDOpen:
+		v = &RecvOpen{}
+	case RecvIDQuit:
+		v = &RecvQuit{}
+	case RecvIDEvent:
+		v = &RecvEvent{}
+	case RecvIDEventObjectAddRemove:
+		v = &RecvEventObjectAddRemove{}
+	case RecvIDEventFilename:
+		v = &RecvEventFilename{}
+	case RecvIDEventFrame:
+		v = &RecvEventFrame{}
+	case RecvIDAssignedObjectID:
+		v = &RecvAssignedObjectID{}
+	case RecvIDReservedKey:
+		v = &RecvReservedKey{}
+	case RecvIDSystemState:
+		v = &RecvSystemState{}
+	case RecvIDEventWeatherMode:
+		v = &RecvEventWeatherMode{}
+	case RecvIDEventMultiplayerServerStarted:
+		v = &RecvEventMultiplayerServerStarted{}
+	case RecvIDEventMultiplayerClientStarted:
+		v = &RecvEventMultiplayerClientStarted{}
+	case RecvIDEventMultiplayerSessionEnded:
+		v = &RecvEventMultiplayerSessionEnded{}
+	case RecvIDEventRaceEnd:
+		v = &RecvEventRaceEnd{}
+	case RecvIDEventRaceLap:
+		v = &RecvEventRaceLap{}
+
+	case RecvIDSimobjectData:
+		recv, data, err := msg.SimObjectData()
+		if err != nil {
+			return nil, err
+		}
+		return &ObjectData{recv, data}, nil
+
+	case RecvIDSimObjectDataByType:
+		recv, data, err := msg.SimObjectDataByType()
+		if err != nil {
+			return nil, err
+		}
+		return &ObjectDataByType{recv, data}, nil
+
+	case RecvIDClientData:
+		recv, data, err := msg.ClientData()
+		if err != nil {
+			return nil, err
+		}
+		return &ClientData{recv, data}, nil
+
+	case RecvIDWeatherObservation:
+		out := &WeatherObservation{}
+		rest, err := msg.decodeHeader(&out.RecvWeatherObservation)
+		if err != nil {
+			return nil, err
+		}
+		out.Metar, err = decodeStringV(rest)
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+
+	case RecvIDCloudState:
+		out := &CloudState{}
+		rest, err := msg.decodeHeader(&out.RecvCloudState)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkArraySize(rest, out.ArraySize, byte(0)); err != nil {
+			return nil, fmt.Errorf("message %d: %v: %w", msg.ID(), err, ErrMalformedMessage)
+		}
+		out.Data = rest[:out.ArraySize]
+		return out, nil
+
+	case RecvIDCustomAction:
+		out := &CustomAction{}
+		rest, err := msg.decodeHeader(&out.RecvCustomAction)
+		if err != nil {
+			return nil, err
+		}
+		out.Payload, err = decodeStringV(rest)
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+
+	case RecvIDAirportList:
+		recv, items, err := UnmarshalAirportList(msg.data)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %v: %w", msg.ID(), err, ErrMalformedMessage)
+		}
+		return &AirportList{recv, items}, nil
+
+	case RecvIDWaypointList:
+		recv, items, err := UnmarshalWaypointList(msg.data)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %v: %w", msg.ID(), err, ErrMalformedMessage)
+		}
+		return &WaypointList{recv, items}, nil
+
+	case RecvIDNDBList:
+		recv, items, err := UnmarshalNDBList(msg.data)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %v: %w", msg.ID(), err, ErrMalformedMessage)
+		}
+		return &NDBList{recv, items}, nil
+
+	case RecvIDVORList:
+		recv, items, err := UnmarshalVORList(msg.data)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %v: %w", msg.ID(), err, ErrMalformedMessage)
+		}
+		return &VORList{recv, items}, nil
+
+	default:
+		// RecvIDNull, RecvIDPick and anything newer than this package
+		v = &Recv{}
+	}
+	if err := msg.Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// decodeHeader decodes the fixed-size part of the message into v and returns the bytes which follow it.
+func (msg *Message) decodeHeader(v interface{}) ([]byte, error) {
+	if err := msg.Decode(v); err != nil {
+		return nil, err
+	}
+	return msg.data[PackedSize(v):], nil
+}
+
+// decodeStringV decodes a SIMCONNECT_STRINGV, i.e. a zero-terminated string of variable length.
+func decodeStringV(data []byte) (string, error) {
+	s, _, err := RetrieveString(data)
+	return s, err
+}
@@ -0,0 +1,134 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+)
+
+// recvBytes returns a buffer of the given size which starts with a Recv header for the message ID.
+func recvBytes(id DWord, size int) golden {
+	return newGolden(size).dword(0, uint32(size)).dword(4, 4).dword(8, uint32(id))
+}
+
+func decodeBytes(t *testing.T, data []byte) (interface{}, error) {
+	t.Helper()
+	msg, err := NewMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Decode(msg)
+}
+
+func TestDecodeCloudState(t *testing.T) {
+	const header = 20
+	data := recvBytes(RecvIDCloudState, header+5).dword(12, 9).dword(16, 5)
+	copy(data[header:], []byte{1, 2, 3, 4, 5})
+	value, err := decodeBytes(t, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloudState, ok := value.(*CloudState)
+	if !ok {
+		t.Fatalf("decoded %T, want *CloudState", value)
+	}
+	if cloudState.RequestID != 9 || cloudState.ArraySize != 5 || string(cloudState.Data) != "\x01\x02\x03\x04\x05" {
+		t.Errorf("got %+v", cloudState)
+	}
+
+	truncated := recvBytes(RecvIDCloudState, header+5).dword(16, 6)
+	if _, err := decodeBytes(t, truncated); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("truncated: err = %v, want ErrMalformedMessage", err)
+	}
+}
+
+func TestDecodeCustomAction(t *testing.T) {
+	// RecvEvent, a 16 byte GUID and dwWaitForCompletion, then the payload
+	const header = 44
+	data := recvBytes(RecvIDCustomAction, header+8).
+		dword(16, 3).
+		dword(24, 0xA1B2C3D4).
+		dword(40, 1).
+		str(header, "action")
+	value, err := decodeBytes(t, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	action, ok := value.(*CustomAction)
+	if !ok {
+		t.Fatalf("decoded %T, want *CustomAction", value)
+	}
+	if action.EventID != 3 || action.WaitForCompletion != 1 || action.Payload != "action" {
+		t.Errorf("got %+v", action)
+	}
+
+	unterminated := recvBytes(RecvIDCustomAction, header+4).str(header, "acti")
+	if _, err := decodeBytes(t, unterminated); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("unterminated payload: err = %v, want ErrMalformedMessage", err)
+	}
+	short := recvBytes(RecvIDCustomAction, header-4)
+	if _, err := decodeBytes(t, short); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("short header: err = %v, want ErrMalformedMessage", err)
+	}
+}
+
+func TestDecodeFacilityLists(t *testing.T) {
+	const header = 28
+	airport := airportBytes()
+	tests := []struct {
+		id    DWord
+		size  int // of one item
+		check func(value interface{}) bool
+	}{
+		{RecvIDAirportList, 33, func(value interface{}) bool {
+			list, ok := value.(*AirportList)
+			return ok && len(list.Airports) == 2 && CString(list.Airports[1].Icao[:]) == "KSEA" && list.Airports[1].Altitude == 131.7
+		}},
+		{RecvIDWaypointList, 37, func(value interface{}) bool {
+			list, ok := value.(*WaypointList)
+			return ok && len(list.Waypoints) == 2 && list.Waypoints[1].Latitude == 47.449 && list.Waypoints[1].MagVar == -15
+		}},
+		{RecvIDNDBList, 41, func(value interface{}) bool {
+			list, ok := value.(*NDBList)
+			return ok && len(list.NDBs) == 2 && list.NDBs[1].MagVar == -15 && list.NDBs[1].Frequency == 362000
+		}},
+		{RecvIDVORList, 77, func(value interface{}) bool {
+			list, ok := value.(*VORList)
+			return ok && len(list.VORs) == 2 && list.VORs[1].Frequency == 362000 && CString(list.VORs[0].Icao[:]) == ""
+		}},
+	}
+	for _, test := range tests {
+		// the first item is zero, the second one an airport followed by MagVar and the frequency
+		data := recvBytes(test.id, header+2*test.size).dword(12, 5).dword(16, 2).dword(24, 1)
+		second := header + test.size
+		copy(data[second:], airport)
+		if test.size > 33 {
+			data.float32(second+33, -15)
+		}
+		if test.size > 37 {
+			data.dword(second+37, 362000)
+		}
+		value, err := decodeBytes(t, data)
+		if err != nil {
+			t.Errorf("message %d: %v", test.id, err)
+			continue
+		}
+		if !test.check(value) {
+			t.Errorf("message %d: got %+v", test.id, value)
+		}
+
+		truncated := recvBytes(test.id, header+2*test.size-1).dword(16, 2)
+		if _, err := decodeBytes(t, truncated); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("message %d truncated: err = %v, want ErrMalformedMessage", test.id, err)
+		}
+	}
+}
+
+func TestDecodeUnknownMessage(t *testing.T) {
+	value, err := decodeBytes(t, recvBytes(999, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recv, ok := value.(*Recv); !ok || recv.ID != 999 || recv.Size != 16 {
+		t.Errorf("got %#v, want the *Recv header", value)
+	}
+}
@@ -0,0 +1,547 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+/*
+	A data definition can be declared as a struct whose fields are tagged with the simvar they hold:
+
+	type Position struct {
+		Latitude  float64 `simvar:"PLANE LATITUDE,degrees"`
+		Longitude float64 `simvar:"PLANE LONGITUDE,degrees"`
+		Title     string  `simvar:"TITLE,,string256"`
+	}
+
+	The tag is "NAME,unit,datatype". The datatype is one of the names known to StringToDataType and may be omitted
+	for float64, float32, int32, int64, bool, InitPosition, MarkerState, Waypoint, LatLogAlt and XYZ fields, as well as for byte arrays
+	of a SimConnect string size. Plain string fields default to string256. Untagged fields and fields tagged
+	with "-" are skipped.
+*/
+
+const simVarTag = "simvar"
+
+// DefinitionField is a single datum of a DataDefinition.
+type DefinitionField struct {
+	Name     string
+	Unit     string
+	DataType DWord
+	DatumID  DWord // the position of the field in the definition, identifies the datum in tagged data
+	index    []int
+}
+
+// DataDefinition maps a tagged struct type to a SimConnect data definition.
+type DataDefinition struct {
+	DefineID DWord
+	Type     reflect.Type
+	Fields   []DefinitionField
+}
+
+// NewDataDefinition parses the simvar tags of v, which must be a struct or a pointer to a struct.
+// The definition is not registered with SimConnect, see SimConnect.RegisterDataDefinition.
+func NewDataDefinition(v interface{}) (*DataDefinition, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("data definition needs a struct, got %T", v)
+	}
+	def := &DataDefinition{
+		DefineID: NewDefineID(),
+		Type:     t,
+		Fields:   make([]DefinitionField, 0, t.NumField()),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(simVarTag)
+		if !ok || tag == "-" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("%s.%s: field is not exported", t.Name(), sf.Name)
+		}
+		field, err := parseSimVarTag(tag, sf.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), sf.Name, err)
+		}
+		field.DatumID = DWord(len(def.Fields))
+		field.index = sf.Index
+		def.Fields = append(def.Fields, field)
+	}
+	if len(def.Fields) == 0 {
+		return nil, fmt.Errorf("%s has no simvar fields", t.Name())
+	}
+	return def, nil
+}
+
+func parseSimVarTag(tag string, t reflect.Type) (DefinitionField, error) {
+	parts := strings.Split(tag, ",")
+	if len(parts) > 3 {
+		return DefinitionField{}, fmt.Errorf("malformed simvar tag %q", tag)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	field := DefinitionField{
+		Name: strings.TrimSpace(parts[0]),
+		Unit: strings.TrimSpace(parts[1]),
+	}
+	if field.Name == "" {
+		return field, fmt.Errorf("simvar tag %q has no name", tag)
+	}
+	if dataType := strings.TrimSpace(parts[2]); dataType != "" {
+		field.DataType = StringToDataType(dataType)
+		if field.DataType == DataTypeInvalid {
+			return field, fmt.Errorf("unknown datatype %q", dataType)
+		}
+	} else {
+		field.DataType = dataTypeOf(t)
+		if field.DataType == DataTypeInvalid {
+			return field, fmt.Errorf("can't derive datatype from %s, add it to the tag", t)
+		}
+	}
+	if !canHold(t, field.DataType) {
+		return field, fmt.Errorf("%s can't hold a %s", t, DataTypeToString(field.DataType))
+	}
+	return field, nil
+}
+
+// dataTypeOf returns the datatype a Go type maps to by default.
+func dataTypeOf(t reflect.Type) DWord {
+	switch t {
+	case reflect.TypeOf(InitPosition{}):
+		return DataTypeInitPosition
+	case reflect.TypeOf(MarkerState{}):
+		return DataTypeMarkerState
+	case reflect.TypeOf(Waypoint{}):
+		return DataTypeWaypoint
+	case reflect.TypeOf(LatLogAlt{}):
+		return DataTypeLatLonAlt
+	case reflect.TypeOf(XYZ{}):
+		return DataTypeXYZ
+	}
+	switch t.Kind() {
+	case reflect.Float64:
+		return DataTypeFloat64
+	case reflect.Float32:
+		return DataTypeFloat32
+	case reflect.Int64, reflect.Int, reflect.Uint64, reflect.Uint:
+		return DataTypeInt64
+	case reflect.Int32, reflect.Uint32, reflect.Int16, reflect.Uint16, reflect.Int8, reflect.Uint8, reflect.Bool:
+		return DataTypeInt32
+	case reflect.String:
+		return DataTypeString256
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			for _, dataType := range []DWord{DataTypeString8, DataTypeString32, DataTypeString64, DataTypeString128, DataTypeString256, DataTypeString260} {
+				if DataTypeSize(dataType) == t.Len() {
+					return dataType
+				}
+			}
+		}
+	}
+	return DataTypeInvalid
+}
+
+// canHold reports whether a field of type t can be decoded from and encoded to the datatype.
+func canHold(t reflect.Type, dataType DWord) bool {
+	switch dataType {
+	case DataTypeInt32, DataTypeInt64, DataTypeFloat32, DataTypeFloat64:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.Bool:
+			return true
+		}
+		return false
+	case DataTypeString8, DataTypeString32, DataTypeString64, DataTypeString128, DataTypeString256, DataTypeString260:
+		return t.Kind() == reflect.String || (t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8)
+	case DataTypeStringV:
+		return t.Kind() == reflect.String
+	}
+	size := DataTypeSize(dataType)
+	return size > 0 && binary.Size(reflect.Zero(t).Interface()) == size
+}
+
+// DataTypeSize returns the number of bytes a datum of the given DataType* occupies, or -1 for DataTypeStringV and unknown types.
+func DataTypeSize(dataType DWord) int {
+	switch dataType {
+	case DataTypeInt32, DataTypeFloat32:
+		return 4
+	case DataTypeInt64, DataTypeFloat64:
+		return 8
+	case DataTypeString8:
+		return 8
+	case DataTypeString32:
+		return 32
+	case DataTypeString64:
+		return 64
+	case DataTypeString128:
+		return 128
+	case DataTypeString256:
+		return 256
+	case DataTypeString260:
+		return 260
+	case DataTypeInitPosition:
+		return binary.Size(InitPosition{})
+	case DataTypeMarkerState:
+		return binary.Size(MarkerState{})
+	case DataTypeWaypoint:
+		return binary.Size(Waypoint{})
+	case DataTypeLatLonAlt:
+		return binary.Size(LatLogAlt{})
+	case DataTypeXYZ:
+		return binary.Size(XYZ{})
+	}
+	return -1
+}
+
+// Size returns the number of bytes an encoded value occupies, or -1 if the definition contains a STRINGV.
+func (def *DataDefinition) Size() int {
+	size := 0
+	for _, field := range def.Fields {
+		n := DataTypeSize(field.DataType)
+		if n < 0 {
+			return -1
+		}
+		size += n
+	}
+	return size
+}
+
+// Decode decodes the data block of a RecvSimObjectData message into v, which must be a pointer to the definition's type.
+func (def *DataDefinition) Decode(data []byte, v interface{}) error {
+	rv, err := def.structValue(v)
+	if err != nil {
+		return err
+	}
+	for _, field := range def.Fields {
+		fv := rv.FieldByIndex(field.index)
+		var n int
+		if field.DataType == DataTypeStringV {
+			var s string
+			var err error
+			if s, n, err = RetrieveString(data); err != nil {
+				return fmt.Errorf("%s: %w", field.Name, err)
+			}
+			fv.SetString(s)
+		} else {
+			n = DataTypeSize(field.DataType)
+			if len(data) < n {
+				return fmt.Errorf("%s needs %d bytes, got %d: %w", field.Name, n, len(data), ErrShortBuffer)
+			}
+			if err := decodeField(data[:n], field.DataType, fv); err != nil {
+				return fmt.Errorf("%s: %w", field.Name, err)
+			}
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// DecodeMessage decodes a RecvIDSimobjectData or RecvIDSimObjectDataByType message of this definition into v.
+func (def *DataDefinition) DecodeMessage(msg *Message, v interface{}) error {
+	var recv RecvSimObjectData
+	var data []byte
+	var err error
+	switch msg.ID() {
+	case RecvIDSimobjectData:
+		recv, data, err = msg.SimObjectData()
+	case RecvIDSimObjectDataByType:
+		var byType RecvSimObjectDataByType
+		byType, data, err = msg.SimObjectDataByType()
+		recv = byType.RecvSimObjectData
+	default:
+		return fmt.Errorf("message %d doesn't carry simobject data", msg.ID())
+	}
+	if err != nil {
+		return err
+	}
+	if recv.DefineID != def.DefineID {
+		return fmt.Errorf("message is for define ID %d, not %d", recv.DefineID, def.DefineID)
+	}
+	if recv.Flags&DataRequestFlagTagged != 0 {
+		_, err := def.DecodeTagged(data, recv.DefineCount, v)
+		return err
+	}
+	return def.Decode(data, v)
+}
+
+// DecodeTagged decodes data requested with DataRequestFlagTagged into v, which must be a pointer to the definition's type.
+// Only the count datums present in the data are set, all other fields are left alone.
+// It returns the indices into Fields of the datums which were set.
+func (def *DataDefinition) DecodeTagged(data []byte, count DWord, v interface{}) ([]int, error) {
+	rv, err := def.structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	datums, err := DecodeTagged(data, count, func(datumID DWord) (DWord, bool) {
+		if int(datumID) >= len(def.Fields) {
+			return DataTypeInvalid, false
+		}
+		return def.Fields[datumID].DataType, true
+	})
+	if err != nil {
+		return nil, err
+	}
+	set := make([]int, 0, len(datums))
+	for _, datum := range datums {
+		field := def.Fields[datum.DatumID]
+		if err := setFieldValue(rv.FieldByIndex(field.index), field.DataType, datum.Value); err != nil {
+			return set, fmt.Errorf("%s: %w", field.Name, err)
+		}
+		set = append(set, int(datum.DatumID))
+	}
+	return set, nil
+}
+
+// setFieldValue stores a value returned by DecodeValue in a field.
+func setFieldValue(fv reflect.Value, dataType DWord, value interface{}) error {
+	switch v := value.(type) {
+	case int32:
+		return setNumber(fv, float64(v), int64(v))
+	case int64:
+		return setNumber(fv, float64(v), v)
+	case float32:
+		return setNumber(fv, float64(v), int64(v))
+	case float64:
+		return setNumber(fv, v, int64(v))
+	case string:
+		fv.SetString(v)
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Kind() == reflect.Array && fv.Kind() == reflect.String:
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		fv.SetString(CString(b))
+	case rv.Kind() == reflect.Array && fv.Kind() == reflect.Array:
+		reflect.Copy(fv, rv)
+	case rv.Type().AssignableTo(fv.Type()):
+		fv.Set(rv)
+	default:
+		return fmt.Errorf("can't store a %s in %s", DataTypeToString(dataType), fv.Type())
+	}
+	return nil
+}
+
+// Encode encodes v, a value of or a pointer to the definition's type, for SetDataOnSimObject.
+func (def *DataDefinition) Encode(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Type() != def.Type {
+		return nil, fmt.Errorf("expected %s, got %T", def.Type, v)
+	}
+	buf := new(bytes.Buffer)
+	for _, field := range def.Fields {
+		if err := encodeField(buf, field.DataType, rv.FieldByIndex(field.index)); err != nil {
+			return nil, fmt.Errorf("%s: %w", field.Name, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (def *DataDefinition) structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Type() != def.Type {
+		return reflect.Value{}, fmt.Errorf("expected *%s, got %T", def.Type, v)
+	}
+	return rv.Elem(), nil
+}
+
+func decodeField(data []byte, dataType DWord, fv reflect.Value) error {
+	switch dataType {
+	case DataTypeInt32:
+		i := int64(int32(binary.LittleEndian.Uint32(data)))
+		return setNumber(fv, float64(i), i)
+	case DataTypeInt64:
+		i := int64(binary.LittleEndian.Uint64(data))
+		return setNumber(fv, float64(i), i)
+	case DataTypeFloat32:
+		f := float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+		return setNumber(fv, f, int64(f))
+	case DataTypeFloat64:
+		f := math.Float64frombits(binary.LittleEndian.Uint64(data))
+		return setNumber(fv, f, int64(f))
+	case DataTypeString8, DataTypeString32, DataTypeString64, DataTypeString128, DataTypeString256, DataTypeString260:
+		if fv.Kind() == reflect.String {
+			fv.SetString(CString(data))
+			return nil
+		}
+		reflect.Copy(fv, reflect.ValueOf(data))
+		return nil
+	}
+	return binary.Read(bytes.NewReader(data), binary.LittleEndian, fv.Addr().Interface())
+}
+
+func setNumber(fv reflect.Value, f float64, i int64) error {
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		fv.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(uint64(i))
+	case reflect.Bool:
+		fv.SetBool(i != 0)
+	default:
+		return fmt.Errorf("can't store a number in %s", fv.Type())
+	}
+	return nil
+}
+
+// EncodeValue returns the packed representation of value as the given DataType*, e.g. an InitPosition as
+// DataTypeInitPosition or a string as DataTypeString64. It is the counterpart of DecodeValue.
+func EncodeValue(value interface{}, dataType DWord) ([]byte, error) {
+	fv := reflect.ValueOf(value)
+	if !fv.IsValid() || !canHold(fv.Type(), dataType) {
+		return nil, fmt.Errorf("can't encode %T as %s", value, DataTypeToString(dataType))
+	}
+	buf := new(bytes.Buffer)
+	if err := encodeField(buf, dataType, fv); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeField(buf *bytes.Buffer, dataType DWord, fv reflect.Value) error {
+	switch dataType {
+	case DataTypeInt32:
+		return binary.Write(buf, binary.LittleEndian, int32(numberAsInt(fv)))
+	case DataTypeInt64:
+		return binary.Write(buf, binary.LittleEndian, numberAsInt(fv))
+	case DataTypeFloat32:
+		return binary.Write(buf, binary.LittleEndian, float32(numberAsFloat(fv)))
+	case DataTypeFloat64:
+		return binary.Write(buf, binary.LittleEndian, numberAsFloat(fv))
+	case DataTypeString8, DataTypeString32, DataTypeString64, DataTypeString128, DataTypeString256, DataTypeString260:
+		field := make([]byte, DataTypeSize(dataType))
+		if fv.Kind() == reflect.String {
+			// keep the terminating zero
+			copy(field[:len(field)-1], fv.String())
+		} else {
+			reflect.Copy(reflect.ValueOf(field), fv)
+		}
+		buf.Write(field)
+		return nil
+	case DataTypeStringV:
+		buf.Write(InsertString(nil, fv.String()))
+		return nil
+	}
+	return binary.Write(buf, binary.LittleEndian, fv.Interface())
+}
+
+func numberAsInt(fv reflect.Value) int64 {
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return int64(fv.Float())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(fv.Uint())
+	case reflect.Bool:
+		if fv.Bool() {
+			return 1
+		}
+		return 0
+	}
+	return fv.Int()
+}
+
+func numberAsFloat(fv reflect.Value) float64 {
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return fv.Float()
+	}
+	return float64(numberAsInt(fv))
+}
+
+// RegisterDataDefinition parses the simvar tags of v (see NewDataDefinition) and adds all fields to a new data definition.
+func (simco *SimConnect) RegisterDataDefinition(v interface{}) (*DataDefinition, error) {
+	def, err := NewDataDefinition(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range def.Fields {
+		if err := simco.AddToDataDefinitionWithDatumID(def.DefineID, field.Name, field.Unit, field.DataType, 0, field.DatumID); err != nil {
+			simco.ClearDataDefinition(def.DefineID)
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+// SetDataDefinitionOnSimObject encodes v and sends it to the given object, e.g. ObjectIDUser.
+func (simco *SimConnect) SetDataDefinitionOnSimObject(def *DataDefinition, objectID DWord, v interface{}) error {
+	data, err := def.Encode(v)
+	if err != nil {
+		return err
+	}
+	return simco.SetDataOnSimObject(def.DefineID, objectID, 0, 0, DWord(len(data)), unsafe.Pointer(&data[0]))
+}
+
+// SetInitPosition places the object at the given position, e.g. to teleport the user aircraft (ObjectIDUser).
+// The data definition is registered on first use and reused until the connection is opened again.
+func (simco *SimConnect) SetInitPosition(objectID DWord, position InitPosition) error {
+	data, err := EncodeValue(position, DataTypeInitPosition)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("initial position encoded to no data")
+	}
+	defineID, err := simco.sessionDefineID("Initial Position", "NULL", DataTypeInitPosition)
+	if err != nil {
+		return err
+	}
+	return simco.SetDataOnSimObject(defineID, objectID, 0, 0, DWord(len(data)), unsafe.Pointer(&data[0]))
+}
+
+// SetWaypoints replaces the waypoint list of an AI object.
+// The data definition is registered on first use and reused until the connection is opened again.
+func (simco *SimConnect) SetWaypoints(objectID DWord, waypoints []Waypoint) error {
+	if len(waypoints) == 0 {
+		return fmt.Errorf("no waypoints")
+	}
+	buf := new(bytes.Buffer)
+	for _, waypoint := range waypoints {
+		if err := binary.Write(buf, binary.LittleEndian, waypoint); err != nil {
+			return err
+		}
+	}
+	data := buf.Bytes()
+	if len(data) == 0 {
+		return fmt.Errorf("waypoints encoded to no data")
+	}
+	defineID, err := simco.sessionDefineID("AI WAYPOINT LIST", "number", DataTypeWaypoint)
+	if err != nil {
+		return err
+	}
+	unitSize := DWord(DataTypeSize(DataTypeWaypoint))
+	return simco.SetDataOnSimObject(defineID, objectID, 0, DWord(len(waypoints)), unitSize, unsafe.Pointer(&data[0]))
+}
+
+// sessionDefineID returns the data definition holding just the simvar, registering it with the current connection if needed.
+func (simco *SimConnect) sessionDefineID(name, unit string, dataType DWord) (DWord, error) {
+	s := simco.session
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if defineID, exists := s.defines[name]; exists {
+		return defineID, nil
+	}
+	defineID := NewDefineID()
+	if err := simco.AddToDataDefinition(defineID, name, unit, dataType); err != nil {
+		simco.ClearDataDefinition(defineID)
+		return 0, err
+	}
+	s.defines[name] = defineID
+	return defineID, nil
+}
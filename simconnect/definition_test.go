@@ -0,0 +1,444 @@
+package simconnect
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testDefinition struct {
+	Altitude float64   `simvar:"PLANE ALTITUDE,feet"`
+	Heading  float32   `simvar:"PLANE HEADING DEGREES TRUE,degrees"`
+	Gear     bool      `simvar:"GEAR HANDLE POSITION,bool"`
+	Engines  int64     `simvar:"NUMBER OF ENGINES,number"`
+	Flaps    uint8     `simvar:"FLAPS HANDLE INDEX,number,int32"`
+	Title    string    `simvar:"TITLE,,string64"`
+	ATCID    [32]byte  `simvar:"ATC ID"`
+	Position LatLogAlt `simvar:"STRUCT LATLONALT"`
+	Model    string    `simvar:"ATC MODEL,,stringv"`
+	Skipped  float64   `simvar:"-"`
+	Untagged float64
+}
+
+func TestNewDataDefinition(t *testing.T) {
+	def, err := NewDataDefinition(&testDefinition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DefinitionField{
+		{Name: "PLANE ALTITUDE", Unit: "feet", DataType: DataTypeFloat64},
+		{Name: "PLANE HEADING DEGREES TRUE", Unit: "degrees", DataType: DataTypeFloat32},
+		{Name: "GEAR HANDLE POSITION", Unit: "bool", DataType: DataTypeInt32},
+		{Name: "NUMBER OF ENGINES", Unit: "number", DataType: DataTypeInt64},
+		{Name: "FLAPS HANDLE INDEX", Unit: "number", DataType: DataTypeInt32},
+		{Name: "TITLE", DataType: DataTypeString64},
+		{Name: "ATC ID", DataType: DataTypeString32},
+		{Name: "STRUCT LATLONALT", DataType: DataTypeLatLonAlt},
+		{Name: "ATC MODEL", DataType: DataTypeStringV},
+	}
+	if len(def.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(def.Fields), len(want))
+	}
+	for i, field := range def.Fields {
+		if field.Name != want[i].Name || field.Unit != want[i].Unit || field.DataType != want[i].DataType || field.DatumID != DWord(i) {
+			t.Errorf("field %d = %+v, want %+v with datum ID %d", i, field, want[i], i)
+		}
+	}
+	if def.Size() != -1 {
+		t.Errorf("Size = %d, want -1 for a definition with a STRINGV", def.Size())
+	}
+}
+
+func TestNewDataDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{42, "needs a struct"},
+		{nil, "needs a struct"},
+		{struct{ X float64 }{}, "has no simvar fields"},
+		{struct {
+			x float64 `simvar:"PLANE ALTITUDE,feet"`
+		}{}, "not exported"},
+		{struct {
+			X float64 `simvar:"PLANE ALTITUDE,feet,float64,extra"`
+		}{}, "malformed simvar tag"},
+		{struct {
+			X float64 `simvar:",feet"`
+		}{}, "has no name"},
+		{struct {
+			X float64 `simvar:"PLANE ALTITUDE,feet,float128"`
+		}{}, `unknown datatype "float128"`},
+		{struct {
+			X []float64 `simvar:"PLANE ALTITUDE,feet"`
+		}{}, "can't derive datatype"},
+		{struct {
+			X [10]byte `simvar:"TITLE"`
+		}{}, "can't derive datatype"},
+		{struct {
+			X string `simvar:"PLANE ALTITUDE,feet,float64"`
+		}{}, "string can't hold a float64"},
+		{struct {
+			X float64 `simvar:"TITLE,,string256"`
+		}{}, "float64 can't hold a string256"},
+		{struct {
+			X [8]byte `simvar:"TITLE,,stringv"`
+		}{}, "can't hold a stringv"},
+		{struct {
+			X InitPosition `simvar:"STRUCT LATLONALT,,latlonalt"`
+		}{}, "can't hold a latlonalt"},
+	}
+	for _, test := range tests {
+		_, err := NewDataDefinition(test.v)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("NewDataDefinition(%T) = %v, want an error containing %q", test.v, err, test.want)
+		}
+	}
+}
+
+func TestDataTypeOfAndCanHold(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		dataType DWord
+	}{
+		{float64(0), DataTypeFloat64},
+		{float32(0), DataTypeFloat32},
+		{int(0), DataTypeInt64},
+		{uint64(0), DataTypeInt64},
+		{int32(0), DataTypeInt32},
+		{uint16(0), DataTypeInt32},
+		{false, DataTypeInt32},
+		{"", DataTypeString256},
+		{[8]byte{}, DataTypeString8},
+		{[260]byte{}, DataTypeString260},
+		{InitPosition{}, DataTypeInitPosition},
+		{MarkerState{}, DataTypeMarkerState},
+		{Waypoint{}, DataTypeWaypoint},
+		{LatLogAlt{}, DataTypeLatLonAlt},
+		{XYZ{}, DataTypeXYZ},
+		{[3]float64{}, DataTypeInvalid},
+		{map[string]int{}, DataTypeInvalid},
+	}
+	for _, test := range tests {
+		typ := reflect.TypeOf(test.value)
+		if got := dataTypeOf(typ); got != test.dataType {
+			t.Errorf("dataTypeOf(%s) = %s, want %s", typ, DataTypeToString(got), DataTypeToString(test.dataType))
+		}
+		if test.dataType != DataTypeInvalid && !canHold(typ, test.dataType) {
+			t.Errorf("canHold(%s, %s) = false", typ, DataTypeToString(test.dataType))
+		}
+	}
+
+	holds := []struct {
+		value    interface{}
+		dataType DWord
+		want     bool
+	}{
+		{float64(0), DataTypeInt32, true},
+		{true, DataTypeFloat64, true},
+		{"", DataTypeString8, true},
+		{"", DataTypeStringV, true},
+		{[64]byte{}, DataTypeString256, true},
+		{[64]byte{}, DataTypeStringV, false},
+		{"", DataTypeFloat64, false},
+		{XYZ{}, DataTypeLatLonAlt, true}, // same size, both three doubles
+		{InitPosition{}, DataTypeXYZ, false},
+		{float64(0), DataTypeInvalid, false},
+	}
+	for _, test := range holds {
+		typ := reflect.TypeOf(test.value)
+		if got := canHold(typ, test.dataType); got != test.want {
+			t.Errorf("canHold(%s, %s) = %v, want %v", typ, DataTypeToString(test.dataType), got, test.want)
+		}
+	}
+}
+
+func TestDataDefinitionRoundTrip(t *testing.T) {
+	def, err := NewDataDefinition(testDefinition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := testDefinition{
+		Altitude: 4500.5,
+		Heading:  270.25,
+		Gear:     true,
+		Engines:  2,
+		Flaps:    3,
+		Title:    "Cessna Skyhawk",
+		Position: LatLogAlt{Latitude: 47.4, Longitude: -122.3, Altitude: 400},
+		Model:    "C172",
+		Skipped:  1,
+		Untagged: 2,
+	}
+	copy(in.ATCID[:], "N172SP")
+	data, err := def.Encode(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 8 + 4 + 4 + 8 + 4 + 64 + 32 + 24 and "C172" with its zero padded to 8
+	if len(data) != 148+8 {
+		t.Fatalf("encoded %d bytes, want 156", len(data))
+	}
+
+	var out testDefinition
+	if err := def.Decode(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Skipped, in.Untagged = 0, 0
+	if out != in {
+		t.Errorf("decoded %+v\nwant    %+v", out, in)
+	}
+
+	if err := def.Decode(data[:100], &out); !errors.Is(err, ErrShortBuffer) {
+		t.Errorf("short data: err = %v, want ErrShortBuffer", err)
+	}
+	if err := def.Decode(data, out); err == nil {
+		t.Error("Decode into a non-pointer succeeded")
+	}
+	if _, err := def.Encode(struct{ X float64 }{}); err == nil {
+		t.Error("Encode of another type succeeded")
+	}
+}
+
+func TestDataDefinitionLongString(t *testing.T) {
+	type title struct {
+		Title string `simvar:"TITLE,,string8"`
+	}
+	def, err := NewDataDefinition(title{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := def.Encode(title{"Cessna Skyhawk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 8 || data[7] != 0 {
+		t.Fatalf("encoded % x, want 8 bytes with a terminating zero", data)
+	}
+	var out title
+	if err := def.Decode(data, &out); err != nil || out.Title != "Cessna " {
+		t.Errorf("decoded %q, %v", out.Title, err)
+	}
+}
+
+// simObjectDataMessage builds a RecvIDSimobjectData message for the define ID followed by data.
+func simObjectDataMessage(t *testing.T, defineID, flags, count DWord, data []byte) *Message {
+	t.Helper()
+	header, err := Marshal(&RecvSimObjectData{
+		Recv:        Recv{ID: RecvIDSimobjectData},
+		DefineID:    defineID,
+		Flags:       flags,
+		DefineCount: count,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet := golden(append(header, data...))
+	msg, err := NewMessage(packet.dword(0, uint32(len(packet))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestDataDefinitionDecodeMessage(t *testing.T) {
+	type engine struct {
+		RPM  float64 `simvar:"GENERAL ENG RPM:1,rpm"`
+		Fuel int32   `simvar:"GENERAL ENG FUEL VALVE:1,bool"`
+	}
+	def, err := NewDataDefinition(engine{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := def.Encode(engine{RPM: 2400, Fuel: 1})
+
+	var out engine
+	if err := def.DecodeMessage(simObjectDataMessage(t, def.DefineID, 0, 2, data), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.RPM != 2400 || out.Fuel != 1 {
+		t.Errorf("decoded %+v", out)
+	}
+
+	// only the fuel valve changed
+	tagged := newGolden(8).dword(0, 1).dword(4, 0)
+	out = engine{RPM: 1, Fuel: 1}
+	if err := def.DecodeMessage(simObjectDataMessage(t, def.DefineID, DataRequestFlagTagged, 1, tagged), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.RPM != 1 || out.Fuel != 0 {
+		t.Errorf("decoded tagged %+v", out)
+	}
+
+	if err := def.DecodeMessage(simObjectDataMessage(t, def.DefineID+1, 0, 2, data), &out); err == nil {
+		t.Error("decoded a message for another define ID")
+	}
+	event, _ := NewMessage(eventBytes(24, 24))
+	if err := def.DecodeMessage(event, &out); err == nil {
+		t.Error("decoded an event")
+	}
+}
+
+func TestEncodeValue(t *testing.T) {
+	data, err := EncodeValue(XYZ{X: 1, Y: 2, Z: 3}, DataTypeXYZ)
+	if err != nil || len(data) != 24 {
+		t.Fatalf("EncodeValue = % x, %v", data, err)
+	}
+	value, err := DecodeValue(data, DataTypeXYZ)
+	if err != nil || value != (XYZ{X: 1, Y: 2, Z: 3}) {
+		t.Errorf("DecodeValue = %v, %v", value, err)
+	}
+	for _, test := range []struct {
+		value    interface{}
+		dataType DWord
+	}{
+		{nil, DataTypeFloat64},
+		{"abc", DataTypeInt32},
+		{XYZ{}, DataTypeInitPosition},
+	} {
+		if _, err := EncodeValue(test.value, test.dataType); err == nil {
+			t.Errorf("EncodeValue(%#v, %s) succeeded", test.value, DataTypeToString(test.dataType))
+		}
+	}
+}
+
+func TestRegisterDataDefinition(t *testing.T) {
+	backend := NewScriptedBackend()
+	simco := NewSimConnectWithBackend(backend)
+	def, err := simco.RegisterDataDefinition(&testDefinition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adds := backend.CallsNamed(scAddToDataDefinition)
+	if len(adds) != len(def.Fields) {
+		t.Fatalf("%d AddToDataDefinition calls, want %d", len(adds), len(def.Fields))
+	}
+	for i, call := range adds {
+		if call.Args[0] != def.DefineID || call.Args[1] != def.Fields[i].Name || call.Args[5] != DWord(i) {
+			t.Errorf("call %d = %v", i, call.Args)
+		}
+	}
+
+	backend.SetError(scAddToDataDefinition, errors.New("failed"))
+	if _, err := simco.RegisterDataDefinition(&testDefinition{}); err == nil {
+		t.Fatal("RegisterDataDefinition succeeded")
+	}
+	if clears := backend.CallsNamed(scClearDataDefinition); len(clears) != 1 {
+		t.Errorf("%d ClearDataDefinition calls after a failure, want 1", len(clears))
+	}
+}
+
+func TestSetInitPosition(t *testing.T) {
+	backend := NewScriptedBackend()
+	simco := NewSimConnectWithBackend(backend)
+	position := InitPosition{Latitude: 47.4, Longitude: -122.3, Altitude: 500, Heading: 270, OnGround: 1, Airspeed: 0}
+	if err := simco.SetInitPosition(ObjectIDUser, position); err != nil {
+		t.Fatal(err)
+	}
+	calls := backend.Calls()
+	if len(calls) != 2 || calls[0].Name != scAddToDataDefinition || calls[1].Name != scSetDataOnSimObject {
+		t.Fatalf("calls = %v", calls)
+	}
+	defineID := calls[0].Args[0]
+	if calls[0].Args[1] != "Initial Position" || calls[0].Args[2] != "NULL" || calls[0].Args[3] != DWord(DataTypeInitPosition) {
+		t.Errorf("AddToDataDefinition(%v)", calls[0].Args)
+	}
+	args := calls[1].Args
+	if args[0] != defineID || args[1] != DWord(ObjectIDUser) || args[3] != DWord(0) || args[4] != DWord(56) {
+		t.Errorf("SetDataOnSimObject(%v)", args[:5])
+	}
+	want := newGolden(56).float64(0, 47.4).float64(8, -122.3).float64(16, 500).float64(40, 270).dword(48, 1)
+	if data := args[5].([]byte); string(data) != string(want) {
+		t.Errorf("payload % x\nwant % x", data, want)
+	}
+
+	// the definition is reused until the connection is opened again
+	backend.Reset()
+	if err := simco.SetInitPosition(ObjectIDUser, position); err != nil {
+		t.Fatal(err)
+	}
+	if calls := backend.Calls(); len(calls) != 1 || calls[0].Name != scSetDataOnSimObject || calls[0].Args[0] != defineID {
+		t.Fatalf("calls of the second SetInitPosition = %v", calls)
+	}
+	if err := simco.Open("again"); err != nil {
+		t.Fatal(err)
+	}
+	backend.Reset()
+	if err := simco.SetInitPosition(ObjectIDUser, position); err != nil {
+		t.Fatal(err)
+	}
+	if adds := backend.CallsNamed(scAddToDataDefinition); len(adds) != 1 || adds[0].Args[0] == defineID {
+		t.Errorf("AddToDataDefinition calls after Open = %v", adds)
+	}
+}
+
+func TestSetInitPositionFailure(t *testing.T) {
+	backend := NewScriptedBackend()
+	simco := NewSimConnectWithBackend(backend)
+	backend.SetError(scAddToDataDefinition, errors.New("failed"))
+	if err := simco.SetInitPosition(ObjectIDUser, InitPosition{}); err == nil {
+		t.Error("SetInitPosition succeeded")
+	}
+	if sets := backend.CallsNamed(scSetDataOnSimObject); len(sets) != 0 {
+		t.Errorf("%d SetDataOnSimObject calls after a failure", len(sets))
+	}
+	if clears := backend.CallsNamed(scClearDataDefinition); len(clears) != 1 {
+		t.Errorf("%d ClearDataDefinition calls after a failure, want 1", len(clears))
+	}
+
+	backend.SetError(scAddToDataDefinition, nil)
+	if err := simco.SetInitPosition(ObjectIDUser, InitPosition{}); err != nil {
+		t.Fatal(err)
+	}
+	if adds := backend.CallsNamed(scAddToDataDefinition); len(adds) != 2 {
+		t.Errorf("a failed definition was kept, %d AddToDataDefinition calls", len(adds))
+	}
+}
+
+func TestSetWaypoints(t *testing.T) {
+	backend := NewScriptedBackend()
+	simco := NewSimConnectWithBackend(backend)
+	waypoints := []Waypoint{
+		{Latitude: 47.4, Longitude: -122.3, Altitude: 3000, Flags: 0x4, KtsSpeed: 120},
+		{Latitude: 47.5, Longitude: -122.2, Altitude: 2500, KtsSpeed: 100, PercentThrottle: 60},
+	}
+	if err := simco.SetWaypoints(42, waypoints); err != nil {
+		t.Fatal(err)
+	}
+	calls := backend.Calls()
+	if len(calls) != 2 || calls[0].Name != scAddToDataDefinition || calls[1].Name != scSetDataOnSimObject {
+		t.Fatalf("calls = %v", calls)
+	}
+	defineID := calls[0].Args[0]
+	if calls[0].Args[1] != "AI WAYPOINT LIST" || calls[0].Args[3] != DWord(DataTypeWaypoint) {
+		t.Errorf("AddToDataDefinition(%v)", calls[0].Args)
+	}
+	args := calls[1].Args
+	if args[0] != defineID || args[1] != DWord(42) || args[3] != DWord(2) || args[4] != DWord(44) {
+		t.Errorf("SetDataOnSimObject(%v)", args[:5])
+	}
+	want := append(
+		newGolden(44).float64(0, 47.4).float64(8, -122.3).float64(16, 3000).dword(24, 0x4).float64(28, 120),
+		newGolden(44).float64(0, 47.5).float64(8, -122.2).float64(16, 2500).float64(28, 100).float64(36, 60)...)
+	if data := args[5].([]byte); string(data) != string(want) {
+		t.Errorf("payload % x\nwant % x", data, want)
+	}
+
+	backend.Reset()
+	if err := simco.SetWaypoints(7, waypoints[:1]); err != nil {
+		t.Fatal(err)
+	}
+	calls = backend.Calls()
+	if len(calls) != 1 || calls[0].Args[0] != defineID || calls[0].Args[1] != DWord(7) || calls[0].Args[3] != DWord(1) {
+		t.Fatalf("calls of the second SetWaypoints = %v", calls)
+	}
+
+	backend.Reset()
+	if err := simco.SetWaypoints(42, nil); err == nil {
+		t.Error("SetWaypoints without waypoints succeeded")
+	}
+	if calls := backend.Calls(); len(calls) != 0 {
+		t.Errorf("calls without waypoints = %v", calls)
+	}
+}
@@ -287,13 +287,13 @@ const (
 	TextResultTimeout                         // SIMCONNECT_TEXT_RESULT_TIMEOUT
 )
 
-// // SIMCONNECT_WEATHER_MODE
-// const (
-// 	WeatherModeTheme  DWord = iota // SIMCONNECT_WEATHER_MODE_THEME
-// 	WeatherModeRWW                 // SIMCONNECT_WEATHER_MODE_RWW
-// 	WeatherModeCustom              // SIMCONNECT_WEATHER_MODE_CUSTOM
-// 	WeatherModeGlobal              // SIMCONNECT_WEATHER_MODE_GLOBAL
-// )
+// SIMCONNECT_WEATHER_MODE
+const (
+	WeatherModeTheme  DWord = iota // SIMCONNECT_WEATHER_MODE_THEME
+	WeatherModeRWW                 // SIMCONNECT_WEATHER_MODE_RWW
+	WeatherModeCustom              // SIMCONNECT_WEATHER_MODE_CUSTOM
+	WeatherModeGlobal              // SIMCONNECT_WEATHER_MODE_GLOBAL
+)
 
 // SIMCONNECT_FACILITY_LIST_TYPE
 // https://docs.flightsimulator.com/html/Programming_Tools/SimConnect/API_Reference/Structures_And_Enumerations/SIMCONNECT_FACILITY_LIST_TYPE.htm
@@ -466,56 +466,57 @@ type RecvEventFrame struct {
 }
 
 // SIMCONNECT_RECV_EVENT_MULTIPLAYER_SERVER_STARTED: when dwID == SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED
-// type RecvEventMultiplayerServerStarted struct {
-// 	RecvEvent
-// 	// No event specific data, for now
-// }
+type RecvEventMultiplayerServerStarted struct {
+	RecvEvent
+	// No event specific data, for now
+}
 
 // SIMCONNECT_RECV_EVENT_MULTIPLAYER_CLIENT_STARTED: when dwID == SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED
-// type RecvEventMultiplayerClientStarted struct {
-// 	RecvEvent
-// 	// No event specific data, for now
-// }
+type RecvEventMultiplayerClientStarted struct {
+	RecvEvent
+	// No event specific data, for now
+}
 
 // SIMCONNECT_RECV_EVENT_MULTIPLAYER_SESSION_ENDED: when dwID == SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED
-// type RecvEventMultiplayerSessionEnded struct {
-// 	RecvEvent
-// 	// No event specific data, for now
-// }
+type RecvEventMultiplayerSessionEnded struct {
+	RecvEvent
+	// No event specific data, for now
+}
 
 // GUID structure from guiddef.h
-// typedef struct _GUID {
-// 	unsigned long  Data1;
-// 	unsigned short Data2;
-// 	unsigned short Data3;
-// 	unsigned char  Data4[8];
-// } GUID;
+type GUID struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
 
 // SIMCONNECT_DATA_RACE_RESULT
-// type DataRaceResult struct {
-// 	NumberOfRacers DWord        // The total number of racers
-// 	MissionGUID    windows.GUID // The name of the mission to execute, NULL if no mission
-// 	PlayerName     [260]byte    // The name of the player
-// 	SessionType    [260]byte    // The type of the multiplayer session: "LAN", "GAMESPY")
-// 	Aircraft       [260]byte    // The aircraft type
-// 	PlayerRole     [260]byte    // The player role in the mission
-// 	TotalTime      float64      // Total time in seconds, 0 means DNF
-// 	PenaltyTime    float64      // Total penalty time in seconds
-// 	IsDisqualified DWord        // non 0 - disqualified, 0 - not disqualified
-// }
+type DataRaceResult struct {
+	NumberOfRacers DWord     // The total number of racers
+	MissionGUID    GUID      // The name of the mission to execute, NULL if no mission
+	PlayerName     [260]byte // The name of the player
+	SessionType    [260]byte // The type of the multiplayer session: "LAN", "GAMESPY")
+	Aircraft       [260]byte // The aircraft type
+	PlayerRole     [260]byte // The player role in the mission
+	TotalTime      float64   // Total time in seconds, 0 means DNF
+	PenaltyTime    float64   // Total penalty time in seconds
+	IsDisqualified DWord     // non 0 - disqualified, 0 - not disqualified
+}
 
 // SIMCONNECT_RECV_EVENT_RACE_END: when dwID == SIMCONNECT_RECV_ID_EVENT_RACE_END
-// type RecvEventRaceEnd struct {
-// 	RecvEvent
-// 	RacerNumber DWord // The index of the racer the results are for
-// 	RacerData   DataRaceResult
-// }
+type RecvEventRaceEnd struct {
+	RecvEvent
+	RacerNumber DWord // The index of the racer the results are for
+	RacerData   DataRaceResult
+}
 
 // SIMCONNECT_RECV_EVENT_RACE_LAP: when dwID == SIMCONNECT_RECV_ID_EVENT_RACE_LAP
-// type RecvEventRaceLap struct {
-// 	LapIndex  DWord // The index of the lap the results are for
-// 	RacerData DataRaceResult
-// }
+type RecvEventRaceLap struct {
+	RecvEvent
+	LapIndex  DWord // The index of the lap the results are for
+	RacerData DataRaceResult
+}
 
 // SIMCONNECT_RECV_SIMOBJECT_DATA: when dwID == SIMCONNECT_RECV_ID_SIMOBJECT_DATA
 // Will be received by the client after a successful call to SimConnect_RequestDataOnSimObject or SimConnect_RequestDataOnSimObjectType.
@@ -547,24 +548,24 @@ type RecvClientData struct {
 }
 
 // SIMCONNECT_RECV_WEATHER_OBSERVATION: when dwID == SIMCONNECT_RECV_ID_WEATHER_OBSERVATION
-// type RecvWeatherObservation struct {
-// 	Recv
-// 	requestID DWord
-// 	metar     [1]byte // SIMCONNECT_STRINGV(szMetar): Variable length string whose maximum size is MAX_METAR_LENGTH
-// }
+type RecvWeatherObservation struct {
+	Recv
+	RequestID DWord
+	// SIMCONNECT_STRINGV(szMetar): Variable length string whose maximum size is MAX_METAR_LENGTH
+}
 
-// const (
-// 	CloudStateArrayWidth int = 64                                          // SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH
-// 	CloudStateArraySize  int = CloudStateArrayWidth * CloudStateArrayWidth // SIMCONNECT_CLOUD_STATE_ARRAY_SIZE
-// )
+const (
+	CloudStateArrayWidth int = 64                                          // SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH
+	CloudStateArraySize  int = CloudStateArrayWidth * CloudStateArrayWidth // SIMCONNECT_CLOUD_STATE_ARRAY_SIZE
+)
 
 // SIMCONNECT_RECV_CLOUD_STATE: when dwID == SIMCONNECT_RECV_ID_CLOUD_STATE
-// type RecvCloudState struct {
-// 	Recv
-// 	RequestID DWord
-// 	ArraySize DWord
-// 	// SIMCONNECT_FIXEDTYPE_DATAV(BYTE, rgbData, dwArraySize, U1 /*member of UnmanagedType enum*/ , System::Byte /*cli type*/);
-// }
+type RecvCloudState struct {
+	Recv
+	RequestID DWord
+	ArraySize DWord
+	// SIMCONNECT_FIXEDTYPE_DATAV(BYTE, rgbData, dwArraySize, U1 /*member of UnmanagedType enum*/ , System::Byte /*cli type*/);
+}
 
 // SIMCONNECT_RECV_ASSIGNED_OBJECT_ID: when dwID == SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID
 // Used to return an object ID that matches a request ID.
@@ -596,17 +597,18 @@ type RecvSystemState struct {
 }
 
 // SIMCONNECT_RECV_CUSTOM_ACTION : public SIMCONNECT_RECV_EVENT
-// type RecvCustomAction struct {
-// 	RecvEvent
-// 	InstanceID        windows.GUID // Instance id of the action that executed
-// 	WaitForCompletion DWord        // Wait for completion flag on the action
-// 	Payload           [1]byte      // SIMCONNECT_STRINGV(szPayLoad): Variable length string payload associated with the mission action
-// }
+type RecvCustomAction struct {
+	RecvEvent
+	InstanceID        GUID  // Instance id of the action that executed
+	WaitForCompletion DWord // Wait for completion flag on the action
+	// SIMCONNECT_STRINGV(szPayLoad): Variable length string payload associated with the mission action
+}
 
 // SIMCONNECT_RECV_EVENT_WEATHER_MODE : public SIMCONNECT_RECV_EVENT
-// type RecvEventWeatherMode struct {
-// 	// No event specific data - the new weather mode is in the base structure dwData member
-// }
+type RecvEventWeatherMode struct {
+	RecvEvent
+	// No event specific data - the new weather mode is in the base structure dwData member
+}
 
 // SIMCONNECT_RECV_FACILITIES_LIST
 // Used to provide information on the number of elements in a list of facilities returned to the client, and the number of packets that were used to transmit the data.
@@ -703,20 +705,24 @@ type InitPosition struct {
 }
 
 // SIMCONNECT_DATATYPE_MARKERSTATE
-// type MarkerState struct {
-// 	MarkerName  [64]byte
-// 	MarkerState DWord
-// }
+// Used to help graphically link flight model data with the graphics model.
+// https://docs.flightsimulator.com/html/Programming_Tools/SimConnect/API_Reference/Structures_And_Enumerations/SIMCONNECT_DATA_MARKERSTATE.htm
+type MarkerState struct {
+	MarkerName  [64]byte
+	MarkerState DWord // 1=on, 0=off
+}
 
 // SIMCONNECT_DATATYPE_WAYPOINT
-// type Waypoint struct {
-// 	Latitude        float64 // degrees
-// 	Longitude       float64 // degrees
-// 	Altitude        float64 // feet
-// 	Flags           uint32
-// 	ktsSpeed        float64 // knots
-// 	percentThrottle float64
-// }
+// Used to hold all the necessary information on a waypoint.
+// https://docs.flightsimulator.com/html/Programming_Tools/SimConnect/API_Reference/Structures_And_Enumerations/SIMCONNECT_DATA_WAYPOINT.htm
+type Waypoint struct {
+	Latitude        float64 // degrees
+	Longitude       float64 // degrees
+	Altitude        float64 // feet
+	Flags           DWord   // SIMCONNECT_WAYPOINT_FLAGS
+	KtsSpeed        float64 // knots
+	PercentThrottle float64
+}
 
 // SIMCONNECT_DATA_LATLONALT
 // Used to hold a world position.
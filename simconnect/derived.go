@@ -0,0 +1,140 @@
+package simconnect
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect/expr"
+)
+
+// derivedVar is a simvar computed from other simvars, see SimMate.AddDerivedSimVar.
+type derivedVar struct {
+	simVar *SimVar
+	expr   *expr.Expr
+	inputs map[string]DWord // define IDs of the variables of the expression
+}
+
+// AddDerivedSimVar adds a simvar whose value is computed from other simvars with an expression, e.g.
+//
+//	mate.AddDerivedSimVar("FUEL ENDURANCE", "hours", "[FUEL TOTAL QUANTITY] / max([ENG FUEL FLOW GPH:1], 0.1)")
+//
+// See package expr for the syntax. The variables of the expression are the names of simvars added before,
+// including other derived simvars; their values are taken in the units they were added with.
+// The value is recomputed whenever an input is updated and is read like any other simvar, e.g. with SimVar or ValueIn.
+// Options like WithHistory apply, options which affect requests don't.
+func (mate *SimMate) AddDerivedSimVar(name, unit, expression string, opts ...SimVarOption) (DWord, error) {
+	e, err := expr.Parse(expression)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	if _, exists := mate.simVarManager.simVarWithName(name); exists {
+		return 0, fmt.Errorf("%s was already added", name)
+	}
+	derived := &derivedVar{expr: e, inputs: make(map[string]DWord)}
+	for _, input := range e.Vars() {
+		simVar, exists := mate.simVarManager.simVarWithName(input)
+		if !exists {
+			return 0, fmt.Errorf("%s: %w %q, add it first", name, expr.ErrUnknownVar, input)
+		}
+		derived.inputs[input] = simVar.DefineID
+	}
+
+	defineID := mate.simVarManager.Add(name, unit, DataTypeFloat64)
+	simVar, _ := mate.simVarManager.GetSimVar(defineID)
+	for _, opt := range opts {
+		opt(simVar)
+	}
+	simVar.Expression = expression
+	simVar.Period, simVar.Flags = PeriodNever, 0
+	if simVar.HistorySize > 0 {
+		mate.histories[defineID] = NewHistory(simVar.HistorySize)
+	}
+	derived.simVar = simVar
+	mate.derived = append(mate.derived, derived)
+	for _, inputID := range derived.inputs {
+		mate.dependents[inputID] = append(mate.dependents[inputID], derived)
+	}
+	mate.evalDerived(derived)
+	return defineID, nil
+}
+
+// removeDerived forgets the derived simvar, if it is one. The caller holds the mutex.
+func (mate *SimMate) removeDerived(defineID DWord) {
+	for i, derived := range mate.derived {
+		if derived.simVar.DefineID != defineID {
+			continue
+		}
+		mate.derived = append(mate.derived[:i:i], mate.derived[i+1:]...)
+		for _, inputID := range derived.inputs {
+			dependents := mate.dependents[inputID]
+			for j, dependent := range dependents {
+				if dependent == derived {
+					mate.dependents[inputID] = append(dependents[:j:j], dependents[j+1:]...)
+					break
+				}
+			}
+			if len(mate.dependents[inputID]) == 0 {
+				delete(mate.dependents, inputID)
+			}
+		}
+		return
+	}
+}
+
+// updateDerived recomputes the derived simvars which depend on the updated simvars, directly or through
+// other derived simvars. It returns the derived simvars which got a new value and their values.
+// The caller holds the mutex.
+func (mate *SimMate) updateDerived(updated []*SimVar) ([]*SimVar, []interface{}) {
+	affected := make(map[*derivedVar]bool)
+	for _, simVar := range updated {
+		for _, dependent := range mate.dependents[simVar.DefineID] {
+			affected[dependent] = true
+		}
+	}
+	if len(affected) == 0 {
+		return nil, nil
+	}
+	derivedVars := make([]*SimVar, 0, len(affected))
+	values := make([]interface{}, 0, len(affected))
+	// derived simvars only depend on simvars added before them, so one pass in order of addition suffices
+	for _, derived := range mate.derived {
+		if !affected[derived] || !mate.evalDerived(derived) {
+			continue
+		}
+		derivedVars = append(derivedVars, derived.simVar)
+		values = append(values, derived.simVar.Value)
+		for _, dependent := range mate.dependents[derived.simVar.DefineID] {
+			affected[dependent] = true
+		}
+	}
+	return derivedVars, values
+}
+
+// evalDerived computes the value of a derived simvar. It returns false if an input has no numeric value yet.
+// The sample time is the latest one of the inputs. The caller holds the mutex.
+func (mate *SimMate) evalDerived(derived *derivedVar) bool {
+	var sampled time.Time
+	value, err := derived.expr.Eval(func(name string) (float64, bool) {
+		simVar, exists := mate.simVarManager.simVarWithID(derived.inputs[name])
+		if !exists || simVar.Value == nil {
+			return 0, false
+		}
+		if simVar.LastUpdate.After(sampled) {
+			sampled = simVar.LastUpdate
+		}
+		return ValueToNumber(simVar.Value)
+	})
+	if err != nil {
+		return false
+	}
+	simVar := derived.simVar
+	simVar.Value = value
+	simVar.UpdateCount++
+	simVar.LastUpdate = sampled
+	if history, exists := mate.histories[simVar.DefineID]; exists {
+		history.Add(value, sampled)
+	}
+	return true
+}
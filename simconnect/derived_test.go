@@ -0,0 +1,37 @@
+package simconnect
+
+import "testing"
+
+func TestRemoveInputOfDerivedSimVar(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	inputID := mate.AddSimVar("AIRSPEED INDICATED", "knots", DataTypeFloat64)
+	mphID, err := mate.AddDerivedSimVar("AIRSPEED MPH", "mph", "[AIRSPEED INDICATED] * 1.15078")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kmhID, err := mate.AddDerivedSimVar("AIRSPEED KMH", "kph", "[AIRSPEED MPH] * 1.609344")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mate.RemoveSimVar(inputID) {
+		t.Fatal("removed the input of a derived simvar")
+	}
+	if mate.RemoveSimVar(mphID) {
+		t.Fatal("removed a derived simvar which is the input of another one")
+	}
+	for _, id := range []DWord{inputID, mphID, kmhID} {
+		if _, exists := mate.SimVar(id); !exists {
+			t.Fatalf("simvar %d is gone", id)
+		}
+	}
+
+	for _, id := range []DWord{kmhID, mphID, inputID} {
+		if !mate.RemoveSimVar(id) {
+			t.Fatalf("simvar %d wasn't removed", id)
+		}
+	}
+	if len(mate.derived) != 0 || len(mate.dependents) != 0 {
+		t.Errorf("%d derived simvars and %d inputs left", len(mate.derived), len(mate.dependents))
+	}
+}
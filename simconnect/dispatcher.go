@@ -0,0 +1,180 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultMinIdleInterval = time.Millisecond
+	DefaultMaxIdleInterval = time.Millisecond * 50
+)
+
+// ErrQuit is returned by Dispatcher.Run when the simulator sent RecvIDQuit.
+var ErrQuit = errors.New("simulator quit")
+
+// MessageSource is where a Dispatcher gets its messages from, e.g. *SimConnect or *SimMate.
+// NextMessage returns nil if there is no message pending.
+type MessageSource interface {
+	NextMessage() (*Message, error)
+}
+
+// Clock is the time source of a Dispatcher. Tests may drive the dispatcher with their own clock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock returns the wall clock.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// HandlerFunc is called for every message with the value returned by Decode.
+type HandlerFunc func(msg *Message, value interface{})
+
+type periodicTask struct {
+	interval time.Duration
+	due      time.Time
+	fn       func()
+}
+
+// Dispatcher reads messages from a MessageSource and hands them to its handlers.
+// The source is polled: every wakeup drains all pending messages. While the source is idle, the dispatcher
+// backs off from MinIdleInterval up to MaxIdleInterval between polls, so a message arriving after an idle spell
+// waits up to MaxIdleInterval before it is dispatched. Once a message was dispatched, the dispatcher polls every
+// MinIdleInterval again. Handlers and periodic tasks are all called from the goroutine which runs the dispatcher.
+type Dispatcher struct {
+	MinIdleInterval time.Duration
+	MaxIdleInterval time.Duration
+	source          MessageSource
+	clock           Clock
+	handlers        []HandlerFunc
+	tasks           []*periodicTask
+}
+
+// NewDispatcher creates a Dispatcher reading from source. If clock is nil the wall clock is used.
+func NewDispatcher(source MessageSource, clock Clock) *Dispatcher {
+	if clock == nil {
+		clock = SystemClock()
+	}
+	return &Dispatcher{
+		MinIdleInterval: DefaultMinIdleInterval,
+		MaxIdleInterval: DefaultMaxIdleInterval,
+		source:          source,
+		clock:           clock,
+		handlers:        make([]HandlerFunc, 0),
+		tasks:           make([]*periodicTask, 0),
+	}
+}
+
+// Handle adds a handler which is called for every message.
+func (d *Dispatcher) Handle(handler HandlerFunc) {
+	d.handlers = append(d.handlers, handler)
+}
+
+// Listen adds a handler which invokes the matching callback of the listener.
+func (d *Dispatcher) Listen(listener *EventListener) {
+	d.Handle(func(msg *Message, value interface{}) {
+		notifyListener(value, listener)
+	})
+}
+
+// Every calls fn every interval, starting one interval after Run was called. The interval must be positive.
+func (d *Dispatcher) Every(interval time.Duration, fn func()) {
+	d.tasks = append(d.tasks, &periodicTask{interval: interval, fn: fn})
+}
+
+// Run dispatches messages until the context is done, the simulator quits or the source fails.
+// It returns ctx.Err(), ErrQuit or the error of the source, respectively.
+// Malformed messages are dropped. A task added with Every whose interval isn't positive fails Run right away,
+// so does a MinIdleInterval which isn't positive or a MaxIdleInterval below MinIdleInterval.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	if d.MinIdleInterval <= 0 {
+		return fmt.Errorf("min idle interval %s: interval must be positive", d.MinIdleInterval)
+	}
+	if d.MaxIdleInterval < d.MinIdleInterval {
+		return fmt.Errorf("max idle interval %s is below the min idle interval %s", d.MaxIdleInterval, d.MinIdleInterval)
+	}
+	now := d.clock.Now()
+	for _, task := range d.tasks {
+		if task.interval <= 0 {
+			return fmt.Errorf("periodic task every %s: interval must be positive", task.interval)
+		}
+		task.due = now.Add(task.interval)
+	}
+
+	idle := d.MinIdleInterval
+	for {
+		count, err := d.drain()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			idle = d.MinIdleInterval
+		} else if idle *= 2; idle > d.MaxIdleInterval {
+			idle = d.MaxIdleInterval
+		}
+
+		wait := idle
+		now := d.clock.Now()
+		for _, task := range d.tasks {
+			if !now.Before(task.due) {
+				task.fn()
+				task.due = task.due.Add(task.interval)
+				if task.due.Before(now) {
+					task.due = now.Add(task.interval)
+				}
+			}
+			if until := task.due.Sub(now); until < wait {
+				wait = until
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.clock.After(wait):
+		}
+	}
+}
+
+// drain dispatches all pending messages and returns how many there were.
+func (d *Dispatcher) drain() (int, error) {
+	count := 0
+	for {
+		msg, err := d.source.NextMessage()
+		if err != nil {
+			if errors.Is(err, ErrMalformedMessage) {
+				log.Tracef("Dropping message: %s", err.Error())
+				continue
+			}
+			return count, err
+		}
+		if msg == nil {
+			return count, nil
+		}
+		count++
+
+		value, err := Decode(msg)
+		if err != nil {
+			log.Tracef("Dropping message: %s", err.Error())
+			continue
+		}
+		for _, handler := range d.handlers {
+			handler(msg, value)
+		}
+		if msg.ID() == RecvIDQuit {
+			return count, ErrQuit
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock advances by the full duration whenever the dispatcher waits, so Run never sleeps.
+// onWait is called with the number of waits so far; returning false cancels the run.
+type fakeClock struct {
+	now    time.Time
+	waits  []time.Duration
+	onWait func(n int) bool
+	cancel context.CancelFunc
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	if c.onWait != nil && !c.onWait(len(c.waits)) {
+		c.cancel()
+		return nil // only ctx.Done can fire
+	}
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// newTestDispatcher returns a dispatcher reading from a ScriptedBackend, its clock and the context to run it with.
+func newTestDispatcher(t *testing.T) (*Dispatcher, *ScriptedBackend, *fakeClock, context.Context) {
+	t.Helper()
+	backend := NewScriptedBackend()
+	clock := newFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	clock.cancel = cancel
+	d := NewDispatcher(NewSimConnectWithBackend(backend), clock)
+	d.MinIdleInterval = time.Millisecond
+	d.MaxIdleInterval = 8 * time.Millisecond
+	return d, backend, clock, ctx
+}
+
+func pushEvent(t *testing.T, backend *ScriptedBackend, eventID DWord) {
+	t.Helper()
+	if err := backend.PushRecv(RecvEvent{Recv: Recv{ID: RecvIDEvent}, EventID: eventID}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatcherDrainsAllPendingMessages(t *testing.T) {
+	d, backend, clock, ctx := newTestDispatcher(t)
+	for i := DWord(1); i <= 3; i++ {
+		pushEvent(t, backend, i)
+	}
+	events := make([]DWord, 0)
+	d.Handle(func(msg *Message, value interface{}) {
+		events = append(events, value.(*RecvEvent).EventID)
+	})
+	handledBeforeWait := -1
+	clock.onWait = func(n int) bool {
+		if n == 1 {
+			handledBeforeWait = len(events)
+		}
+		return n < 2
+	}
+
+	if err := d.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	if handledBeforeWait != 3 {
+		t.Errorf("%d messages handled before the first wait, want 3", handledBeforeWait)
+	}
+	if len(events) != 3 || events[0] != 1 || events[1] != 2 || events[2] != 3 {
+		t.Errorf("events = %v, want [1 2 3]", events)
+	}
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d, backend, clock, ctx := newTestDispatcher(t)
+	clock.onWait = func(n int) bool {
+		if n == 4 {
+			pushEvent(t, backend, 1)
+		}
+		return n < 6
+	}
+
+	if err := d.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	ms := time.Millisecond
+	want := []time.Duration{2 * ms, 4 * ms, 8 * ms, 8 * ms, 1 * ms, 2 * ms}
+	if len(clock.waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", clock.waits, want)
+	}
+	for i := range want {
+		if clock.waits[i] != want[i] {
+			t.Fatalf("waits = %v, want %v", clock.waits, want)
+		}
+	}
+}
+
+func TestDispatcherInvalidIntervals(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max time.Duration
+		every    time.Duration
+	}{
+		{"zero min idle interval", 0, 8 * time.Millisecond, time.Second},
+		{"negative min idle interval", -time.Millisecond, 8 * time.Millisecond, time.Second},
+		{"max idle interval below min", 8 * time.Millisecond, time.Millisecond, time.Second},
+		{"zero task interval", time.Millisecond, 8 * time.Millisecond, 0},
+		{"negative task interval", time.Millisecond, 8 * time.Millisecond, -time.Second},
+	}
+	for _, test := range tests {
+		d, _, clock, ctx := newTestDispatcher(t)
+		d.MinIdleInterval, d.MaxIdleInterval = test.min, test.max
+		d.Every(test.every, func() { t.Errorf("%s: task called", test.name) })
+		if err := d.Run(ctx); err == nil || errors.Is(err, context.Canceled) {
+			t.Errorf("%s: Run = %v, want an error", test.name, err)
+		}
+		if len(clock.waits) != 0 {
+			t.Errorf("%s: dispatcher waited %v", test.name, clock.waits)
+		}
+	}
+}
+
+func TestDispatcherPeriodicTask(t *testing.T) {
+	d, _, clock, ctx := newTestDispatcher(t)
+	start := clock.Now()
+	calls := make([]time.Duration, 0)
+	d.Every(20*time.Millisecond, func() {
+		calls = append(calls, clock.Now().Sub(start))
+	})
+	clock.onWait = func(n int) bool {
+		return clock.Now().Sub(start) < 70*time.Millisecond
+	}
+
+	if err := d.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	want := []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 60 * time.Millisecond}
+	if len(calls) != len(want) {
+		t.Fatalf("task called at %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("task called at %v, want %v", calls, want)
+		}
+	}
+	for _, wait := range clock.waits {
+		if wait > d.MaxIdleInterval {
+			t.Fatalf("waited %v, longer than MaxIdleInterval", wait)
+		}
+	}
+}
+
+func TestDispatcherQuit(t *testing.T) {
+	d, backend, _, ctx := newTestDispatcher(t)
+	pushEvent(t, backend, 1)
+	backend.PushRecv(RecvQuit{Recv{ID: RecvIDQuit}})
+	pushEvent(t, backend, 2)
+	quit := false
+	d.Listen(&EventListener{OnQuit: func() { quit = true }})
+
+	if err := d.Run(ctx); !errors.Is(err, ErrQuit) {
+		t.Fatalf("Run = %v, want ErrQuit", err)
+	}
+	if !quit {
+		t.Error("OnQuit wasn't called")
+	}
+	if backend.Pending() != 1 {
+		t.Errorf("%d messages pending, want the one after RecvQuit", backend.Pending())
+	}
+}
+
+func TestDispatcherSourceError(t *testing.T) {
+	d, backend, clock, ctx := newTestDispatcher(t)
+	errBroken := errors.New("pipe broken")
+	clock.onWait = func(n int) bool {
+		if n == 2 {
+			backend.SetError(scGetNextDispatch, errBroken)
+		}
+		return n < 10
+	}
+
+	if err := d.Run(ctx); !errors.Is(err, errBroken) {
+		t.Fatalf("Run = %v, want %v", err, errBroken)
+	}
+	if len(clock.waits) != 2 {
+		t.Errorf("waited %d times before the error, want 2", len(clock.waits))
+	}
+}
+
+func TestDispatcherDropsMalformedMessages(t *testing.T) {
+	d, backend, clock, ctx := newTestDispatcher(t)
+	backend.Push([]byte{0xFF, 0, 0, 0, 4, 0, 0, 0, byte(RecvIDEvent), 0, 0, 0}) // size beyond the packet
+	pushEvent(t, backend, 7)
+	events := 0
+	d.Handle(func(msg *Message, value interface{}) { events++ })
+	clock.onWait = func(n int) bool { return false }
+
+	if err := d.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	if events != 1 {
+		t.Errorf("%d messages handled, want 1", events)
+	}
+}
+
+func TestDispatcherContextCanceled(t *testing.T) {
+	d, _, _, _ := newTestDispatcher(t)
+	d.clock = SystemClock()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Run = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the context was canceled")
+	}
+}
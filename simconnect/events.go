@@ -0,0 +1,259 @@
+package simconnect
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed data/events.txt
+var eventCatalogData string
+
+// ErrUnknownEvent is returned for a key event name which is not in the catalog.
+var ErrUnknownEvent = errors.New("unknown event")
+
+// EventInfo describes a key event of the catalog.
+type EventInfo struct {
+	Name      string
+	Category  string // e.g. "Autopilot" or "Lights"
+	Parameter string // meaning of the event data, empty if the event takes none
+}
+
+// HasParameter reports whether the event takes data.
+func (info EventInfo) HasParameter() bool {
+	return info.Parameter != ""
+}
+
+type eventCatalog struct {
+	mutex  sync.RWMutex
+	events map[string]EventInfo
+}
+
+var (
+	eventCatalogOnce sync.Once
+	events           *eventCatalog
+)
+
+func theEventCatalog() *eventCatalog {
+	eventCatalogOnce.Do(func() {
+		events = &eventCatalog{
+			events: parseEventCatalog(eventCatalogData),
+		}
+	})
+	return events
+}
+
+func parseEventCatalog(data string) map[string]EventInfo {
+	events := make(map[string]EventInfo)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		for len(fields) < 3 {
+			fields = append(fields, "")
+		}
+		events[fields[0]] = EventInfo{
+			Name:      fields[0],
+			Category:  fields[1],
+			Parameter: fields[2],
+		}
+	}
+	return events
+}
+
+// LookupEvent returns the catalog entry of a key event. The name is case insensitive.
+func LookupEvent(name string) (EventInfo, bool) {
+	c := theEventCatalog()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	info, exists := c.events[eventName(name)]
+	return info, exists
+}
+
+// EventInfos returns all key events of the catalog sorted by name.
+func EventInfos() []EventInfo {
+	c := theEventCatalog()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	infos := make([]EventInfo, 0, len(c.events))
+	for _, info := range c.events {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// RegisterEventInfo adds a key event to the catalog or replaces it, e.g. an event the catalog doesn't list yet.
+func RegisterEventInfo(info EventInfo) {
+	info.Name = eventName(info.Name)
+	c := theEventCatalog()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.events[info.Name] = info
+}
+
+// ValidateEvent checks a key event name against the catalog and suggests similar names for unknown ones.
+// Custom events like "MyAddon.Event" or "#0x11000" aren't in the catalog and are not checked.
+func ValidateEvent(name string) error {
+	if isCustomEventName(name) {
+		return nil
+	}
+	if _, exists := LookupEvent(name); exists {
+		return nil
+	}
+	c := theEventCatalog()
+	c.mutex.RLock()
+	names := make([]string, 0, len(c.events))
+	for name := range c.events {
+		names = append(names, name)
+	}
+	c.mutex.RUnlock()
+	return fmt.Errorf("%w %q%s", ErrUnknownEvent, name, didYouMean(eventName(name), names))
+}
+
+// eventName normalizes a key event name, i.e. upper case. Custom event names are left as they are.
+func eventName(name string) string {
+	name = strings.TrimSpace(name)
+	if isCustomEventName(name) {
+		return name
+	}
+	return strings.ToUpper(name)
+}
+
+func isCustomEventName(name string) bool {
+	return strings.HasPrefix(name, "#") || strings.Contains(name, ".")
+}
+
+// EventRegistry maps event names to client event IDs. An event is mapped with MapClientEventToSimEvent
+// when it is first used, so callers deal with names like "AP_MASTER" rather than IDs.
+// Key event names are checked against the catalog unless SkipValidation of the SimConnect is set. As the catalog
+// may lag behind the Simulator, an unknown event is logged with a suggestion and mapped anyway; the Simulator answers
+// a name it doesn't know with ExceptionNameUnrecognized. Use ValidateEvent to reject unknown names up front.
+// Mappings and subscriptions belong to a connection: after the SimConnect was opened again, events are mapped
+// anew on first use and have to be subscribed again.
+type EventRegistry struct {
+	simco      *SimConnect
+	mutex      sync.Mutex
+	session    uint64
+	ids        map[string]DWord
+	names      map[DWord]string
+	groupID    DWord
+	subscribed map[DWord]bool
+}
+
+// NewEventRegistry creates an EventRegistry for the connection.
+func NewEventRegistry(simco *SimConnect) *EventRegistry {
+	return &EventRegistry{
+		simco:      simco,
+		session:    simco.sessionID(),
+		ids:        make(map[string]DWord),
+		names:      make(map[DWord]string),
+		subscribed: make(map[DWord]bool),
+	}
+}
+
+// checkSession drops the mappings and subscriptions of an earlier connection.
+func (r *EventRegistry) checkSession() {
+	session := r.simco.sessionID()
+	if session == r.session {
+		return
+	}
+	r.session = session
+	r.ids = make(map[string]DWord)
+	r.names = make(map[DWord]string)
+	r.groupID = 0
+	r.subscribed = make(map[DWord]bool)
+}
+
+// ID returns the client event ID of the event, mapping it if needed.
+func (r *EventRegistry) ID(name string) (DWord, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.id(name)
+}
+
+func (r *EventRegistry) id(name string) (DWord, error) {
+	r.checkSession()
+	key := eventName(name)
+	if eventID, exists := r.ids[key]; exists {
+		return eventID, nil
+	}
+	if !r.simco.SkipValidation {
+		if err := ValidateEvent(key); err != nil {
+			log.Warnf("EventRegistry: %s", err.Error())
+		}
+	}
+	eventID := NewEventID()
+	if err := r.simco.MapClientEventToSimEvent(eventID, key); err != nil {
+		return 0, err
+	}
+	r.ids[key] = eventID
+	r.names[eventID] = key
+	return eventID, nil
+}
+
+// Name returns the name of a mapped event, e.g. for the EventID of a RecvEvent.
+func (r *EventRegistry) Name(eventID DWord) (string, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.checkSession()
+	name, exists := r.names[eventID]
+	return name, exists
+}
+
+// Transmit sends the event to the user object, e.g. Transmit("HEADING_BUG_SET", 270).
+// Events take at most one data value, see EventInfo.Parameter for its meaning.
+func (r *EventRegistry) Transmit(name string, data ...DWord) error {
+	return r.TransmitTo(ObjectIDUser, name, data...)
+}
+
+// TransmitTo sends the event to the given object with the highest priority.
+func (r *EventRegistry) TransmitTo(objectID DWord, name string, data ...DWord) error {
+	if len(data) > 1 {
+		return fmt.Errorf("%s: events take at most one data value, got %d", name, len(data))
+	}
+	var value DWord
+	if len(data) == 1 {
+		value = data[0]
+	}
+	eventID, err := r.ID(name)
+	if err != nil {
+		return err
+	}
+	return r.simco.TransmitClientEvent(uint32(objectID), uint32(eventID), value, GroupPriorityHighest, EventFlagGroupIDIsPriority)
+}
+
+// Subscribe adds the event to the notification group of the registry, so the simulator reports it
+// as a RecvEvent whenever it is triggered, e.g. by the user in the cockpit. See EventListener.OnEvent.
+func (r *EventRegistry) Subscribe(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	eventID, err := r.id(name)
+	if err != nil {
+		return err
+	}
+	if r.subscribed[eventID] {
+		return nil
+	}
+	if r.groupID == 0 {
+		groupID := NewGroupID()
+		if err := r.simco.SetNotificationGroupPriority(groupID, GroupPriorityHighest); err != nil {
+			return err
+		}
+		r.groupID = groupID
+	}
+	if err := r.simco.AddClientEventToNotificationGroup(r.groupID, eventID, false); err != nil {
+		return err
+	}
+	r.subscribed[eventID] = true
+	return nil
+}
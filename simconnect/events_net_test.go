@@ -0,0 +1,62 @@
+package simconnect_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+)
+
+func TestEventRegistryReconnect(t *testing.T) {
+	srv := newTestServer(t)
+	simco := NewSimConnectWithBackend(srv.Backend())
+	registry := NewEventRegistry(simco)
+	for i := 1; i <= 2; i++ {
+		if err := simco.Open("events"); err != nil {
+			t.Fatal(err)
+		}
+		if msg := waitForMessage(t, simco); msg.ID() != RecvIDOpen {
+			t.Fatalf("open %d: got message %d, want RecvIDOpen", i, msg.ID())
+		}
+		if err := registry.Transmit("GEAR_TOGGLE"); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.Subscribe("FLAPS_INCR"); err != nil {
+			t.Fatal(err)
+		}
+		if !srv.WaitForCalls("SimConnect_AddClientEventToNotificationGroup", i, time.Second) {
+			t.Fatalf("open %d: FLAPS_INCR wasn't subscribed", i)
+		}
+
+		srv.FireClientEvent("FLAPS_INCR", 3)
+		event, err := waitForMessage(t, simco).Event()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name, exists := registry.Name(event.EventID); !exists || name != "FLAPS_INCR" || event.Data != 3 {
+			t.Errorf("open %d: event %d (%q, %v) with %d, want FLAPS_INCR with 3", i, event.EventID, name, exists, event.Data)
+		}
+		if err := simco.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := srv.TransmittedEvents()
+	if len(events) != 2 {
+		t.Fatalf("server got %d events, want 2", len(events))
+	}
+	for i, event := range events {
+		if event.Name != "GEAR_TOGGLE" {
+			t.Errorf("event %d was sent as %q, want GEAR_TOGGLE", i+1, event.Name)
+		}
+	}
+	maps := 0
+	for _, call := range srv.Calls() {
+		if call.Name == "SimConnect_MapClientEventToSimEvent" {
+			maps++
+		}
+	}
+	if maps != 4 {
+		t.Errorf("server got %d event mappings, want 4", maps)
+	}
+}
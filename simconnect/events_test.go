@@ -0,0 +1,134 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateEvent(t *testing.T) {
+	for _, name := range []string{"PITOT_HEAT_TOGGLE", "TOGGLE_ELECTRIC_FUEL_PUMP", "REPAIR_AND_REFUEL", "FLIGHT_LEVEL_CHANGE", "gear_toggle", "MyAddon.Event", "#0x11000"} {
+		if err := ValidateEvent(name); err != nil {
+			t.Errorf("ValidateEvent(%q) = %v", name, err)
+		}
+	}
+	if err := ValidateEvent("GEAR_TOGLE"); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("ValidateEvent(GEAR_TOGLE) = %v, want ErrUnknownEvent", err)
+	}
+}
+
+func TestTransmitUnknownEvent(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	for _, name := range []string{"PITOT_HEAT_TOGGLE", "SOME_EVENT_THE_CATALOG_MISSES"} {
+		if err := mate.Events().Transmit(name); err != nil {
+			t.Errorf("Transmit(%q) = %v", name, err)
+		}
+	}
+	maps := backend.CallsNamed(scMapClientEventToSimEvent)
+	if len(maps) != 2 || maps[1].Args[1] != "SOME_EVENT_THE_CATALOG_MISSES" {
+		t.Fatalf("MapClientEventToSimEvent calls = %v", maps)
+	}
+	if transmits := backend.CallsNamed(scTransmitClientEvent); len(transmits) != 2 {
+		t.Errorf("got %d transmitted events, want 2", len(transmits))
+	}
+}
+
+func TestEventRegistryMapsOnce(t *testing.T) {
+	backend := NewScriptedBackend()
+	registry := NewEventRegistry(NewSimConnectWithBackend(backend))
+	for _, name := range []string{"AP_MASTER", "ap_master", " AP_MASTER "} {
+		if err := registry.Transmit(name); err != nil {
+			t.Fatalf("Transmit(%q) = %v", name, err)
+		}
+	}
+	if err := registry.Transmit("MyAddon.Event"); err != nil {
+		t.Fatal(err)
+	}
+	maps := backend.CallsNamed(scMapClientEventToSimEvent)
+	if len(maps) != 2 || maps[0].Args[1] != "AP_MASTER" || maps[1].Args[1] != "MyAddon.Event" {
+		t.Fatalf("MapClientEventToSimEvent calls = %v", maps)
+	}
+	eventID, err := registry.ID("ap_master")
+	if err != nil || eventID != maps[0].Args[0] {
+		t.Errorf("ID(ap_master) = %d, %v, want %v", eventID, err, maps[0].Args[0])
+	}
+	for _, call := range maps {
+		if name, exists := registry.Name(call.Args[0].(DWord)); !exists || name != call.Args[1] {
+			t.Errorf("Name(%v) = %q, %v, want %q", call.Args[0], name, exists, call.Args[1])
+		}
+	}
+	if name, exists := registry.Name(eventID + 1000); exists {
+		t.Errorf("Name of an unmapped ID = %q", name)
+	}
+
+	backend.Reset()
+	backend.SetError(scMapClientEventToSimEvent, errors.New("failed"))
+	if err := registry.Transmit("GEAR_TOGGLE"); err == nil {
+		t.Fatal("Transmit succeeded without a mapping")
+	}
+	backend.SetError(scMapClientEventToSimEvent, nil)
+	if err := registry.Transmit("GEAR_TOGGLE"); err != nil {
+		t.Fatal(err)
+	}
+	if maps := backend.CallsNamed(scMapClientEventToSimEvent); len(maps) != 2 {
+		t.Errorf("a failed mapping was kept, %d MapClientEventToSimEvent calls", len(maps))
+	}
+}
+
+func TestEventRegistryTransmitTo(t *testing.T) {
+	backend := NewScriptedBackend()
+	registry := NewEventRegistry(NewSimConnectWithBackend(backend))
+	if err := registry.TransmitTo(42, "HEADING_BUG_SET", 270, 1); err == nil {
+		t.Error("TransmitTo with two data values succeeded")
+	}
+	if calls := backend.Calls(); len(calls) != 0 {
+		t.Fatalf("calls for a rejected event = %v", calls)
+	}
+	if err := registry.TransmitTo(42, "HEADING_BUG_SET", 270); err != nil {
+		t.Fatal(err)
+	}
+	eventID, _ := registry.ID("HEADING_BUG_SET")
+	transmits := backend.CallsNamed(scTransmitClientEvent)
+	if len(transmits) != 1 {
+		t.Fatalf("got %d transmitted events, want 1", len(transmits))
+	}
+	args := transmits[0].Args
+	if args[0] != uint32(42) || args[1] != uint32(eventID) || args[2] != DWord(270) || args[3] != DWord(GroupPriorityHighest) || args[4] != DWord(EventFlagGroupIDIsPriority) {
+		t.Errorf("TransmitClientEvent(%v)", args)
+	}
+}
+
+func TestEventRegistrySubscribe(t *testing.T) {
+	received := make(map[string]DWord)
+	m := newTestMate(t, &EventListener{OnEvent: func(name string, data DWord) { received[name] = data }})
+	for _, name := range []string{"GEAR_TOGGLE", "FLAPS_INCR", "gear_toggle"} {
+		if err := m.Events().Subscribe(name); err != nil {
+			t.Fatalf("Subscribe(%q) = %v", name, err)
+		}
+	}
+	priorities := m.backend.CallsNamed(scSetNotificationGroupPriority)
+	if len(priorities) != 1 || priorities[0].Args[1] != DWord(GroupPriorityHighest) {
+		t.Fatalf("SetNotificationGroupPriority calls = %v", priorities)
+	}
+	groupID := priorities[0].Args[0]
+	adds := m.backend.CallsNamed(scAddClientEventToNotificationGroup)
+	if len(adds) != 2 {
+		t.Fatalf("got %d AddClientEventToNotificationGroup calls, want 2", len(adds))
+	}
+	for _, call := range adds {
+		if call.Args[0] != groupID {
+			t.Errorf("event %v added to group %v, want %v", call.Args[1], call.Args[0], groupID)
+		}
+	}
+
+	gearID, _ := m.Events().ID("GEAR_TOGGLE")
+	if err := m.backend.PushRecv(RecvEvent{Recv: Recv{ID: RecvIDEvent}, GroupID: groupID.(DWord), EventID: gearID, Data: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.d.drain(); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := received["GEAR_TOGGLE"]; !ok || data != 1 || len(received) != 1 {
+		t.Errorf("OnEvent received %v, want GEAR_TOGGLE with 1", received)
+	}
+}
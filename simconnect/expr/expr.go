@@ -0,0 +1,329 @@
+// Package expr evaluates arithmetic expressions over named variables, e.g. the crosswind component
+//
+//	[AMBIENT WIND VELOCITY] * sin(rad([AMBIENT WIND DIRECTION] - [PLANE HEADING DEGREES TRUE]))
+//
+// Expressions are made of numbers, variables, the operators + - * / % ^ (power), comparisons < <= > >= == !=,
+// logical && || !, the conditional c ? a : b, parentheses and function calls. Booleans are numbers:
+// comparisons yield 1 or 0 and every value other than 0 is true.
+// Variables are bare identifiers like alt_ft or names in brackets like [ENG N1 RPM:1], which may contain spaces.
+// The constants pi and e are predefined. See Functions for the available functions.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrSyntax is returned by Parse for a malformed expression.
+	ErrSyntax = errors.New("syntax error")
+	// ErrUnknownVar is returned by Eval for a variable the environment doesn't know.
+	ErrUnknownVar = errors.New("unknown variable")
+)
+
+// Env resolves a variable to its value. ok is false if the variable is unknown.
+type Env func(name string) (value float64, ok bool)
+
+// Expr is a parsed expression. It is immutable and safe for concurrent use.
+type Expr struct {
+	src  string
+	root node
+	vars []string
+}
+
+// Parse parses an expression.
+func Parse(src string) (*Expr, error) {
+	p := &parser{lexer: lexer{src: src}}
+	p.next()
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.err != nil || p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	seen := make(map[string]bool)
+	vars := make([]string, 0)
+	collectVars(root, func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			vars = append(vars, name)
+		}
+	})
+	return &Expr{src: src, root: root, vars: vars}, nil
+}
+
+// MustParse is like Parse but panics if the expression is malformed.
+func MustParse(src string) *Expr {
+	e, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Eval evaluates the expression, looking up the variables in env.
+func (e *Expr) Eval(env Env) (float64, error) {
+	return e.root.eval(env)
+}
+
+// Vars returns the variables the expression refers to, in order of their first appearance.
+func (e *Expr) Vars() []string {
+	return append([]string(nil), e.vars...)
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Map returns an Env which looks variables up in the map.
+func Map(values map[string]float64) Env {
+	return func(name string) (float64, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+type node interface {
+	eval(env Env) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(env Env) (float64, error) {
+	return float64(n), nil
+}
+
+type varNode string
+
+func (n varNode) eval(env Env) (float64, error) {
+	if env != nil {
+		if value, ok := env(string(n)); ok {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q", ErrUnknownVar, string(n))
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env Env) (float64, error) {
+	x, err := n.operand.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "!" {
+		return boolValue(x == 0), nil
+	}
+	return -x, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env Env) (float64, error) {
+	x, err := n.left.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	// && and || only evaluate the right side if needed
+	switch n.op {
+	case "&&":
+		if x == 0 {
+			return 0, nil
+		}
+	case "||":
+		if x != 0 {
+			return 1, nil
+		}
+	}
+	y, err := n.right.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		return x / y, nil
+	case "%":
+		return math.Mod(x, y), nil
+	case "^":
+		return math.Pow(x, y), nil
+	case "<":
+		return boolValue(x < y), nil
+	case "<=":
+		return boolValue(x <= y), nil
+	case ">":
+		return boolValue(x > y), nil
+	case ">=":
+		return boolValue(x >= y), nil
+	case "==":
+		return boolValue(x == y), nil
+	case "!=":
+		return boolValue(x != y), nil
+	case "&&", "||":
+		return boolValue(y != 0), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", n.op)
+}
+
+type condNode struct {
+	cond, then, otherwise node
+}
+
+func (n *condNode) eval(env Env) (float64, error) {
+	c, err := n.cond.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if c != 0 {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(env Env) (float64, error) {
+	// if(c, a, b) only evaluates the branch it takes
+	if n.name == "if" {
+		return (&condNode{n.args[0], n.args[1], n.args[2]}).eval(env)
+	}
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+	return n.fn.call(args), nil
+}
+
+type function struct {
+	minArgs int
+	maxArgs int // -1 for any number
+	call    func(args []float64) float64
+}
+
+func fn1(f func(float64) float64) function {
+	return function{1, 1, func(args []float64) float64 { return f(args[0]) }}
+}
+
+func fn2(f func(float64, float64) float64) function {
+	return function{2, 2, func(args []float64) float64 { return f(args[0], args[1]) }}
+}
+
+var functions = map[string]function{
+	"abs":   fn1(math.Abs),
+	"sqrt":  fn1(math.Sqrt),
+	"exp":   fn1(math.Exp),
+	"log":   fn1(math.Log),
+	"log10": fn1(math.Log10),
+	"floor": fn1(math.Floor),
+	"ceil":  fn1(math.Ceil),
+	"round": fn1(math.Round),
+	"sin":   fn1(math.Sin),
+	"cos":   fn1(math.Cos),
+	"tan":   fn1(math.Tan),
+	"asin":  fn1(math.Asin),
+	"acos":  fn1(math.Acos),
+	"atan":  fn1(math.Atan),
+	"atan2": fn2(math.Atan2),
+	"pow":   fn2(math.Pow),
+	"hypot": fn2(math.Hypot),
+	"rad":   fn1(func(deg float64) float64 { return deg * math.Pi / 180 }),
+	"deg":   fn1(func(rad float64) float64 { return rad * 180 / math.Pi }),
+	"sign": fn1(func(x float64) float64 {
+		switch {
+		case x > 0:
+			return 1
+		case x < 0:
+			return -1
+		}
+		return 0
+	}),
+	"min": {1, -1, func(args []float64) float64 {
+		m := args[0]
+		for _, arg := range args[1:] {
+			m = math.Min(m, arg)
+		}
+		return m
+	}},
+	"max": {1, -1, func(args []float64) float64 {
+		m := args[0]
+		for _, arg := range args[1:] {
+			m = math.Max(m, arg)
+		}
+		return m
+	}},
+	"clamp": {3, 3, func(args []float64) float64 {
+		return math.Max(args[1], math.Min(args[2], args[0]))
+	}},
+	"if": {3, 3, nil}, // evaluated lazily by callNode
+}
+
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// Functions returns the names of the functions expressions can call, sorted.
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func collectVars(n node, fn func(name string)) {
+	switch n := n.(type) {
+	case varNode:
+		fn(string(n))
+	case *unaryNode:
+		collectVars(n.operand, fn)
+	case *binaryNode:
+		collectVars(n.left, fn)
+		collectVars(n.right, fn)
+	case *condNode:
+		collectVars(n.cond, fn)
+		collectVars(n.then, fn)
+		collectVars(n.otherwise, fn)
+	case *callNode:
+		for _, arg := range n.args {
+			collectVars(arg, fn)
+		}
+	}
+}
+
+// lookupFunction returns the function with the given name, case insensitive.
+func lookupFunction(name string) (function, bool) {
+	f, ok := functions[strings.ToLower(name)]
+	return f, ok
+}
@@ -0,0 +1,150 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	env := Map(map[string]float64{"x": 3, "ENG N1 RPM:1": 90})
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"7 - 2 - 1", 4},
+		{"8 / 4 / 2", 1},
+		{"7 % 4", 3},
+		// power binds tighter than unary minus on its left and is right associative
+		{"-2^2", -4},
+		{"(-2)^2", 4},
+		{"2^-1", 0.5},
+		{"2^3^2", 512},
+		{"-x^2", -9},
+		{"2 * 3^2", 18},
+		{"--2", 2},
+		{"+2", 2},
+		{"!0", 1},
+		{"!2", 0},
+		{"1 < 2 == 1", 1},
+		{"1 + 1 == 2 && 3 > 2", 1},
+		{"0 || 0 && 1", 0},
+		{"1 || 0 && 0", 1},
+		// ?: is right associative and has the lowest precedence
+		{"1 ? 2 : 3", 2},
+		{"0 ? 2 : 3", 3},
+		{"0 ? 1 : 0 ? 2 : 3", 3},
+		{"0 ? 1 : 1 ? 2 : 3", 2},
+		{"1 ? 0 ? 4 : 5 : 6", 5},
+		{"x > 2 ? x * 2 : x", 6},
+		{"1 + 1 ? 2 : 3", 2},
+		{"(1 ? 2 : 3) + 1", 3},
+		{"[ENG N1 RPM:1] / 2", 45},
+		{"[ ENG N1 RPM:1 ]", 90},
+		{"1e3 + .5", 1000.5},
+		{"2.5E-1", 0.25},
+		{"pi", math.Pi},
+		{"deg(pi)", 180},
+		{"MAX(1, 5, 3)", 5},
+		{"min(4)", 4},
+		{"clamp(12, 0, 10)", 10},
+		{"atan2(0, -1)", math.Pi},
+		{"sign(-3)", -1},
+		{"if(x > 2, 1, 2)", 1},
+	}
+	for _, test := range tests {
+		e, err := Parse(test.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.src, err)
+			continue
+		}
+		got, err := e.Eval(env)
+		if err != nil {
+			t.Errorf("%q: %v", test.src, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%q = %v, want %v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestEvalShortCircuit(t *testing.T) {
+	// missing is unknown, evaluating it fails
+	env := Map(map[string]float64{"x": 1})
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"0 && missing", 0},
+		{"x || missing", 1},
+		{"x == 1 || missing > 0", 1},
+		{"if(x, 2, missing)", 2},
+		{"if(0, missing, 3)", 3},
+		{"x ? 4 : missing", 4},
+		{"!x ? missing : 5", 5},
+		{"0 && missing || 6", 1},
+	}
+	for _, test := range tests {
+		got, err := MustParse(test.src).Eval(env)
+		if err != nil || got != test.want {
+			t.Errorf("%q = %v, %v, want %v", test.src, got, err, test.want)
+		}
+	}
+
+	for _, src := range []string{"x && missing", "0 || missing", "if(x, missing, 1)", "max(x, missing)"} {
+		if _, err := MustParse(src).Eval(env); !errors.Is(err, ErrUnknownVar) {
+			t.Errorf("%q: err = %v, want ErrUnknownVar", src, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string // part of the error message
+	}{
+		{"", "unexpected end of expression"},
+		{"1 +", "unexpected end of expression"},
+		{"(1 + 2", `expected ")"`},
+		{"1 2", `unexpected "2"`},
+		{"1 ? 2", `expected ":"`},
+		{"2 $ 3", `unexpected '$'`},
+		{"[ENG N1 RPM", "unterminated variable name"},
+		{"1 + [ENG N1 RPM", "unterminated variable name"},
+		{"2 [ENG N1 RPM", "unterminated variable name"},
+		{"[ ]", "empty variable name"},
+		{"foo(1)", `unknown function "foo"`},
+		{"sin()", "sin takes 1 argument, got 0"},
+		{"sin(1, 2)", "sin takes 1 argument, got 2"},
+		{"atan2(1)", "atan2 takes 2 arguments, got 1"},
+		{"max()", "max takes at least 1 arguments, got 0"},
+		{"if(1, 2)", "if takes 3 arguments, got 2"},
+		{"sin(1,)", "unexpected \")\""},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.src)
+		if !errors.Is(err, ErrSyntax) {
+			t.Errorf("Parse(%q): err = %v, want ErrSyntax", test.src, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Parse(%q): err = %q, want it to contain %q", test.src, err, test.want)
+		}
+	}
+}
+
+func TestVars(t *testing.T) {
+	e := MustParse("[B] + a * [B] - if(c, a, pi)")
+	got := e.Vars()
+	want := []string{"B", "a", "c"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Vars = %v, want %v", got, want)
+	}
+	if _, err := e.Eval(Map(map[string]float64{"a": 1})); !errors.Is(err, ErrUnknownVar) {
+		t.Errorf("err = %v, want ErrUnknownVar", err)
+	}
+}
@@ -0,0 +1,311 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokVar // a bracketed variable name
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// operators lists the operators, longer ones first so "<=" isn't read as "<".
+var operators = []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "%", "^", "<", ">", "!", "?", ":", "(", ")", ","}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{tokEOF, "", start}, nil
+	}
+	c := l.src[l.pos]
+	switch {
+	case c == '[':
+		end := strings.IndexByte(l.src[l.pos:], ']')
+		if end < 0 {
+			return token{}, fmt.Errorf("%w at %d: unterminated variable name", ErrSyntax, start)
+		}
+		name := strings.TrimSpace(l.src[l.pos+1 : l.pos+end])
+		if name == "" {
+			return token{}, fmt.Errorf("%w at %d: empty variable name", ErrSyntax, start)
+		}
+		l.pos += end + 1
+		return token{tokVar, name, start}, nil
+
+	case c >= '0' && c <= '9' || c == '.':
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		// exponent, e.g. 1e-3
+		if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+			end := l.pos + 1
+			if end < len(l.src) && (l.src[end] == '+' || l.src[end] == '-') {
+				end++
+			}
+			if end < len(l.src) && isDigit(l.src[end]) {
+				for end < len(l.src) && isDigit(l.src[end]) {
+					end++
+				}
+				l.pos = end
+			}
+		}
+		return token{tokNumber, l.src[start:l.pos], start}, nil
+
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isDigit(l.src[l.pos]) || unicode.IsLetter(rune(l.src[l.pos]))) {
+			l.pos++
+		}
+		return token{tokIdent, l.src[start:l.pos], start}, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{tokOp, op, start}, nil
+		}
+	}
+	return token{}, fmt.Errorf("%w at %d: unexpected %q", ErrSyntax, start, c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parser is a recursive descent parser. From lowest to highest precedence:
+// ?:, ||, &&, comparisons, + -, * / %, unary - !, ^ (right associative).
+type parser struct {
+	lexer lexer
+	tok   token
+	err   error
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lexer.next()
+	if p.err != nil {
+		p.tok = token{kind: tokEOF}
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	if p.err != nil {
+		return p.err
+	}
+	return fmt.Errorf("%w at %d: %s", ErrSyntax, p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	if p.tok.kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if p.tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		return p.errorf("expected %q, got %s", op, p.tok)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parseExpr() (node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("?") {
+		return cond, nil
+	}
+	p.next()
+	then, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &condNode{cond, then, otherwise}, nil
+}
+
+// precedences of the left associative binary operators, lowest first.
+var precedences = [][]string{
+	{"||"},
+	{"&&"},
+	{"<", "<=", ">", ">=", "==", "!="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedences) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(precedences[level]...) {
+		op := p.tok.text
+		p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op, left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("-", "!", "+") {
+		op := p.tok.text
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return operand, nil
+		}
+		return &unaryNode{op, operand}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("^") {
+		return base, nil
+	}
+	p.next()
+	// right associative and binds tighter than unary minus on its left: -2^2 is -4, 2^-1 is 0.5
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{"^", base, exponent}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		p.next()
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w at %d: invalid number %q", ErrSyntax, tok.pos, tok.text)
+		}
+		return numberNode(value), nil
+
+	case tokVar:
+		p.next()
+		return varNode(tok.text), nil
+
+	case tokIdent:
+		p.next()
+		if p.isOp("(") {
+			return p.parseCall(tok)
+		}
+		if value, ok := constants[tok.text]; ok {
+			return numberNode(value), nil
+		}
+		return varNode(tok.text), nil
+
+	case tokOp:
+		if tok.text == "(" {
+			p.next()
+			inner, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := lookupFunction(name.text)
+	if !ok {
+		return nil, fmt.Errorf("%w at %d: unknown function %q", ErrSyntax, name.pos, name.text)
+	}
+	p.next() // (
+	args := make([]node, 0)
+	if !p.isOp(")") {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("%w at %d: %s takes %s, got %d", ErrSyntax, name.pos, name.text, arity(fn), len(args))
+	}
+	return &callNode{strings.ToLower(name.text), fn, args}, nil
+}
+
+func arity(fn function) string {
+	switch {
+	case fn.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", fn.minArgs)
+	case fn.minArgs == 1 && fn.maxArgs == 1:
+		return "1 argument"
+	case fn.minArgs == fn.maxArgs:
+		return fmt.Sprintf("%d arguments", fn.minArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", fn.minArgs, fn.maxArgs)
+}
@@ -0,0 +1,195 @@
+package simconnect
+
+import (
+	"sync"
+	"time"
+)
+
+// Interpolation selects how History.At computes a value between two samples.
+type Interpolation int
+
+const (
+	InterpolateStep   Interpolation = iota // the value of the sample before
+	InterpolateLinear                      // linear between the samples before and after
+)
+
+// Sample is a value of a simvar and when it was received.
+type Sample struct {
+	Value float64
+	Time  time.Time
+}
+
+// HistoryStats summarizes the samples of a window, see History.Stats.
+type HistoryStats struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+}
+
+// History is a ring buffer of the latest samples of a numeric simvar. It is safe for concurrent use.
+// Samples are expected in chronological order.
+type History struct {
+	mutex   sync.Mutex
+	samples []Sample
+	start   int // index of the oldest sample
+	count   int
+}
+
+// NewHistory returns a History which keeps the latest capacity samples.
+func NewHistory(capacity int) *History {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &History{samples: make([]Sample, capacity)}
+}
+
+// Cap returns the number of samples the history keeps at most.
+func (h *History) Cap() int {
+	return len(h.samples)
+}
+
+// Len returns the number of samples.
+func (h *History) Len() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.count
+}
+
+// Add appends a sample, dropping the oldest one if the history is full.
+func (h *History) Add(value float64, t time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	i := (h.start + h.count) % len(h.samples)
+	h.samples[i] = Sample{value, t}
+	if h.count < len(h.samples) {
+		h.count++
+	} else {
+		h.start = (h.start + 1) % len(h.samples)
+	}
+}
+
+// Samples returns a copy of the samples, oldest first.
+func (h *History) Samples() []Sample {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.window(0)
+}
+
+// Latest returns the newest sample. ok is false if the history is empty.
+func (h *History) Latest() (sample Sample, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.count == 0 {
+		return sample, false
+	}
+	return h.at(h.count - 1), true
+}
+
+// At returns the value at the given time. Times after the newest sample return its value,
+// times before the oldest sample return false.
+func (h *History) At(t time.Time, mode Interpolation) (float64, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.count == 0 || t.Before(h.at(0).Time) {
+		return 0, false
+	}
+	// binary search for the first sample after t
+	lo, hi := 0, h.count
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if h.at(mid).Time.After(t) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	before := h.at(lo - 1)
+	if lo == h.count || mode == InterpolateStep {
+		return before.Value, true
+	}
+	after := h.at(lo)
+	span := after.Time.Sub(before.Time)
+	if span <= 0 {
+		return after.Value, true
+	}
+	fraction := float64(t.Sub(before.Time)) / float64(span)
+	return before.Value + (after.Value-before.Value)*fraction, true
+}
+
+// Stats returns min, max and mean of the samples received within the window before the newest sample.
+// A window of 0 covers all samples. ok is false if the history is empty.
+func (h *History) Stats(window time.Duration) (stats HistoryStats, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	samples := h.window(window)
+	if len(samples) == 0 {
+		return stats, false
+	}
+	stats.Count = len(samples)
+	stats.Min, stats.Max = samples[0].Value, samples[0].Value
+	sum := 0.0
+	for _, sample := range samples {
+		if sample.Value < stats.Min {
+			stats.Min = sample.Value
+		}
+		if sample.Value > stats.Max {
+			stats.Max = sample.Value
+		}
+		sum += sample.Value
+	}
+	stats.Mean = sum / float64(len(samples))
+	return stats, true
+}
+
+// Rate returns the rate of change per second over the window before the newest sample, e.g. feet per second
+// for an altitude in feet. It is the least squares slope of the samples, so single noisy samples don't dominate.
+// A window of 0 covers all samples. ok is false if there are less than two samples of different times.
+func (h *History) Rate(window time.Duration) (float64, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	samples := h.window(window)
+	if len(samples) < 2 {
+		return 0, false
+	}
+	origin := samples[0].Time
+	var sumT, sumV float64
+	for _, sample := range samples {
+		sumT += sample.Time.Sub(origin).Seconds()
+		sumV += sample.Value
+	}
+	n := float64(len(samples))
+	meanT, meanV := sumT/n, sumV/n
+	var covariance, variance float64
+	for _, sample := range samples {
+		dt := sample.Time.Sub(origin).Seconds() - meanT
+		covariance += dt * (sample.Value - meanV)
+		variance += dt * dt
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return covariance / variance, true
+}
+
+// at returns the i-th sample, oldest first. The caller holds the mutex.
+func (h *History) at(i int) Sample {
+	return h.samples[(h.start+i)%len(h.samples)]
+}
+
+// window returns a copy of the samples within the window before the newest sample, all samples for 0.
+// The caller holds the mutex.
+func (h *History) window(window time.Duration) []Sample {
+	first := 0
+	if window > 0 && h.count > 0 {
+		from := h.at(h.count - 1).Time.Add(-window)
+		for first < h.count && h.at(first).Time.Before(from) {
+			first++
+		}
+	}
+	samples := make([]Sample, 0, h.count-first)
+	for i := first; i < h.count; i++ {
+		samples = append(samples, h.at(i))
+	}
+	return samples
+}
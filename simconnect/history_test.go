@@ -0,0 +1,148 @@
+package simconnect
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var historyStart = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestHistory returns a history with one sample per second, starting at historyStart.
+func newTestHistory(capacity int, values ...float64) *History {
+	h := NewHistory(capacity)
+	for i, value := range values {
+		h.Add(value, historyStart.Add(time.Duration(i)*time.Second))
+	}
+	return h
+}
+
+func TestHistoryAt(t *testing.T) {
+	h := newTestHistory(8, 100, 200, 200, 500)
+	tests := []struct {
+		offset time.Duration
+		mode   Interpolation
+		want   float64
+		ok     bool
+	}{
+		{-time.Second, InterpolateStep, 0, false},
+		{0, InterpolateStep, 100, true},
+		{0, InterpolateLinear, 100, true},
+		{500 * time.Millisecond, InterpolateStep, 100, true},
+		{500 * time.Millisecond, InterpolateLinear, 150, true},
+		{1500 * time.Millisecond, InterpolateLinear, 200, true},
+		{2250 * time.Millisecond, InterpolateLinear, 275, true},
+		{3 * time.Second, InterpolateLinear, 500, true},
+		{time.Minute, InterpolateLinear, 500, true},
+		{time.Minute, InterpolateStep, 500, true},
+	}
+	for _, test := range tests {
+		got, ok := h.At(historyStart.Add(test.offset), test.mode)
+		if ok != test.ok || got != test.want {
+			t.Errorf("At(+%s, %d) = %v, %v, want %v, %v", test.offset, test.mode, got, ok, test.want, test.ok)
+		}
+	}
+	if _, ok := NewHistory(4).At(historyStart, InterpolateStep); ok {
+		t.Error("an empty history has a value")
+	}
+}
+
+func TestHistoryStats(t *testing.T) {
+	h := newTestHistory(8, 4, 8, 2, 6)
+	tests := []struct {
+		window time.Duration
+		want   HistoryStats
+	}{
+		{0, HistoryStats{Count: 4, Min: 2, Max: 8, Mean: 5}},
+		{time.Second, HistoryStats{Count: 2, Min: 2, Max: 6, Mean: 4}},
+		{1500 * time.Millisecond, HistoryStats{Count: 2, Min: 2, Max: 6, Mean: 4}},
+		{2 * time.Second, HistoryStats{Count: 3, Min: 2, Max: 8, Mean: 16.0 / 3}},
+		{time.Millisecond, HistoryStats{Count: 1, Min: 6, Max: 6, Mean: 6}},
+	}
+	for _, test := range tests {
+		got, ok := h.Stats(test.window)
+		if !ok || got != test.want {
+			t.Errorf("Stats(%s) = %+v, %v, want %+v", test.window, got, ok, test.want)
+		}
+	}
+	if _, ok := NewHistory(4).Stats(0); ok {
+		t.Error("an empty history has stats")
+	}
+}
+
+func TestHistoryRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		window time.Duration
+		want   float64
+		ok     bool
+	}{
+		{"climb", []float64{1000, 1010, 1020, 1030}, 0, 10, true},
+		{"noisy climb", []float64{0, 12, 18, 30}, 0, 9.6, true},
+		{"window", []float64{500, 500, 0, 10, 20}, 2 * time.Second, 10, true},
+		{"level", []float64{300, 300, 300}, 0, 0, true},
+		{"single sample", []float64{300}, 0, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := newTestHistory(8, test.values...).Rate(test.window)
+		if ok != test.ok || math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: Rate = %v, %v, want %v, %v", test.name, got, ok, test.want, test.ok)
+		}
+	}
+
+	h := NewHistory(4)
+	h.Add(1, historyStart)
+	h.Add(2, historyStart)
+	if _, ok := h.Rate(0); ok {
+		t.Error("rate of samples at the same time")
+	}
+}
+
+func TestHistoryWrapsAround(t *testing.T) {
+	h := newTestHistory(3, 1, 2, 3, 4, 5)
+	if h.Len() != 3 || h.Cap() != 3 {
+		t.Fatalf("len %d, cap %d, want 3 and 3", h.Len(), h.Cap())
+	}
+	samples := h.Samples()
+	for i, want := range []float64{3, 4, 5} {
+		if samples[i].Value != want || !samples[i].Time.Equal(historyStart.Add(time.Duration(i+2)*time.Second)) {
+			t.Errorf("sample %d = %+v, want %v", i, samples[i], want)
+		}
+	}
+	if latest, ok := h.Latest(); !ok || latest.Value != 5 {
+		t.Errorf("Latest = %+v, %v", latest, ok)
+	}
+	if _, ok := h.At(historyStart.Add(time.Second), InterpolateStep); ok {
+		t.Error("At found a dropped sample")
+	}
+	if value, ok := h.At(historyStart.Add(3500*time.Millisecond), InterpolateLinear); !ok || value != 4.5 {
+		t.Errorf("At across the wrap = %v, %v, want 4.5", value, ok)
+	}
+	if stats, _ := h.Stats(0); stats.Min != 3 || stats.Max != 5 {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestHistorySampleTimes(t *testing.T) {
+	m := newTestMate(t, nil)
+	altitude := m.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64, WithHistory(4))
+	for i := 1; i <= 2; i++ {
+		m.tick()
+		m.deliver(t, altitude, float64(1000*i))
+	}
+	history, exists := m.History(altitude)
+	if !exists {
+		t.Fatal("no history")
+	}
+	samples := history.Samples()
+	if len(samples) != 2 || !samples[1].Time.Equal(m.clock.now) || samples[1].Time.Sub(samples[0].Time) != time.Second {
+		t.Fatalf("samples %+v, want two a second apart on the dispatcher clock", samples)
+	}
+	if rate, ok := history.Rate(0); !ok || rate != 1000 {
+		t.Errorf("Rate = %v, %v, want 1000 feet per second", rate, ok)
+	}
+	if snapshot := m.Snapshot(); !snapshot.Updated(altitude).Equal(m.clock.now) {
+		t.Errorf("snapshot has the simvar sampled at %s, want %s", snapshot.Updated(altitude), m.clock.now)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package simconnect
+
+import (
+	"errors"
+)
+
+var errNoLibrary = errors.New("SimConnect.dll is only available on Windows")
+
+func loadLibrary(path string) error {
+	return errNoLibrary
+}
+
+func loadProcs() {
+}
+
+func procCall(procName string, args ...uintptr) (uintptr, error) {
+	return uintptr(EFail), errNoLibrary
+}
+
+func toUTF16Ptr(str string) (uintptr, error) {
+	return 0, errNoLibrary
+}
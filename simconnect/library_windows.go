@@ -0,0 +1,107 @@
+//go:build windows
+// +build windows
+
+package simconnect
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	library *syscall.LazyDLL
+	procs   map[string]*syscall.LazyProc
+)
+
+func loadLibrary(path string) error {
+	library = syscall.NewLazyDLL(path)
+	if err := library.Load(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func loadProcs() {
+	procs = make(map[string]*syscall.LazyProc)
+	procNames := []string{
+		scOpen,
+		scClose,
+		// scCallDispatch,
+		scGetNextDispatch,
+		scRequestSystemState,
+		scMapClientEventToSimEvent,
+		scSubscribeToSystemEvent,
+		scSetSystemEventState,
+		scUnsubscribeFromSystemEvent,
+		scSetNotificationGroupPriority,
+		scText,
+		scRequestDataOnSimObject,
+		scRequestDataOnSimObjectType,
+		scAddClientEventToNotificationGroup,
+		scRemoveClientEvent,
+		scTransmitClientEvent,
+		scMapClientDataNameToID,
+		scRequestClientData,
+		scCreateClientData,
+		scAddToClientDataDefinition,
+		scAddToDataDefinition,
+		scSetClientData,
+		scSetDataOnSimObject,
+		scClearClientDataDefinition,
+		scClearDataDefinition,
+		scMapInputEventToClientEvent,
+		scRequestNotificationGroup,
+		scClearInputGroup,
+		scClearNotificationGroup,
+		// scRequestReservedKey,
+		scSetInputGroupPriority,
+		scSetInputGroupState,
+		scRemoveInputEvent,
+		scAICreateEnrouteATCAircraft,
+		scAICreateNonATCAircraft,
+		scAICreateParkedATCAircraft,
+		scAICreateSimulatedObject,
+		scAIReleaseControl,
+		scAIRemoveObject,
+		scAISetAircraftFlightPlan,
+		scFlightLoad,
+		scFlightSave,
+		scFlightPlanLoad,
+		scGetLastSentPacketID,
+		// scRequestResponseTimes,
+		// scInsertString,
+		// scRetrieveString,
+		scRequestFacilitiesList,
+		scSubscribeToFacilities,
+		scUnsubscribeToFacilities,
+		// scCompleteCustomMissionAction,
+		// scExecuteMissionAction,
+		scMenuAddItem,
+		scMenuAddSubItem,
+		scMenuDeleteItem,
+		scMenuDeleteSubItem,
+		scCameraSetRelative6DOF,
+		scSetSystemState,
+	}
+	for _, procName := range procNames {
+		procs[procName] = library.NewProc(procName)
+	}
+}
+
+func procCall(procName string, args ...uintptr) (uintptr, error) {
+	proc, ok := procs[procName]
+	if !ok {
+		return 0, fmt.Errorf("proc %s not defined", procName)
+	}
+	r1, _, err := proc.Call(args...)
+	return r1, err
+}
+
+func toUTF16Ptr(str string) (uintptr, error) {
+	ptr, err := syscall.UTF16PtrFromString(str)
+	if err != nil {
+		return 0, err
+	}
+	return uintptr(unsafe.Pointer(ptr)), nil
+}
@@ -0,0 +1,187 @@
+package simconnect
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrMalformedMessage is returned when a message is truncated or doesn't match its Recv header.
+var ErrMalformedMessage = errors.New("malformed message")
+
+// Message is a received SimConnect packet.
+// It owns a copy of the data, so it stays valid after the next call to GetNextDispatch,
+// and all accessors are bounds-checked against the size reported by SimConnect.
+type Message struct {
+	data []byte
+}
+
+// NewMessage copies data, which must start with a Recv header, into a new Message.
+// The header's size must not exceed len(data), trailing bytes are cut off.
+func NewMessage(data []byte) (*Message, error) {
+	var recv Recv
+	if err := Unmarshal(data, &recv); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrMalformedMessage)
+	}
+	if recv.Size < DWord(PackedSize(&recv)) || int(recv.Size) > len(data) {
+		return nil, fmt.Errorf("header size %d doesn't fit into %d bytes: %w", recv.Size, len(data), ErrMalformedMessage)
+	}
+	return &Message{data: append([]byte(nil), data[:recv.Size]...)}, nil
+}
+
+// ID returns the RecvID* of the message.
+func (msg *Message) ID() DWord {
+	return msg.header().ID
+}
+
+// Size returns the size of the message in bytes.
+func (msg *Message) Size() DWord {
+	return DWord(len(msg.data))
+}
+
+// Version returns the SimConnect protocol version the message was sent with.
+func (msg *Message) Version() DWord {
+	return msg.header().Version
+}
+
+// Bytes returns the raw message data, header included.
+func (msg *Message) Bytes() []byte {
+	return msg.data
+}
+
+// Decode decodes the message into v, e.g. a *RecvEventFilename. The message must be at least as long as v.
+func (msg *Message) Decode(v interface{}) error {
+	if err := Unmarshal(msg.data, v); err != nil {
+		return fmt.Errorf("message %d: %v: %w", msg.ID(), err, ErrMalformedMessage)
+	}
+	return nil
+}
+
+// Exception decodes a RecvIDException message.
+func (msg *Message) Exception() (RecvException, error) {
+	var recv RecvException
+	err := msg.decodeAs(RecvIDException, &recv)
+	return recv, err
+}
+
+// Open decodes a RecvIDOpen message.
+func (msg *Message) Open() (RecvOpen, error) {
+	var recv RecvOpen
+	err := msg.decodeAs(RecvIDOpen, &recv)
+	return recv, err
+}
+
+// Event decodes a RecvIDEvent message.
+func (msg *Message) Event() (RecvEvent, error) {
+	var recv RecvEvent
+	err := msg.decodeAs(RecvIDEvent, &recv)
+	return recv, err
+}
+
+// SimObjectData decodes a RecvIDSimobjectData message and returns the header and the data block which follows it.
+func (msg *Message) SimObjectData() (RecvSimObjectData, []byte, error) {
+	return msg.simObjectData(RecvIDSimobjectData)
+}
+
+// SimObjectDataByType decodes a RecvIDSimObjectDataByType message and returns the header and the data block which follows it.
+func (msg *Message) SimObjectDataByType() (RecvSimObjectDataByType, []byte, error) {
+	recv, data, err := msg.simObjectData(RecvIDSimObjectDataByType)
+	return RecvSimObjectDataByType{recv}, data, err
+}
+
+// ClientData decodes a RecvIDClientData message and returns the header and the data block which follows it.
+func (msg *Message) ClientData() (RecvClientData, []byte, error) {
+	recv, data, err := msg.simObjectData(RecvIDClientData)
+	return RecvClientData{recv}, data, err
+}
+
+func (msg *Message) simObjectData(id DWord) (RecvSimObjectData, []byte, error) {
+	if err := msg.expect(id); err != nil {
+		return RecvSimObjectData{}, nil, err
+	}
+	recv, data, err := UnmarshalSimObjectData(msg.data)
+	if err != nil {
+		return recv, nil, fmt.Errorf("message %d: %v: %w", id, err, ErrMalformedMessage)
+	}
+	return recv, data, nil
+}
+
+func (msg *Message) decodeAs(id DWord, v interface{}) error {
+	if err := msg.expect(id); err != nil {
+		return err
+	}
+	return msg.Decode(v)
+}
+
+func (msg *Message) expect(id DWord) error {
+	if msg.ID() != id {
+		return fmt.Errorf("expected message %d, got %d", id, msg.ID())
+	}
+	return nil
+}
+
+func (msg *Message) header() Recv {
+	var recv Recv
+	Unmarshal(msg.data, &recv) // length was checked by NewMessage
+	return recv
+}
+
+// DecodeValue decodes a single value of the given DataType* from the start of data.
+// Fixed-size strings are returned as byte arrays, e.g. [256]byte, STRINGV as string
+// and the structured datatypes as InitPosition, MarkerState, Waypoint, LatLogAlt and XYZ.
+func DecodeValue(data []byte, dataType DWord) (interface{}, error) {
+	var value interface{}
+	switch dataType {
+	case DataTypeInt32:
+		value = new(int32)
+	case DataTypeInt64:
+		value = new(int64)
+	case DataTypeFloat32:
+		value = new(float32)
+	case DataTypeFloat64:
+		value = new(float64)
+	case DataTypeString8:
+		value = new([8]byte)
+	case DataTypeString32:
+		value = new([32]byte)
+	case DataTypeString64:
+		value = new([64]byte)
+	case DataTypeString128:
+		value = new([128]byte)
+	case DataTypeString256:
+		value = new([256]byte)
+	case DataTypeString260:
+		value = new([260]byte)
+	case DataTypeInitPosition:
+		value = new(InitPosition)
+	case DataTypeMarkerState:
+		value = new(MarkerState)
+	case DataTypeWaypoint:
+		value = new(Waypoint)
+	case DataTypeLatLonAlt:
+		value = new(LatLogAlt)
+	case DataTypeXYZ:
+		value = new(XYZ)
+	case DataTypeStringV:
+		s, _, err := RetrieveString(data)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("datatype not implemented: %d", dataType)
+	}
+	if err := Unmarshal(data, value); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrMalformedMessage)
+	}
+	return reflect.ValueOf(value).Elem().Interface(), nil
+}
+
+// CString converts a zero-padded char array as used by SimConnect to a string.
+func CString(buf []byte) string {
+	if end := bytes.IndexByte(buf, 0); end >= 0 {
+		buf = buf[:end]
+	}
+	return string(buf)
+}
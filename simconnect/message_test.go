@@ -0,0 +1,163 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+)
+
+// eventBytes lays out a SIMCONNECT_RECV_EVENT of the given header size into a buffer of length n.
+func eventBytes(size uint32, n int) golden {
+	return newGolden(n).
+		dword(0, size).
+		dword(4, 4).
+		dword(8, uint32(RecvIDEvent)).
+		dword(12, 1).
+		dword(16, 42).
+		dword(20, 7)
+}
+
+func TestNewMessage(t *testing.T) {
+	msg, err := NewMessage(eventBytes(24, 24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID() != RecvIDEvent || msg.Size() != 24 || msg.Version() != 4 {
+		t.Errorf("ID %d, size %d, version %d", msg.ID(), msg.Size(), msg.Version())
+	}
+	event, err := msg.Event()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GroupID != 1 || event.EventID != 42 || event.Data != 7 {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestNewMessageCutsTrailingBytes(t *testing.T) {
+	data := eventBytes(24, 40)
+	data[24] = 0xFF
+	msg, err := NewMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Size() != 24 || len(msg.Bytes()) != 24 {
+		t.Errorf("size %d, %d bytes, want 24", msg.Size(), len(msg.Bytes()))
+	}
+}
+
+func TestNewMessageCopiesData(t *testing.T) {
+	data := eventBytes(24, 24)
+	msg, err := NewMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		data[i] = 0xFF
+	}
+	event, err := msg.Event()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Size() != 24 || event.EventID != 42 || event.Data != 7 {
+		t.Errorf("message changed with the caller's buffer: size %d, event %+v", msg.Size(), event)
+	}
+}
+
+func TestNewMessageMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"shorter than the header", eventBytes(24, 24)[:8]},
+		{"size below the header", eventBytes(8, 24)},
+		{"size zero", eventBytes(0, 24)},
+		{"size beyond the packet", eventBytes(32, 24)},
+		{"huge size", eventBytes(0xFFFFFFFF, 24)},
+	}
+	for _, test := range tests {
+		if msg, err := NewMessage(test.data); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("%s: NewMessage = %v, %v, want ErrMalformedMessage", test.name, msg, err)
+		}
+	}
+}
+
+func TestMessageDecodeMalformed(t *testing.T) {
+	// a header which claims to be an event but ends after the group ID
+	short, err := NewMessage(eventBytes(16, 24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := short.Event(); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("Event = %v, want ErrMalformedMessage", err)
+	}
+
+	data := newGolden(20).dword(0, 20).dword(8, uint32(RecvIDSimobjectData))
+	truncated, err := NewMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := truncated.SimObjectData(); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("SimObjectData = %v, want ErrMalformedMessage", err)
+	}
+}
+
+func TestMessageWrongID(t *testing.T) {
+	msg, err := NewMessage(eventBytes(24, 24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := msg.Exception(); err == nil || errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("Exception = %v, want a mismatch error", err)
+	}
+	if _, _, err := msg.SimObjectDataByType(); err == nil {
+		t.Error("SimObjectDataByType decoded an event")
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	value, err := DecodeValue(newGolden(8).float64(0, 1.5), DataTypeFloat64)
+	if err != nil || value != 1.5 {
+		t.Errorf("DecodeValue = %v, %v", value, err)
+	}
+	if _, err := DecodeValue(newGolden(4), DataTypeFloat64); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("short float64: err = %v, want ErrMalformedMessage", err)
+	}
+	if _, err := DecodeValue(newGolden(8), 999); err == nil {
+		t.Error("unknown datatype decoded")
+	}
+}
+
+func TestDecodeStructures(t *testing.T) {
+	marker := MarkerState{MarkerState: 1}
+	copy(marker.MarkerName[:], "Cg")
+	tests := []struct {
+		dataType DWord
+		data     golden
+		want     interface{}
+	}{
+		{
+			DataTypeInitPosition,
+			newGolden(56).float64(0, 47.4).float64(8, -122.3).float64(16, 500).
+				float64(24, -2.5).float64(32, 1).float64(40, 270).dword(48, 1).dword(52, 0),
+			InitPosition{Latitude: 47.4, Longitude: -122.3, Altitude: 500, Pitch: -2.5, Bank: 1, Heading: 270, OnGround: 1},
+		},
+		{DataTypeMarkerState, newGolden(68).str(0, "Cg").dword(64, 1), marker},
+		{
+			DataTypeWaypoint,
+			newGolden(44).float64(0, 47.4).float64(8, -122.3).float64(16, 3000).dword(24, 0x4).float64(28, 120).float64(36, 60),
+			Waypoint{Latitude: 47.4, Longitude: -122.3, Altitude: 3000, Flags: 0x4, KtsSpeed: 120, PercentThrottle: 60},
+		},
+		{DataTypeLatLonAlt, newGolden(24).float64(0, 47.4).float64(8, -122.3).float64(16, 433), LatLogAlt{47.4, -122.3, 433}},
+		{DataTypeXYZ, newGolden(24).float64(0, 1).float64(8, -2).float64(16, 3), XYZ{1, -2, 3}},
+	}
+	for _, test := range tests {
+		value, err := DecodeValue(test.data, test.dataType)
+		if err != nil || value != test.want {
+			t.Errorf("DecodeValue(%s) = %+v, %v, want %+v", DataTypeToString(test.dataType), value, err, test.want)
+		}
+		if _, err := DecodeValue(test.data[:len(test.data)-1], test.dataType); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("short %s: err = %v, want ErrMalformedMessage", DataTypeToString(test.dataType), err)
+		}
+	}
+}
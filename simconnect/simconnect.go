@@ -5,8 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
-	"syscall"
-	"unsafe"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -16,12 +14,11 @@ const (
 )
 
 var (
-	library     *syscall.LazyDLL
-	procs       map[string]*syscall.LazyProc
 	lockID      sync.Mutex
 	defineID    DWord
 	eventID     DWord
 	requestID   DWord
+	groupID     DWord
 	initialized bool
 )
 
@@ -32,15 +29,52 @@ func init() {
 }
 
 type SimConnect struct {
-	handle    unsafe.Pointer
-	connected bool
+	// SkipValidation disables checking simvars and key events against the catalogs in AddToDataDefinition,
+	// SimMate and EventRegistry, see ValidateSimVar and ValidateEvent.
+	SkipValidation bool
+	backend        Backend
+	connected      bool
+	session        *session
+}
+
+// session holds what SimConnect registers with the simulator on its own. It belongs to a connection
+// and is dropped by Open, so nothing registered with an earlier connection is reused.
+type session struct {
+	mutex   sync.Mutex
+	id      uint64           // counts the connections, so caches like EventRegistry can tell theirs is gone
+	defines map[string]DWord // data definitions of a single simvar by name, see SetInitPosition
+}
+
+func newSession() *session {
+	return &session{defines: make(map[string]DWord)}
+}
+
+// reset drops the state of the previous connection.
+func (s *session) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.id++
+	s.defines = make(map[string]DWord)
+}
+
+// sessionID identifies the current connection. It changes with every successful Open.
+func (simco *SimConnect) sessionID() uint64 {
+	simco.session.mutex.Lock()
+	defer simco.session.mutex.Unlock()
+	return simco.session.id
 }
 
 func NewSimConnect() *SimConnect {
 	if !initialized {
 		panic("SimConnect not initialized.")
 	}
-	return &SimConnect{}
+	return NewSimConnectWithBackend(&dllBackend{})
+}
+
+// NewSimConnectWithBackend creates a SimConnect which talks through the given backend instead of SimConnect.dll.
+// Initialize does not need to be called in this case.
+func NewSimConnectWithBackend(backend Backend) *SimConnect {
+	return &SimConnect{backend: backend, session: newSession()}
 }
 
 func LocateLibrary(additionalSearchPath string) (string, error) {
@@ -89,6 +123,10 @@ func (simco *SimConnect) IsConnected() bool {
 	return simco.connected
 }
 
+func (simco *SimConnect) Backend() Backend {
+	return simco.backend
+}
+
 func NewDefineID() DWord {
 	lockID.Lock()
 	defer lockID.Unlock()
@@ -110,6 +148,13 @@ func NewEventID() DWord {
 	return eventID
 }
 
+func NewGroupID() DWord {
+	lockID.Lock()
+	defer lockID.Unlock()
+	groupID++
+	return groupID
+}
+
 func getSearchPaths(additionalSearchPath string) ([]string, error) {
 	paths := []string{}
 	if len(additionalSearchPath) > 0 {
@@ -139,107 +184,3 @@ func findLibrary(searchPaths []string) (string, error) {
 	}
 	return "", fmt.Errorf("could not locate %s in search paths", SimConnectDLL)
 }
-
-func loadLibrary(path string) error {
-	library = syscall.NewLazyDLL(path)
-	if err := library.Load(); err != nil {
-		return err
-	}
-	return nil
-}
-
-func loadProcs() {
-	procs = make(map[string]*syscall.LazyProc)
-	procNames := []string{
-		scOpen,
-		scClose,
-		// scCallDispatch,
-		scGetNextDispatch,
-		scRequestSystemState,
-		scMapClientEventToSimEvent,
-		scSubscribeToSystemEvent,
-		scSetSystemEventState,
-		scUnsubscribeFromSystemEvent,
-		scSetNotificationGroupPriority,
-		scText,
-		scRequestDataOnSimObject,
-		scRequestDataOnSimObjectType,
-		scAddClientEventToNotificationGroup,
-		scRemoveClientEvent,
-		scTransmitClientEvent,
-		scMapClientDataNameToID,
-		scRequestClientData,
-		scCreateClientData,
-		scAddToClientDataDefinition,
-		scAddToDataDefinition,
-		scSetClientData,
-		scSetDataOnSimObject,
-		scClearClientDataDefinition,
-		scClearDataDefinition,
-		scMapInputEventToClientEvent,
-		scRequestNotificationGroup,
-		scClearInputGroup,
-		scClearNotificationGroup,
-		// scRequestReservedKey,
-		scSetInputGroupPriority,
-		scSetInputGroupState,
-		scRemoveInputEvent,
-		scAICreateEnrouteATCAircraft,
-		scAICreateNonATCAircraft,
-		scAICreateParkedATCAircraft,
-		scAICreateSimulatedObject,
-		scAIReleaseControl,
-		scAIRemoveObject,
-		scAISetAircraftFlightPlan,
-		scFlightLoad,
-		scFlightSave,
-		scFlightPlanLoad,
-		scGetLastSentPacketID,
-		// scRequestResponseTimes,
-		// scInsertString,
-		// scRetrieveString,
-		scRequestFacilitiesList,
-		scSubscribeToFacilities,
-		scUnsubscribeToFacilities,
-		// scCompleteCustomMissionAction,
-		// scExecuteMissionAction,
-		scMenuAddItem,
-		scMenuAddSubItem,
-		scMenuDeleteItem,
-		scMenuDeleteSubItem,
-		scCameraSetRelative6DOF,
-		scSetSystemState,
-	}
-	for _, procName := range procNames {
-		procs[procName] = library.NewProc(procName)
-	}
-}
-
-func callProc(procName string, args ...uintptr) error {
-	proc, ok := procs[procName]
-	if !ok {
-		return fmt.Errorf("proc %s not defined", procName)
-	}
-	r1, _, err := proc.Call(args...)
-	if int32(r1) < 0 {
-		return fmt.Errorf("%s error: %d %s", procName, r1, err)
-	}
-	return nil
-}
-
-func toNullTerminatedBytes(str string) []byte {
-	return []byte(str + "\x00")
-}
-
-func toCharPtr(str string) uintptr {
-	bytes := toNullTerminatedBytes(str)
-	return uintptr(unsafe.Pointer(&bytes[0]))
-}
-
-func toBoolPtr(value bool) uintptr {
-	v := 0
-	if value {
-		v = 1
-	}
-	return uintptr(v)
-}
@@ -0,0 +1,243 @@
+package simconnecttest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+)
+
+// Function IDs of the SimConnect network protocol handled by the server (see simconnect/backend_net.go)
+const (
+	fnOpen                       = 0x01
+	fnMapClientEventToSimEvent   = 0x04
+	fnTransmitClientEvent        = 0x05
+	fnAddToDataDefinition        = 0x0C
+	fnClearDataDefinition        = 0x0D
+	fnRequestDataOnSimObject     = 0x0E
+	fnRequestDataOnSimObjectType = 0x0F
+	fnSetDataOnSimObject         = 0x10
+	fnSubscribeToSystemEvent     = 0x17
+	fnUnsubscribeFromSystemEvent = 0x18
+)
+
+var functionNames = map[uint32]string{
+	0x01: "SimConnect_Open",
+	0x04: "SimConnect_MapClientEventToSimEvent",
+	0x05: "SimConnect_TransmitClientEvent",
+	0x06: "SimConnect_SetSystemEventState",
+	0x07: "SimConnect_AddClientEventToNotificationGroup",
+	0x08: "SimConnect_RemoveClientEvent",
+	0x09: "SimConnect_SetNotificationGroupPriority",
+	0x0A: "SimConnect_ClearNotificationGroup",
+	0x0B: "SimConnect_RequestNotificationGroup",
+	0x0C: "SimConnect_AddToDataDefinition",
+	0x0D: "SimConnect_ClearDataDefinition",
+	0x0E: "SimConnect_RequestDataOnSimObject",
+	0x0F: "SimConnect_RequestDataOnSimObjectType",
+	0x10: "SimConnect_SetDataOnSimObject",
+	0x11: "SimConnect_MapInputEventToClientEvent",
+	0x12: "SimConnect_SetInputGroupPriority",
+	0x13: "SimConnect_RemoveInputEvent",
+	0x14: "SimConnect_ClearInputGroup",
+	0x15: "SimConnect_SetInputGroupState",
+	0x17: "SimConnect_SubscribeToSystemEvent",
+	0x18: "SimConnect_UnsubscribeFromSystemEvent",
+	0x27: "SimConnect_AICreateParkedATCAircraft",
+	0x28: "SimConnect_AICreateEnrouteATCAircraft",
+	0x29: "SimConnect_AICreateNonATCAircraft",
+	0x2A: "SimConnect_AICreateSimulatedObject",
+	0x2B: "SimConnect_AIReleaseControl",
+	0x2C: "SimConnect_AIRemoveObject",
+	0x2D: "SimConnect_AISetAircraftFlightPlan",
+	0x30: "SimConnect_CameraSetRelative6DOF",
+	0x31: "SimConnect_MenuAddItem",
+	0x32: "SimConnect_MenuDeleteItem",
+	0x33: "SimConnect_MenuAddSubItem",
+	0x34: "SimConnect_MenuDeleteSubItem",
+	0x35: "SimConnect_RequestSystemState",
+	0x36: "SimConnect_SetSystemState",
+	0x37: "SimConnect_MapClientDataNameToID",
+	0x38: "SimConnect_CreateClientData",
+	0x39: "SimConnect_AddToClientDataDefinition",
+	0x3A: "SimConnect_ClearClientDataDefinition",
+	0x3B: "SimConnect_RequestClientData",
+	0x3C: "SimConnect_SetClientData",
+	0x3D: "SimConnect_FlightLoad",
+	0x3E: "SimConnect_FlightSave",
+	0x3F: "SimConnect_FlightPlanLoad",
+	0x40: "SimConnect_Text",
+	0x41: "SimConnect_SubscribeToFacilities",
+	0x42: "SimConnect_UnsubscribeToFacilities",
+	0x43: "SimConnect_RequestFacilitiesList",
+}
+
+var stringSizes = map[simconnect.DWord]int{
+	simconnect.DataTypeString8:   8,
+	simconnect.DataTypeString32:  32,
+	simconnect.DataTypeString64:  64,
+	simconnect.DataTypeString128: 128,
+	simconnect.DataTypeString256: 256,
+	simconnect.DataTypeString260: 260,
+}
+
+func encodeValue(w *bytes.Buffer, dataType simconnect.DWord, value interface{}) error {
+	if size, ok := stringSizes[dataType]; ok {
+		str := make([]byte, size)
+		copy(str[:size-1], toString(value))
+		w.Write(str)
+		return nil
+	}
+
+	switch dataType {
+	case simconnect.DataTypeInt32:
+		return binary.Write(w, binary.LittleEndian, int32(toFloat64(value)))
+
+	case simconnect.DataTypeInt64:
+		return binary.Write(w, binary.LittleEndian, int64(toFloat64(value)))
+
+	case simconnect.DataTypeFloat32:
+		return binary.Write(w, binary.LittleEndian, float32(toFloat64(value)))
+
+	case simconnect.DataTypeFloat64:
+		return binary.Write(w, binary.LittleEndian, toFloat64(value))
+
+	case simconnect.DataTypeStringV:
+		w.Write(simconnect.InsertString(nil, toString(value)))
+		return nil
+
+	case simconnect.DataTypeInitPosition:
+		v, _ := value.(simconnect.InitPosition)
+		return binary.Write(w, binary.LittleEndian, v)
+
+	case simconnect.DataTypeMarkerState:
+		v, _ := value.(simconnect.MarkerState)
+		return binary.Write(w, binary.LittleEndian, v)
+
+	case simconnect.DataTypeWaypoint:
+		v, _ := value.(simconnect.Waypoint)
+		return binary.Write(w, binary.LittleEndian, v)
+
+	case simconnect.DataTypeLatLonAlt:
+		v, _ := value.(simconnect.LatLogAlt)
+		return binary.Write(w, binary.LittleEndian, v)
+
+	case simconnect.DataTypeXYZ:
+		v, _ := value.(simconnect.XYZ)
+		return binary.Write(w, binary.LittleEndian, v)
+	}
+	return fmt.Errorf("datatype %d not supported", dataType)
+}
+
+func decodeValue(r *bytes.Reader, dataType simconnect.DWord) (interface{}, error) {
+	if size, ok := stringSizes[dataType]; ok {
+		str := make([]byte, size)
+		if _, err := io.ReadFull(r, str); err != nil {
+			return nil, err
+		}
+		if i := bytes.IndexByte(str, 0); i >= 0 {
+			str = str[:i]
+		}
+		return string(str), nil
+	}
+
+	if dataType == simconnect.DataTypeStringV {
+		rest := make([]byte, r.Len())
+		r.Read(rest)
+		str, size, err := simconnect.RetrieveString(rest)
+		if err != nil {
+			return nil, err
+		}
+		r.Seek(int64(size-len(rest)), io.SeekCurrent)
+		return str, nil
+	}
+
+	var value interface{}
+	switch dataType {
+	case simconnect.DataTypeInt32:
+		value = new(int32)
+	case simconnect.DataTypeInt64:
+		value = new(int64)
+	case simconnect.DataTypeFloat32:
+		value = new(float32)
+	case simconnect.DataTypeFloat64:
+		value = new(float64)
+	case simconnect.DataTypeInitPosition:
+		value = new(simconnect.InitPosition)
+	case simconnect.DataTypeMarkerState:
+		value = new(simconnect.MarkerState)
+	case simconnect.DataTypeWaypoint:
+		value = new(simconnect.Waypoint)
+	case simconnect.DataTypeLatLonAlt:
+		value = new(simconnect.LatLogAlt)
+	case simconnect.DataTypeXYZ:
+		value = new(simconnect.XYZ)
+	default:
+		return nil, fmt.Errorf("datatype %d not supported", dataType)
+	}
+	if err := binary.Read(r, binary.LittleEndian, value); err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case *int32:
+		return *v, nil
+	case *int64:
+		return *v, nil
+	case *float32:
+		return *v, nil
+	case *float64:
+		return *v, nil
+	case *simconnect.InitPosition:
+		return *v, nil
+	case *simconnect.MarkerState:
+		return *v, nil
+	case *simconnect.Waypoint:
+		return *v, nil
+	case *simconnect.LatLogAlt:
+		return *v, nil
+	case *simconnect.XYZ:
+		return *v, nil
+	}
+	return value, nil
+}
+
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case simconnect.DWord:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		if v == math.Trunc(v) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,710 @@
+// Package simconnecttest provides an in-process fake simulator for integration tests.
+//
+// The Server speaks the SimConnect network protocol on a loopback TCP port, so any
+// SimConnect or SimMate using the NetBackend returned by Server.Backend talks to it
+// exactly like it would talk to the real thing. The test controls the simvar values
+// and decides when events, exceptions and periodic data are sent.
+package simconnecttest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+)
+
+const (
+	packetHeaderSize = 16
+	packetMaxSize    = 1 << 24
+	stringSize       = 256
+
+	// DefaultFrameRate is the number of Step calls per simulated second.
+	DefaultFrameRate = 30
+)
+
+// Call is a single SimConnect function call received by the server.
+type Call struct {
+	Name   string
+	SendID simconnect.DWord
+	Args   []interface{}
+}
+
+// TransmittedEvent is a client event sent with SimConnect_TransmitClientEvent.
+type TransmittedEvent struct {
+	ObjectID simconnect.DWord
+	Name     string
+	Data     simconnect.DWord
+	GroupID  simconnect.DWord
+	Flags    simconnect.DWord
+}
+
+// Server is a fake simulator. Create it with NewServer and Close it when done.
+type Server struct {
+	ApplicationName string
+	FrameRate       int
+	OnTransmit      func(event TransmittedEvent)
+
+	listener net.Listener
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	vars     map[string]interface{}
+	clients  []*client
+	calls    []Call
+	events   []TransmittedEvent
+	frame    int
+	closed   bool
+}
+
+type datum struct {
+	name     string
+	unit     string
+	dataType simconnect.DWord
+	epsilon  float32
+	id       simconnect.DWord
+}
+
+type request struct {
+	requestID simconnect.DWord
+	defineID  simconnect.DWord
+	objectID  simconnect.DWord
+	period    simconnect.DWord
+	flags     simconnect.DWord
+	last      []byte
+	lastDatum [][]byte // tagged requests compare datum by datum
+}
+
+// client is a connection to the server. Replies are queued and written by the writer goroutine of the client,
+// so a client which stops reading never blocks the server.
+type client struct {
+	conn         net.Conn
+	queueMutex   sync.Mutex
+	queueCond    *sync.Cond
+	queue        [][]byte
+	closed       bool
+	definitions  map[simconnect.DWord][]datum
+	clientEvents map[simconnect.DWord]string
+	systemEvents map[string][]simconnect.DWord // event IDs per lower case system event name, in order of subscription
+	requests     map[simconnect.DWord]*request
+}
+
+// NewServer starts a fake simulator listening on a random loopback port.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ApplicationName: "SimConnect Test Server",
+		FrameRate:       DefaultFrameRate,
+		listener:        listener,
+		vars:            make(map[string]interface{}),
+		clients:         make([]*client, 0),
+		calls:           make([]Call, 0),
+		events:          make([]TransmittedEvent, 0),
+	}
+	s.cond = sync.NewCond(&s.mutex)
+	go s.accept()
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Backend returns a new NetBackend connecting to this server.
+func (s *Server) Backend() *simconnect.NetBackend {
+	return simconnect.NewNetBackend("tcp", s.Addr())
+}
+
+// Close stops the server and drops all client connections.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	clients := s.clients
+	s.clients = nil
+	s.cond.Broadcast()
+	s.mutex.Unlock()
+	for _, c := range clients {
+		c.close()
+	}
+	return s.listener.Close()
+}
+
+// SetSimVar sets the value of a simvar. Names are case-insensitive.
+// Numbers may be given as any Go numeric type, strings as string and structured datatypes as their simconnect struct.
+func (s *Server) SetSimVar(name string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.vars[normalizeName(name)] = value
+}
+
+// SimVar returns the current value of a simvar, including values written by clients via SetDataOnSimObject.
+func (s *Server) SimVar(name string) (interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, ok := s.vars[normalizeName(name)]
+	return value, ok
+}
+
+// Calls returns all function calls received so far.
+func (s *Server) Calls() []Call {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	calls := make([]Call, len(s.calls))
+	copy(calls, s.calls)
+	return calls
+}
+
+// TransmittedEvents returns all client events transmitted so far.
+func (s *Server) TransmittedEvents() []TransmittedEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := make([]TransmittedEvent, len(s.events))
+	copy(events, s.events)
+	return events
+}
+
+// WaitForCalls blocks until the server received at least count calls of the named function
+// (e.g. "SimConnect_AddToDataDefinition") or the timeout expired.
+func (s *Server) WaitForCalls(name string, count int, timeout time.Duration) bool {
+	timer := time.AfterFunc(timeout, func() {
+		s.mutex.Lock()
+		s.cond.Broadcast()
+		s.mutex.Unlock()
+	})
+	defer timer.Stop()
+
+	deadline := time.Now().Add(timeout)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for {
+		n := 0
+		for _, call := range s.calls {
+			if call.Name == name {
+				n++
+			}
+		}
+		if n >= count {
+			return true
+		}
+		if s.closed || !time.Now().Before(deadline) {
+			return false
+		}
+		s.cond.Wait()
+	}
+}
+
+// FireSystemEvent sends a RecvEvent for every subscription to the named system event (e.g. "Pause").
+func (s *Server) FireSystemEvent(name string, data simconnect.DWord) {
+	s.forEachSubscription(name, func(c *client, eventID simconnect.DWord) {
+		c.send(simconnect.RecvIDEvent, simconnect.Unused, eventID, data)
+	})
+}
+
+// FireFilenameEvent sends a RecvEventFilename for every subscription to the named system event,
+// e.g. "FlightLoaded" or "AircraftLoaded".
+func (s *Server) FireFilenameEvent(name, filename string, flags simconnect.DWord) {
+	s.forEachSubscription(name, func(c *client, eventID simconnect.DWord) {
+		event := simconnect.RecvEventFilename{
+			RecvEvent: simconnect.RecvEvent{GroupID: simconnect.Unused, EventID: eventID},
+			Flags:     flags,
+		}
+		copy(event.FileName[:len(event.FileName)-1], filename)
+		c.sendStruct(simconnect.RecvIDEventFilename, &event)
+	})
+}
+
+// FireFrameEvent sends a RecvEventFrame for every subscription to the named system event, i.e. "Frame" or "PauseFrame".
+func (s *Server) FireFrameEvent(name string, frameRate, simSpeed float32) {
+	s.forEachSubscription(name, func(c *client, eventID simconnect.DWord) {
+		c.sendStruct(simconnect.RecvIDEventFrame, &simconnect.RecvEventFrame{
+			RecvEvent: simconnect.RecvEvent{GroupID: simconnect.Unused, EventID: eventID},
+			FrameRate: frameRate,
+			SimSpeed:  simSpeed,
+		})
+	})
+}
+
+// FireObjectEvent sends a RecvEventObjectAddRemove for every subscription to the named system event,
+// i.e. "ObjectAdded" or "ObjectRemoved". The object ID is sent as the event data.
+func (s *Server) FireObjectEvent(name string, objectID, objectType simconnect.DWord) {
+	s.forEachSubscription(name, func(c *client, eventID simconnect.DWord) {
+		c.sendStruct(simconnect.RecvIDEventObjectAddRemove, &simconnect.RecvEventObjectAddRemove{
+			RecvEvent: simconnect.RecvEvent{GroupID: simconnect.Unused, EventID: eventID, Data: objectID},
+			ObjType:   objectType,
+		})
+	})
+}
+
+// FireClientEvent sends a RecvEvent to every client which mapped the named sim event (e.g. "GEAR_TOGGLE").
+func (s *Server) FireClientEvent(name string, data simconnect.DWord) {
+	s.forEachClient(func(c *client) {
+		for eventID, eventName := range c.clientEvents {
+			if strings.EqualFold(eventName, name) {
+				c.send(simconnect.RecvIDEvent, simconnect.Unused, eventID, data)
+			}
+		}
+	})
+}
+
+// SendException sends a RecvException to every client.
+func (s *Server) SendException(exception, sendID, index simconnect.DWord) {
+	s.forEachClient(func(c *client) {
+		c.send(simconnect.RecvIDException, exception, sendID, index)
+	})
+}
+
+// Quit sends a RecvQuit to every client.
+func (s *Server) Quit() {
+	s.forEachClient(func(c *client) {
+		c.send(simconnect.RecvIDQuit)
+	})
+}
+
+// Step advances the simulation by one frame and serves all periodic data requests which are due.
+func (s *Server) Step() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.frame++
+	for _, c := range s.clients {
+		for _, req := range c.requests {
+			switch req.period {
+			case simconnect.PeriodSimFrame, simconnect.PeriodVisualFrame:
+			case simconnect.PeriodSecond:
+				if s.FrameRate > 0 && s.frame%s.FrameRate != 0 {
+					continue
+				}
+			default:
+				continue
+			}
+			s.serveRequest(c, req)
+		}
+	}
+}
+
+func (s *Server) forEachClient(fn func(c *client)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.clients {
+		fn(c)
+	}
+}
+
+// forEachSubscription calls fn with every event ID subscribed to the named system event.
+func (s *Server) forEachSubscription(name string, fn func(c *client, eventID simconnect.DWord)) {
+	s.forEachClient(func(c *client) {
+		for _, eventID := range c.systemEvents[strings.ToLower(name)] {
+			fn(c, eventID)
+		}
+	})
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := &client{
+			conn:         conn,
+			definitions:  make(map[simconnect.DWord][]datum),
+			clientEvents: make(map[simconnect.DWord]string),
+			systemEvents: make(map[string][]simconnect.DWord),
+			requests:     make(map[simconnect.DWord]*request),
+		}
+		c.queueCond = sync.NewCond(&c.queueMutex)
+		s.mutex.Lock()
+		s.clients = append(s.clients, c)
+		s.mutex.Unlock()
+		go c.writeLoop()
+		go s.serve(c)
+	}
+}
+
+func (s *Server) serve(c *client) {
+	defer s.removeClient(c)
+	for {
+		packet, err := readPacket(c.conn)
+		if err != nil {
+			return
+		}
+		if err := s.handle(c, packet); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) removeClient(c *client) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, other := range s.clients {
+		if other == c {
+			s.clients = append(s.clients[:i], s.clients[i+1:]...)
+			break
+		}
+	}
+	c.close()
+}
+
+func (s *Server) handle(c *client, packet []byte) error {
+	functionID := binary.LittleEndian.Uint32(packet[8:]) &^ 0xF0000000
+	sendID := simconnect.DWord(binary.LittleEndian.Uint32(packet[12:]))
+	r := &payloadReader{data: packet[packetHeaderSize:]}
+
+	s.mutex.Lock()
+	var transmitted *TransmittedEvent
+
+	call := Call{Name: functionNames[functionID], SendID: sendID}
+	if call.Name == "" {
+		call.Name = fmt.Sprintf("0x%02X", functionID)
+	}
+
+	switch functionID {
+	case fnOpen:
+		name := r.string(stringSize)
+		call.Args = []interface{}{name}
+		open := simconnect.RecvOpen{
+			SimConnectVersionMajor:  11,
+			ApplicationVersionMajor: 11,
+		}
+		copy(open.ApplicationName[:len(open.ApplicationName)-1], s.ApplicationName)
+		c.sendStruct(simconnect.RecvIDOpen, &open)
+
+	case fnMapClientEventToSimEvent:
+		eventID, name := r.dword(), r.string(stringSize)
+		call.Args = []interface{}{eventID, name}
+		c.clientEvents[eventID] = name
+
+	case fnTransmitClientEvent:
+		event := TransmittedEvent{ObjectID: r.dword()}
+		eventID := r.dword()
+		event.Name = c.clientEvents[eventID]
+		event.Data, event.GroupID, event.Flags = r.dword(), r.dword(), r.dword()
+		call.Args = []interface{}{event.ObjectID, eventID, event.Data, event.GroupID, event.Flags}
+		s.events = append(s.events, event)
+		transmitted = &event
+
+	case fnSubscribeToSystemEvent:
+		eventID, name := r.dword(), r.string(stringSize)
+		call.Args = []interface{}{eventID, name}
+		key := strings.ToLower(name)
+		c.systemEvents[key] = append(c.systemEvents[key], eventID)
+
+	case fnUnsubscribeFromSystemEvent:
+		eventID := r.dword()
+		call.Args = []interface{}{eventID}
+		for name, ids := range c.systemEvents {
+			for i, id := range ids {
+				if id == eventID {
+					c.systemEvents[name] = append(ids[:i:i], ids[i+1:]...)
+					break
+				}
+			}
+		}
+
+	case fnAddToDataDefinition:
+		defineID, name, unit, dataType := r.dword(), r.string(stringSize), r.string(stringSize), r.dword()
+		epsilon, datumID := r.float32(), r.dword()
+		call.Args = []interface{}{defineID, name, unit, dataType, epsilon, datumID}
+		c.definitions[defineID] = append(c.definitions[defineID], datum{name, unit, dataType, epsilon, datumID})
+
+	case fnClearDataDefinition:
+		defineID := r.dword()
+		call.Args = []interface{}{defineID}
+		delete(c.definitions, defineID)
+
+	case fnRequestDataOnSimObject:
+		req := &request{requestID: r.dword(), defineID: r.dword(), objectID: r.dword(), period: r.dword(), flags: r.dword()}
+		call.Args = []interface{}{req.requestID, req.defineID, req.objectID, req.period, req.flags}
+		if !s.checkDefinition(c, req.defineID, sendID) {
+			break
+		}
+		switch req.period {
+		case simconnect.PeriodNever:
+			delete(c.requests, req.requestID)
+		case simconnect.PeriodOnce:
+			s.serveRequest(c, req)
+		default:
+			c.requests[req.requestID] = req
+		}
+
+	case fnRequestDataOnSimObjectType:
+		req := &request{requestID: r.dword(), defineID: r.dword(), objectID: simconnect.ObjectIDUser}
+		radius, objectType := r.dword(), r.dword()
+		call.Args = []interface{}{req.requestID, req.defineID, radius, objectType}
+		if !s.checkDefinition(c, req.defineID, sendID) {
+			break
+		}
+		data, err := s.encodeDefinition(c.definitions[req.defineID])
+		if err != nil {
+			c.send(simconnect.RecvIDException, simconnect.ExceptionDataError, sendID, 0)
+			break
+		}
+		c.sendData(simconnect.RecvIDSimObjectDataByType, req, datumCount(c.definitions[req.defineID]), data)
+
+	case fnSetDataOnSimObject:
+		defineID, objectID, flags, arrayCount, unitSize := r.dword(), r.dword(), r.dword(), r.dword(), r.dword()
+		data := r.rest()
+		call.Args = []interface{}{defineID, objectID, flags, arrayCount, unitSize, data}
+		if !s.checkDefinition(c, defineID, sendID) {
+			break
+		}
+		if err := s.decodeDefinition(c.definitions[defineID], arrayCount, data); err != nil {
+			c.send(simconnect.RecvIDException, simconnect.ExceptionDataError, sendID, 0)
+		}
+
+	default:
+		call.Args = []interface{}{r.rest()}
+	}
+
+	s.calls = append(s.calls, call)
+	onTransmit := s.OnTransmit
+	s.cond.Broadcast()
+	s.mutex.Unlock()
+
+	if transmitted != nil && onTransmit != nil {
+		onTransmit(*transmitted)
+	}
+	return r.err
+}
+
+func (s *Server) checkDefinition(c *client, defineID, sendID simconnect.DWord) bool {
+	if _, ok := c.definitions[defineID]; !ok {
+		c.send(simconnect.RecvIDException, simconnect.ExceptionUnrecognizedID, sendID, 1)
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveRequest(c *client, req *request) {
+	datums := c.definitions[req.defineID]
+	if req.flags&simconnect.DataRequestFlagTagged != 0 {
+		s.serveTaggedRequest(c, req, datums)
+		return
+	}
+	data, err := s.encodeDefinition(datums)
+	if err != nil {
+		return
+	}
+	if req.flags&simconnect.DataRequestFlagChanged != 0 && req.last != nil && bytes.Equal(req.last, data) {
+		return
+	}
+	req.last = data
+	c.sendData(simconnect.RecvIDSimobjectData, req, datumCount(datums), data)
+}
+
+// serveTaggedRequest sends pairs of datum ID and value. With DataRequestFlagChanged only the datums which changed are sent.
+func (s *Server) serveTaggedRequest(c *client, req *request, datums []datum) {
+	if len(req.lastDatum) != len(datums) {
+		req.lastDatum = make([][]byte, len(datums))
+	}
+	changedOnly := req.flags&simconnect.DataRequestFlagChanged != 0
+	buf := new(bytes.Buffer)
+	count := simconnect.DWord(0)
+	for i, d := range datums {
+		value, err := s.encodeDefinition(datums[i : i+1])
+		if err != nil {
+			return
+		}
+		if changedOnly && req.lastDatum[i] != nil && bytes.Equal(req.lastDatum[i], value) {
+			continue
+		}
+		req.lastDatum[i] = value
+		binary.Write(buf, binary.LittleEndian, d.id)
+		buf.Write(value)
+		count++
+	}
+	if count == 0 && changedOnly {
+		return
+	}
+	c.sendData(simconnect.RecvIDSimobjectData, req, count, buf.Bytes())
+}
+
+func datumCount(datums []datum) simconnect.DWord {
+	return simconnect.DWord(len(datums))
+}
+
+func (s *Server) encodeDefinition(datums []datum) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for _, d := range datums {
+		if err := encodeValue(buf, d.dataType, s.vars[normalizeName(d.name)]); err != nil {
+			return nil, fmt.Errorf("%s: %v", d.name, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeDefinition stores the values of the datums. If more than one element was sent, e.g. a waypoint list,
+// each simvar is set to a []interface{} holding one value per element.
+func (s *Server) decodeDefinition(datums []datum, arrayCount simconnect.DWord, data []byte) error {
+	r := bytes.NewReader(data)
+	if arrayCount <= 1 {
+		for _, d := range datums {
+			value, err := decodeValue(r, d.dataType)
+			if err != nil {
+				return fmt.Errorf("%s: %v", d.name, err)
+			}
+			s.vars[normalizeName(d.name)] = value
+		}
+		return nil
+	}
+	elements := make([][]interface{}, len(datums))
+	for i := simconnect.DWord(0); i < arrayCount; i++ {
+		for j, d := range datums {
+			value, err := decodeValue(r, d.dataType)
+			if err != nil {
+				return fmt.Errorf("%s[%d]: %v", d.name, i, err)
+			}
+			elements[j] = append(elements[j], value)
+		}
+	}
+	for j, d := range datums {
+		s.vars[normalizeName(d.name)] = elements[j]
+	}
+	return nil
+}
+
+func (c *client) send(id simconnect.DWord, fields ...simconnect.DWord) {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, simconnect.Recv{ID: id})
+	binary.Write(buf, binary.LittleEndian, fields)
+	c.write(buf.Bytes())
+}
+
+func (c *client) sendStruct(id simconnect.DWord, value interface{}) {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, value)
+	packet := buf.Bytes()
+	binary.LittleEndian.PutUint32(packet[8:], uint32(id))
+	c.write(packet)
+}
+
+func (c *client) sendData(id simconnect.DWord, req *request, defineCount simconnect.DWord, data []byte) {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, simconnect.RecvSimObjectData{
+		Recv:        simconnect.Recv{ID: id},
+		RequestID:   req.requestID,
+		ObjectID:    req.objectID,
+		DefineID:    req.defineID,
+		Flags:       req.flags,
+		EntryNumber: 1,
+		OutOf:       1,
+		DefineCount: defineCount,
+	})
+	buf.Write(data)
+	c.write(buf.Bytes())
+}
+
+// write queues a packet for the writer goroutine. It never blocks, so it may be called with the server locked.
+func (c *client) write(packet []byte) {
+	binary.LittleEndian.PutUint32(packet[0:], uint32(len(packet)))
+	binary.LittleEndian.PutUint32(packet[4:], 4)
+	c.queueMutex.Lock()
+	defer c.queueMutex.Unlock()
+	if c.closed {
+		return
+	}
+	c.queue = append(c.queue, packet)
+	c.queueCond.Signal()
+}
+
+// writeLoop writes the queued packets in order until the client is closed. A failed write closes the client,
+// which ends its serve loop as well.
+func (c *client) writeLoop() {
+	for {
+		c.queueMutex.Lock()
+		for len(c.queue) == 0 && !c.closed {
+			c.queueCond.Wait()
+		}
+		if c.closed {
+			c.queueMutex.Unlock()
+			return
+		}
+		packet := c.queue[0]
+		c.queue = c.queue[1:]
+		c.queueMutex.Unlock()
+		if _, err := c.conn.Write(packet); err != nil {
+			c.close()
+			return
+		}
+	}
+}
+
+// close drops the queued packets and closes the connection.
+func (c *client) close() {
+	c.queueMutex.Lock()
+	c.closed = true
+	c.queue = nil
+	c.queueCond.Broadcast()
+	c.queueMutex.Unlock()
+	c.conn.Close()
+}
+
+func readPacket(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(header[:])
+	if size < packetHeaderSize || size > packetMaxSize {
+		return nil, fmt.Errorf("invalid packet size: %d", size)
+	}
+	packet := make([]byte, size)
+	copy(packet, header[:])
+	if _, err := io.ReadFull(r, packet[4:]); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+var errShortPacket = errors.New("short packet")
+
+type payloadReader struct {
+	data []byte
+	err  error
+}
+
+func (r *payloadReader) next(n int) []byte {
+	if r.err != nil || len(r.data) < n {
+		r.err = errShortPacket
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *payloadReader) dword() simconnect.DWord {
+	return simconnect.DWord(binary.LittleEndian.Uint32(r.next(4)))
+}
+
+func (r *payloadReader) float32() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(r.next(4)))
+}
+
+func (r *payloadReader) string(size int) string {
+	b := r.next(size)
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+func (r *payloadReader) rest() []byte {
+	b := r.data
+	r.data = nil
+	return b
+}
+
+func normalizeName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
@@ -0,0 +1,186 @@
+package simconnecttest
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect"
+)
+
+// connect opens a client on the server and consumes its RecvOpen.
+func connect(t *testing.T) (*Server, *simconnect.SimConnect) {
+	t.Helper()
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	simco := simconnect.NewSimConnectWithBackend(srv.Backend())
+	if err := simco.Open("test"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { simco.Close() })
+	if _, ok := next(t, simco).(*simconnect.RecvOpen); !ok {
+		t.Fatal("first message isn't RecvOpen")
+	}
+	return srv, simco
+}
+
+// next waits for the next message and returns it decoded.
+func next(t *testing.T, simco *simconnect.SimConnect) interface{} {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		msg, err := simco.NextMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == nil {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		value, err := simconnect.Decode(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	t.Fatal("no message")
+	return nil
+}
+
+func TestSimObjectDataByType(t *testing.T) {
+	srv, simco := connect(t)
+	srv.SetSimVar("PLANE ALTITUDE", 1234.5)
+	if err := simco.AddToDataDefinition(1, "PLANE ALTITUDE", "feet", simconnect.DataTypeFloat64); err != nil {
+		t.Fatal(err)
+	}
+	if err := simco.RequestDataOnSimObjectType(7, 1, 0, simconnect.SimObjectTypeUser); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := next(t, simco).(*simconnect.ObjectDataByType)
+	if !ok {
+		t.Fatal("expected ObjectDataByType")
+	}
+	if data.RequestID != 7 || data.DefineID != 1 || data.DefineCount != 1 {
+		t.Errorf("header = %+v", data.RecvSimObjectData)
+	}
+	value, err := simconnect.DecodeValue(data.Data, simconnect.DataTypeFloat64)
+	if err != nil || value != 1234.5 {
+		t.Errorf("value = %v, %v", value, err)
+	}
+}
+
+func TestUnknownDefinition(t *testing.T) {
+	_, simco := connect(t)
+	if err := simco.RequestDataOnSimObjectType(1, 99, 0, simconnect.SimObjectTypeUser); err != nil {
+		t.Fatal(err)
+	}
+	exception, ok := next(t, simco).(*simconnect.RecvException)
+	if !ok || exception.Exception != simconnect.ExceptionUnrecognizedID {
+		t.Fatalf("got %+v, want ExceptionUnrecognizedID", exception)
+	}
+}
+
+func TestSystemEventSubscriptions(t *testing.T) {
+	srv, simco := connect(t)
+	simco.SubscribeToSystemEvent(10, "Pause")
+	simco.SubscribeToSystemEvent(11, "pause")
+	simco.SubscribeToSystemEvent(12, "Pause")
+	simco.UnsubscribeFromSystemEvent(11)
+	if !srv.WaitForCalls("SimConnect_UnsubscribeFromSystemEvent", 1, time.Second) {
+		t.Fatal("server got no unsubscribe request")
+	}
+
+	srv.FireSystemEvent("PAUSE", 1)
+	for _, want := range []simconnect.DWord{10, 12} {
+		event, ok := next(t, simco).(*simconnect.RecvEvent)
+		if !ok || event.EventID != want || event.Data != 1 {
+			t.Fatalf("got %+v, want event %d", event, want)
+		}
+	}
+}
+
+func TestTypedSystemEvents(t *testing.T) {
+	srv, simco := connect(t)
+	simco.SubscribeToSystemEvent(1, "FlightLoaded")
+	simco.SubscribeToSystemEvent(2, "Frame")
+	simco.SubscribeToSystemEvent(3, "ObjectAdded")
+	if !srv.WaitForCalls("SimConnect_SubscribeToSystemEvent", 3, time.Second) {
+		t.Fatal("server got no subscriptions")
+	}
+
+	srv.FireFilenameEvent("FlightLoaded", `flights\other\test.FLT`, 2)
+	filename, ok := next(t, simco).(*simconnect.RecvEventFilename)
+	if !ok {
+		t.Fatal("expected RecvEventFilename")
+	}
+	if filename.EventID != 1 || filename.Flags != 2 || simconnect.CString(filename.FileName[:]) != `flights\other\test.FLT` {
+		t.Errorf("got %+v", filename)
+	}
+
+	srv.FireFrameEvent("Frame", 60, 1)
+	frame, ok := next(t, simco).(*simconnect.RecvEventFrame)
+	if !ok || frame.EventID != 2 || frame.FrameRate != 60 || frame.SimSpeed != 1 {
+		t.Errorf("got %+v", frame)
+	}
+
+	srv.FireObjectEvent("ObjectAdded", 42, simconnect.SimObjectTypeAircraft)
+	object, ok := next(t, simco).(*simconnect.RecvEventObjectAddRemove)
+	if !ok || object.EventID != 3 || object.Data != 42 || object.ObjType != simconnect.SimObjectTypeAircraft {
+		t.Errorf("got %+v", object)
+	}
+}
+
+func TestQuit(t *testing.T) {
+	srv, simco := connect(t)
+	srv.Quit()
+	if _, ok := next(t, simco).(*simconnect.RecvQuit); !ok {
+		t.Fatal("expected RecvQuit")
+	}
+}
+
+func TestClientWhichDoesNotRead(t *testing.T) {
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	conn, err := net.Dial("tcp", srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	packet := make([]byte, packetHeaderSize+4+stringSize)
+	binary.LittleEndian.PutUint32(packet[0:], uint32(len(packet)))
+	binary.LittleEndian.PutUint32(packet[4:], 4)
+	binary.LittleEndian.PutUint32(packet[8:], 0xF0000000|fnSubscribeToSystemEvent)
+	binary.LittleEndian.PutUint32(packet[packetHeaderSize:], 1)
+	copy(packet[packetHeaderSize+4:], "FlightLoaded")
+	if _, err := conn.Write(packet); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.WaitForCalls("SimConnect_SubscribeToSystemEvent", 1, time.Second) {
+		t.Fatal("server got no subscription")
+	}
+
+	// far more than the socket buffers hold, the client never reads any of it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20000; i++ {
+			srv.FireFilenameEvent("FlightLoaded", "flights\\other\\MainMenu.FLT", 0)
+		}
+		srv.Step()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sending to a client which doesn't read blocks the server")
+	}
+	if calls := srv.Calls(); len(calls) != 1 {
+		t.Errorf("got %d calls, want 1", len(calls))
+	}
+}
@@ -1,8 +1,10 @@
 package simconnect
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 	"unsafe"
@@ -20,45 +22,198 @@ type OnSimObjectDataFunc func(data *RecvSimObjectData)
 type OnSimObjectDataByTypeFunc func(data *RecvSimObjectDataByType)
 type OnDataReadyFunc func()
 type OnEventIDFunc func(eventID DWord)
+type OnEventFunc func(name string, data DWord)
 type OnExceptionFunc func(exceptionCode DWord)
+type OnEventObjectAddRemoveFunc func(event *RecvEventObjectAddRemove)
+type OnEventFilenameFunc func(event *RecvEventFilename)
+type OnEventFrameFunc func(event *RecvEventFrame)
+type OnWeatherObservationFunc func(observation *WeatherObservation)
+type OnCloudStateFunc func(state *CloudState)
+type OnAssignedObjectIDFunc func(assigned *RecvAssignedObjectID)
+type OnReservedKeyFunc func(key *RecvReservedKey)
+type OnCustomActionFunc func(action *CustomAction)
+type OnSystemStateFunc func(state *RecvSystemState)
+type OnClientDataFunc func(data *ClientData)
+type OnEventWeatherModeFunc func(event *RecvEventWeatherMode)
+type OnAirportListFunc func(list *AirportList)
+type OnVORListFunc func(list *VORList)
+type OnNDBListFunc func(list *NDBList)
+type OnWaypointListFunc func(list *WaypointList)
+type OnEventMultiplayerServerStartedFunc func(event *RecvEventMultiplayerServerStarted)
+type OnEventMultiplayerClientStartedFunc func(event *RecvEventMultiplayerClientStarted)
+type OnEventMultiplayerSessionEndedFunc func(event *RecvEventMultiplayerSessionEnded)
+type OnEventRaceEndFunc func(event *RecvEventRaceEnd)
+type OnEventRaceLapFunc func(event *RecvEventRaceLap)
 
 type EventListener struct {
 	OnOpen                OnOpenFunc
 	OnQuit                OnQuitFunc
 	OnSimObjectData       OnSimObjectDataFunc
 	OnSimObjectDataByType OnSimObjectDataByTypeFunc
-	OnDataReady           OnDataReadyFunc
+	OnDataReady           OnDataReadyFunc // called with every new snapshot, see SimMate.Snapshot
 	OnEventID             OnEventIDFunc
+	OnEvent               OnEventFunc // called for events mapped by SimMate.Events, with the event name
 	OnException           OnExceptionFunc
+
+	OnEventObjectAddRemove          OnEventObjectAddRemoveFunc
+	OnEventFilename                 OnEventFilenameFunc
+	OnEventFrame                    OnEventFrameFunc
+	OnWeatherObservation            OnWeatherObservationFunc
+	OnCloudState                    OnCloudStateFunc
+	OnAssignedObjectID              OnAssignedObjectIDFunc
+	OnReservedKey                   OnReservedKeyFunc
+	OnCustomAction                  OnCustomActionFunc
+	OnSystemState                   OnSystemStateFunc
+	OnClientData                    OnClientDataFunc
+	OnEventWeatherMode              OnEventWeatherModeFunc
+	OnAirportList                   OnAirportListFunc
+	OnVORList                       OnVORListFunc
+	OnNDBList                       OnNDBListFunc
+	OnWaypointList                  OnWaypointListFunc
+	OnEventMultiplayerServerStarted OnEventMultiplayerServerStartedFunc
+	OnEventMultiplayerClientStarted OnEventMultiplayerClientStartedFunc
+	OnEventMultiplayerSessionEnded  OnEventMultiplayerSessionEndedFunc
+	OnEventRaceEnd                  OnEventRaceEndFunc
+	OnEventRaceLap                  OnEventRaceLapFunc
 }
 
 type SimMate struct {
 	SimConnect
-	simVarManager *SimVarManager
-	mutex         sync.Mutex
-	dirty         bool
+	// MaxInFlight limits the number of poll requests awaiting data, 0 means no limit.
+	// Due requests beyond the limit wait for a later tick, the ones with the highest priority first.
+	MaxInFlight     int
+	simVarManager   *SimVarManager
+	events          *EventRegistry
+	systemEvents    *SystemEvents
+	mutex           sync.Mutex
+	dirty           bool
+	blocks          map[DWord]*simVarBlock
+	watchers        map[DWord][]simVarWatcher
+	histories       map[DWord]*History
+	varRefs         map[DWord]int // number of Var handles to simvars created by AddVar, see Var.Remove
+	derived         []*derivedVar // in order of addition
+	dependents      map[DWord][]*derivedVar
+	watcherID       int
+	setMutex        sync.Mutex
+	setDefines      map[setDefinition]DWord
+	slot            int            // spreads blocks over the ticks of their interval, see simVarBlock.schedule
+	round           map[DWord]bool // blocks polled on the last tick whose data is outstanding
+	changed         bool           // a simvar was updated since the last snapshot
+	sequence        uint64
+	snapshot        *Snapshot
+	snapshotWatches []*snapshotWatch
+}
+
+// setDefinition identifies a data definition used by SetSimObjectData.
+type setDefinition struct {
+	name     string
+	unit     string
+	dataType DWord
+}
+
+type simVarWatcher struct {
+	id int
+	fn func(value interface{}, sampled time.Time)
 }
 
 func NewSimMate() *SimMate {
 	if !initialized {
 		Initialize("")
 	}
+	return NewSimMateWithBackend(&dllBackend{})
+}
+
+// NewSimMateWithBackend creates a SimMate on top of the given backend, e.g. a ScriptedBackend.
+func NewSimMateWithBackend(backend Backend) *SimMate {
 	mate := &SimMate{
+		SimConnect:    *NewSimConnectWithBackend(backend),
 		simVarManager: NewSimVarManager(),
+		blocks:        make(map[DWord]*simVarBlock),
+		watchers:      make(map[DWord][]simVarWatcher),
+		histories:     make(map[DWord]*History),
+		varRefs:       make(map[DWord]int),
+		dependents:    make(map[DWord][]*derivedVar),
+		setDefines:    make(map[setDefinition]DWord),
 	}
+	mate.events = NewEventRegistry(&mate.SimConnect)
+	mate.systemEvents = NewSystemEvents(&mate.SimConnect)
 	return mate
 }
 
-func (mate *SimMate) AddSimVar(name, unit string, dataType DWord) DWord {
-	defineID := mate.simVarManager.Add(name, unit, dataType)
-	mate.dirty = true
+// AddSimVar adds a simvar and returns its define ID, which serves as the handle to the simvar.
+// SimMate packs the simvars into shared data definitions, see SimVar.BlockID.
+// By default simvars are polled every requestDataInterval, use WithPeriod or WithOnChange to subscribe instead.
+// If the simvar was already added, its define ID is returned and the options are ignored.
+// A new simvar is checked against the catalog unless SkipValidation is set. A rejected simvar is logged and 0 is returned,
+// use TryAddSimVar to get the error instead.
+func (mate *SimMate) AddSimVar(name, unit string, dataType DWord, opts ...SimVarOption) DWord {
+	defineID, err := mate.TryAddSimVar(name, unit, dataType, opts...)
+	if err != nil {
+		log.Warnf("AddSimVar: %s", err.Error())
+		return 0
+	}
 	return defineID
 }
 
+// TryAddSimVar works like AddSimVar, but returns the error if the catalog rejects the simvar.
+func (mate *SimMate) TryAddSimVar(name, unit string, dataType DWord, opts ...SimVarOption) (DWord, error) {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	defineID, _, err := mate.addSimVar(name, unit, dataType, opts...)
+	return defineID, err
+}
+
+// addSimVar adds the simvar unless it exists, created reports whether it was added. The caller holds mate.mutex.
+func (mate *SimMate) addSimVar(name, unit string, dataType DWord, opts ...SimVarOption) (defineID DWord, created bool, err error) {
+	if simVar, exists := mate.simVarManager.simVarWithName(name); exists {
+		return simVar.DefineID, false, nil
+	}
+	if err := mate.validateSimVar(name, unit, dataType); err != nil {
+		return 0, false, err
+	}
+	defineID = mate.simVarManager.Add(name, unit, dataType)
+	simVar, _ := mate.simVarManager.GetSimVar(defineID)
+	for _, opt := range opts {
+		opt(simVar)
+	}
+	if simVar.Flags&DataRequestFlagChanged != 0 && simVar.Period == PeriodNever {
+		simVar.Period = PeriodSimFrame
+	}
+	if simVar.HistorySize > 0 {
+		mate.histories[defineID] = NewHistory(simVar.HistorySize)
+	}
+	mate.dirty = true
+	return defineID, true, nil
+}
+
+// RemoveSimVar removes a simvar and reports whether it was removed.
+// An input of a derived simvar isn't removed, remove the derived simvar first.
 func (mate *SimMate) RemoveSimVar(defineID DWord) bool {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	return mate.removeSimVar(defineID)
+}
+
+// removeSimVar removes the simvar, the caller holds mate.mutex.
+func (mate *SimMate) removeSimVar(defineID DWord) bool {
+	simVar, exists := mate.simVarManager.GetSimVar(defineID)
+	if !exists {
+		return false
+	}
+	if dependents := mate.dependents[defineID]; len(dependents) > 0 {
+		log.Warnf("RemoveSimVar: %s is an input of %s, remove that first", simVar.Name, dependents[0].simVar.Name)
+		return false
+	}
 	if ok := mate.simVarManager.Remove(defineID); !ok {
 		return false
 	}
+	delete(mate.histories, defineID)
+	delete(mate.varRefs, defineID)
+	mate.removeDerived(defineID)
+	if block, exists := mate.blocks[simVar.BlockID]; exists {
+		block.remove(simVar)
+		mate.dirty = true
+	}
 	return true
 }
 
@@ -72,6 +227,36 @@ func (mate *SimMate) SimVarValueAndDataType(defineID DWord) (interface{}, DWord,
 	return simVar.Value, simVar.DataType, true
 }
 
+// SimVarValueIn returns the value of a simvar converted to the given unit, see SimVar.ValueIn.
+func (mate *SimMate) SimVarValueIn(defineID DWord, unit string) (float64, error) {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	simVar, ok := mate.simVarManager.GetSimVar(defineID)
+	if !ok {
+		return 0, fmt.Errorf("unknown simvar %d", defineID)
+	}
+	return simVar.ValueIn(unit)
+}
+
+// Events returns the registry which maps event names to IDs, e.g. mate.Events().Transmit("GEAR_TOGGLE").
+func (mate *SimMate) Events() *EventRegistry {
+	return mate.events
+}
+
+// SystemEvents returns the typed system event subscriptions, e.g. mate.SystemEvents().OnPause(fn).
+// The callbacks are called from the goroutine running the SimMate.
+func (mate *SimMate) SystemEvents() *SystemEvents {
+	return mate.systemEvents
+}
+
+// History returns the samples of a simvar added WithHistory. Non-numeric values aren't recorded.
+func (mate *SimMate) History(defineID DWord) (*History, bool) {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	history, exists := mate.histories[defineID]
+	return history, exists
+}
+
 func (mate *SimMate) SimVar(defineID DWord) (SimVar, bool) {
 	mate.mutex.Lock()
 	defer mate.mutex.Unlock()
@@ -88,240 +273,423 @@ func (mate *SimMate) SimVarDump(indent string) []string {
 	return mate.simVarManager.SimVarDump(indent)
 }
 
+// SetSimObjectData sets a simvar on the user object. See SetSimObjectDataOnObject.
 func (mate *SimMate) SetSimObjectData(name, unit string, value interface{}, dataType DWord) error {
-	defineID := NewDefineID()
-	if err := mate.AddToDataDefinition(defineID, name, unit, DataTypeFloat64); err != nil {
+	return mate.SetSimObjectDataOnObject(ObjectIDUser, name, unit, value, dataType)
+}
+
+// SetSimObjectDataOnObject sets a simvar on the given object. The value is encoded as the given DataType*,
+// e.g. an int as DataTypeFloat64, a string as DataTypeString64 or a Waypoint as DataTypeWaypoint.
+// A slice sets multiple elements at once, e.g. a []Waypoint for "AI WAYPOINT LIST".
+// The data definition is created on first use and reused for every further write of the same simvar,
+// the simvar is checked against the catalog like in AddSimVar and must be settable.
+func (mate *SimMate) SetSimObjectDataOnObject(objectID DWord, name, unit string, value interface{}, dataType DWord) error {
+	data, arrayCount, unitSize, err := encodeElements(value, dataType)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defineID, err := mate.setDefineID(name, unit, dataType)
+	if err != nil {
 		return err
 	}
-	switch dataType {
-	case DataTypeFloat64:
-		buffer := [1]float64{
-			ValueToFloat64(value),
-		}
-		size := DWord(unsafe.Sizeof(buffer))
-		mate.SetDataOnSimObject(defineID, ObjectIDUser, 0, 0, size, unsafe.Pointer(&buffer[0]))
+	return mate.SetDataOnSimObject(defineID, objectID, 0, arrayCount, unitSize, unsafe.Pointer(&data[0]))
+}
 
-	default:
-		log.Tracef("SimConnect.SetSimObjectData: datatype not implemented: %v", dataType)
+// setDefineID returns the cached data definition for writing the simvar, adding it if needed.
+func (mate *SimMate) setDefineID(name, unit string, dataType DWord) (DWord, error) {
+	mate.setMutex.Lock()
+	defer mate.setMutex.Unlock()
+	key := setDefinition{simVarKey(name), unit, dataType}
+	if defineID, exists := mate.setDefines[key]; exists {
+		return defineID, nil
+	}
+	if err := mate.validateSimVar(name, unit, dataType); err != nil {
+		return 0, err
+	}
+	if err := mate.validateSettable(name); err != nil {
+		return 0, err
+	}
+	defineID := NewDefineID()
+	if err := mate.addToDataDefinition(defineID, name, unit, dataType, 0, Unused); err != nil {
+		mate.ClearDataDefinition(defineID)
+		return 0, err
 	}
-	return nil
+	mate.setDefines[key] = defineID
+	return defineID, nil
 }
 
-func (mate *SimMate) HandleEvents(requestDataInterval time.Duration, receiveDataInterval time.Duration, stop chan interface{}, listener *EventListener) {
-	reqDataTicker := time.NewTicker(requestDataInterval)
-	defer reqDataTicker.Stop()
-
-	recvDataTicker := time.NewTicker(receiveDataInterval)
-	defer recvDataTicker.Stop()
-
-	requestCount := 0
-	updateCount := 0
+// encodeElements encodes a value or a slice of values and returns the data,
+// the number of elements (0 for a single value) and the size of one element.
+func encodeElements(value interface{}, dataType DWord) ([]byte, DWord, DWord, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		data, err := EncodeValue(value, dataType)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return data, 0, DWord(len(data)), nil
+	}
+	if rv.Len() == 0 {
+		return nil, 0, 0, fmt.Errorf("no values")
+	}
+	if dataType == DataTypeStringV {
+		return nil, 0, 0, fmt.Errorf("stringv arrays can't be encoded")
+	}
+	data := make([]byte, 0, rv.Len()*DataTypeSize(dataType))
+	for i := 0; i < rv.Len(); i++ {
+		element, err := EncodeValue(rv.Index(i).Interface(), dataType)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("element %d: %w", i, err)
+		}
+		data = append(data, element...)
+	}
+	return data, DWord(rv.Len()), DWord(DataTypeSize(dataType)), nil
+}
 
-	for {
+// HandleEvents requests the simvars every requestDataInterval and dispatches messages until stop is signaled or closed.
+//
+// Deprecated: Use Run, which reports why it returned and doesn't need a receive interval.
+func (mate *SimMate) HandleEvents(requestDataInterval time.Duration, receiveDataInterval time.Duration, stop chan interface{}, listener *EventListener) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
 		select {
 		case <-stop:
-			return
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	if err := mate.Run(ctx, requestDataInterval, listener); err != nil && err != context.Canceled {
+		log.Tracef("Dispatcher stopped: %s", err.Error())
+	}
+}
 
-		case <-reqDataTicker.C:
-			if updateCount > 0 {
-				if listener != nil && listener.OnDataReady != nil {
-					listener.OnDataReady()
-				}
-			}
-			mate.requestSimObjectData()
-			requestCount++
-
-		case <-recvDataTicker.C:
-			ppData, r1, err := mate.GetNextDispatch()
-			if r1 < 0 {
-				if uint32(r1) != EFail {
-					log.Tracef("GetNextDispatch error: %s (%d)", err.Error(), r1)
-					return
-				}
-				if ppData == nil {
-					break
-				}
-			}
+// Run polls the simvars and dispatches messages to the listener
+// until the context is done, the simulator quits or SimConnect fails. See Dispatcher.Run.
+func (mate *SimMate) Run(ctx context.Context, requestDataInterval time.Duration, listener *EventListener) error {
+	return mate.NewDispatcher(requestDataInterval, listener, nil).Run(ctx)
+}
 
-			recv := *(*Recv)(ppData)
-			switch recv.ID {
-			case RecvIDException:
-				recvException := *(*RecvException)(ppData)
-				if listener != nil && listener.OnException != nil {
-					listener.OnException(recvException.Exception)
-				}
-
-			case RecvIDOpen:
-				recvOpen := *(*RecvOpen)(ppData)
-				applName := strings.Trim(string(recvOpen.ApplicationName[:256]), "\x00")
-				applVersion := fmt.Sprintf("%d.%d", recvOpen.ApplicationVersionMajor, recvOpen.ApplicationVersionMinor)
-				applBuild := fmt.Sprintf("%d.%d", recvOpen.ApplicationBuildMajor, recvOpen.ApplicationBuildMinor)
-				simConnectVersion := fmt.Sprintf("%d.%d", recvOpen.SimConnectVersionMajor, recvOpen.SimConnectVersionMinor)
-				simConnectBuild := fmt.Sprintf("%d.%d", recvOpen.SimConnectBuildMajor, recvOpen.SimConnectBuildMinor)
-				if listener != nil && listener.OnOpen != nil {
-					listener.OnOpen(applName, applVersion, applBuild, simConnectVersion, simConnectBuild)
-				}
-
-			case RecvIDQuit:
-				if listener != nil && listener.OnQuit != nil {
-					listener.OnQuit()
-				}
-
-			case RecvIDEvent:
-				recvEvent := *(*RecvEvent)(ppData)
-				if listener != nil && listener.OnEventID != nil {
-					listener.OnEventID(recvEvent.EventID)
-				}
-
-			// case RecvIDEventObjectAddRemove:
-			// case RecvIDEventFilename:
-			// case RecvIDEventFrame:
-
-			case RecvIDSimobjectData:
-				recvData := *(*RecvSimObjectData)(ppData)
-				if listener != nil && listener.OnSimObjectData != nil {
-					listener.OnSimObjectData(&recvData)
-				}
-
-			case RecvIDSimObjectDataByType:
-				recvData := *(*RecvSimObjectDataByType)(ppData)
-				simVar, exists := mate.simVarManager.GetSimVar(recvData.DefineID)
-				if !exists {
-					continue
-				}
-
-				var value interface{}
-				switch simVar.DataType {
-				case DataTypeInt32:
-					value = (*SimObjectData_int32)(ppData).Value
-
-				case DataTypeInt64:
-					value = (*SimObjectData_int64)(ppData).Value
-
-				case DataTypeFloat32:
-					value = (*SimObjectData_float32)(ppData).Value
-
-				case DataTypeFloat64:
-					value = (*SimObjectData_float64)(ppData).Value
-
-				case DataTypeString8:
-					value = (*SimObjectData_string8)(ppData).Value
-
-				case DataTypeString32:
-					value = (*SimObjectData_string32)(ppData).Value
-
-				case DataTypeString64:
-					value = (*SimObjectData_string64)(ppData).Value
-
-				case DataTypeString128:
-					value = (*SimObjectData_string128)(ppData).Value
-
-				case DataTypeString256:
-					value = (*SimObjectData_string256)(ppData).Value
-
-				case DataTypeString260:
-					value = (*SimObjectData_string260)(ppData).Value
-
-				case DataTypeStringV:
-					value = (*SimObjectData_stringv)(ppData).Value
-
-				// case DataTypeInitPosition:
-				// case DataTypeStringMarkerState:
-				// case DataTypeWaypoint:
-				// case DataTypeStringLatLonAlt:
-				// case DataTypeStringXYZ:
-				default:
-				}
-				if value != nil {
-					mate.updateSimObjectData(recvData.RequestID, recvData.DefineID, value)
-					updateCount++
-				}
-				if listener != nil && listener.OnSimObjectDataByType != nil {
-					listener.OnSimObjectDataByType(&recvData)
-				}
-
-			// case RecvIDWeatherObservation:
-			// case RecvIDCloudState:
-			// case RecvIDAssignedObjectID:
-			// case RecvIDReservedKey:
-			// case RecvIDCustomAction:
-			// case RecvIDSystemState:
-			// case RecvIDClientData:
-			// case RecvIDEventWeatherMode:
-			// case RecvIDAirportList:
-			// case RecvIDVORList:
-			// case RecvIDNDBList:
-			// case RecvIDWaypointList:
-			// case RecvIDEventMultiplayerServerStarted:
-			// case RecvIDEventMultiplayerClientStarted:
-			// case RecvIDEventMultiplayerSessionEnded:
-			// case RecvIDEventRaceEnd:
-			// case RecvIDEventRaceLap:
-			// case RecvIDPick:
-
-			default:
-				log.Tracef("Unknown recvInfo ID: %d", recv.ID)
+// NewDispatcher returns a Dispatcher which keeps the simvars of the SimMate up to date
+// and notifies the listener. If clock is nil the wall clock is used.
+// Every requestDataInterval the simvars which are due are polled, see WithInterval, WithPriority and MaxInFlight.
+// The interval must be positive, otherwise Run of the Dispatcher fails.
+func (mate *SimMate) NewDispatcher(requestDataInterval time.Duration, listener *EventListener, clock Clock) *Dispatcher {
+	d := NewDispatcher(mate, clock)
+	dataReady := func(snapshot *Snapshot) {
+		mate.publish(snapshot)
+		if listener != nil && listener.OnDataReady != nil {
+			listener.OnDataReady()
+		}
+	}
+	d.Handle(func(msg *Message, value interface{}) {
+		if _, snapshot := mate.updateSimVars(value, d.clock.Now()); snapshot != nil {
+			dataReady(snapshot)
+		}
+	})
+	d.Handle(func(msg *Message, value interface{}) {
+		mate.systemEvents.Dispatch(value)
+		if recv, ok := value.(*RecvEvent); ok && listener != nil && listener.OnEvent != nil {
+			if name, exists := mate.events.Name(recv.EventID); exists {
+				listener.OnEvent(name, recv.Data)
 			}
 		}
+	})
+	d.Listen(listener)
+	d.Every(requestDataInterval, func() {
+		// a round which is still outstanding has timed out
+		now := d.clock.Now()
+		mate.mutex.Lock()
+		snapshot := mate.takeSnapshot(now)
+		mate.mutex.Unlock()
+		if snapshot != nil {
+			dataReady(snapshot)
+		}
+		if err := mate.requestSimObjectData(now, requestDataInterval); err != nil {
+			log.Warnf("Requesting simvars: %s", err.Error())
+		}
+	})
+	return d
+}
+
+// updateSimVars stores the value of a received simvar sampled at now. It reports whether a simvar was updated
+// and returns the new snapshot if the data completed a round.
+func (mate *SimMate) updateSimVars(value interface{}, now time.Time) (bool, *Snapshot) {
+	var recv *RecvSimObjectData
+	var data []byte
+	switch v := value.(type) {
+	case *ObjectDataByType:
+		recv, data = &v.RecvSimObjectData, v.Data
+	case *ObjectData:
+		recv, data = &v.RecvSimObjectData, v.Data
+	default:
+		return false, nil
+	}
+	mate.mutex.Lock()
+	updated, values, err := mate.updateBlock(recv, data, now)
+	if err == nil {
+		derived, derivedValues := mate.updateDerived(updated)
+		updated = append(updated, derived...)
+		values = append(values, derivedValues...)
+	}
+	watchers := make([][]simVarWatcher, len(updated))
+	sampled := make([]time.Time, len(updated))
+	for i, simVar := range updated {
+		watchers[i] = mate.watchers[simVar.DefineID]
+		sampled[i] = simVar.LastUpdate
+	}
+	var snapshot *Snapshot
+	if err == nil && len(updated) > 0 {
+		mate.changed = true
+		if mate.completeRound(recv.DefineID) {
+			snapshot = mate.takeSnapshot(now)
+		}
+	}
+	mate.mutex.Unlock()
+
+	if err != nil {
+		log.Tracef("Dropping block %d: %s", recv.DefineID, err.Error())
+		return false, nil
 	}
+	for i := range updated {
+		for _, watcher := range watchers[i] {
+			watcher.fn(values[i], sampled[i])
+		}
+	}
+	return len(updated) > 0, snapshot
 }
 
-func (mate *SimMate) registerSimVars() (int, error) {
-	count := 0
-	for _, simVar := range mate.simVarManager.Vars {
-		if !simVar.Registered {
-			err := mate.AddToDataDefinition(simVar.DefineID, simVar.Name, simVar.Unit, simVar.DataType)
-			if err != nil {
-				return count, err
-			} else {
-				simVar.Registered = true
-				count++
+// watch calls fn with every new value of the simvar and its sample time, until the returned function is called.
+func (mate *SimMate) watch(defineID DWord, fn func(value interface{}, sampled time.Time)) func() {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	mate.watcherID++
+	id := mate.watcherID
+	mate.watchers[defineID] = append(mate.watchers[defineID], simVarWatcher{id, fn})
+	return func() {
+		mate.mutex.Lock()
+		defer mate.mutex.Unlock()
+		watchers := mate.watchers[defineID]
+		for i, watcher := range watchers {
+			if watcher.id == id {
+				mate.watchers[defineID] = append(watchers[:i:i], watchers[i+1:]...)
+				return
 			}
 		}
 	}
-	return count, nil
 }
 
-func (mate *SimMate) requestSimObjectData() (bool, error) {
+// notifyListener invokes the listener's callback matching the decoded message.
+func notifyListener(value interface{}, listener *EventListener) {
+	if listener == nil {
+		return
+	}
+	switch recv := value.(type) {
+	case *RecvException:
+		if listener.OnException != nil {
+			listener.OnException(recv.Exception)
+		}
+
+	case *RecvOpen:
+		applName := CString(recv.ApplicationName[:])
+		applVersion := fmt.Sprintf("%d.%d", recv.ApplicationVersionMajor, recv.ApplicationVersionMinor)
+		applBuild := fmt.Sprintf("%d.%d", recv.ApplicationBuildMajor, recv.ApplicationBuildMinor)
+		simConnectVersion := fmt.Sprintf("%d.%d", recv.SimConnectVersionMajor, recv.SimConnectVersionMinor)
+		simConnectBuild := fmt.Sprintf("%d.%d", recv.SimConnectBuildMajor, recv.SimConnectBuildMinor)
+		if listener.OnOpen != nil {
+			listener.OnOpen(applName, applVersion, applBuild, simConnectVersion, simConnectBuild)
+		}
+
+	case *RecvQuit:
+		if listener.OnQuit != nil {
+			listener.OnQuit()
+		}
+
+	case *RecvEvent:
+		if listener.OnEventID != nil {
+			listener.OnEventID(recv.EventID)
+		}
+
+	case *RecvEventObjectAddRemove:
+		if listener.OnEventObjectAddRemove != nil {
+			listener.OnEventObjectAddRemove(recv)
+		}
+
+	case *RecvEventFilename:
+		if listener.OnEventFilename != nil {
+			listener.OnEventFilename(recv)
+		}
+
+	case *RecvEventFrame:
+		if listener.OnEventFrame != nil {
+			listener.OnEventFrame(recv)
+		}
+
+	case *ObjectData:
+		if listener.OnSimObjectData != nil {
+			listener.OnSimObjectData(&recv.RecvSimObjectData)
+		}
+
+	case *ObjectDataByType:
+		if listener.OnSimObjectDataByType != nil {
+			listener.OnSimObjectDataByType(&recv.RecvSimObjectDataByType)
+		}
+
+	case *WeatherObservation:
+		if listener.OnWeatherObservation != nil {
+			listener.OnWeatherObservation(recv)
+		}
+
+	case *CloudState:
+		if listener.OnCloudState != nil {
+			listener.OnCloudState(recv)
+		}
+
+	case *RecvAssignedObjectID:
+		if listener.OnAssignedObjectID != nil {
+			listener.OnAssignedObjectID(recv)
+		}
+
+	case *RecvReservedKey:
+		if listener.OnReservedKey != nil {
+			listener.OnReservedKey(recv)
+		}
+
+	case *CustomAction:
+		if listener.OnCustomAction != nil {
+			listener.OnCustomAction(recv)
+		}
+
+	case *RecvSystemState:
+		if listener.OnSystemState != nil {
+			listener.OnSystemState(recv)
+		}
+
+	case *ClientData:
+		if listener.OnClientData != nil {
+			listener.OnClientData(recv)
+		}
+
+	case *RecvEventWeatherMode:
+		if listener.OnEventWeatherMode != nil {
+			listener.OnEventWeatherMode(recv)
+		}
+
+	case *AirportList:
+		if listener.OnAirportList != nil {
+			listener.OnAirportList(recv)
+		}
+
+	case *VORList:
+		if listener.OnVORList != nil {
+			listener.OnVORList(recv)
+		}
+
+	case *NDBList:
+		if listener.OnNDBList != nil {
+			listener.OnNDBList(recv)
+		}
+
+	case *WaypointList:
+		if listener.OnWaypointList != nil {
+			listener.OnWaypointList(recv)
+		}
+
+	case *RecvEventMultiplayerServerStarted:
+		if listener.OnEventMultiplayerServerStarted != nil {
+			listener.OnEventMultiplayerServerStarted(recv)
+		}
+
+	case *RecvEventMultiplayerClientStarted:
+		if listener.OnEventMultiplayerClientStarted != nil {
+			listener.OnEventMultiplayerClientStarted(recv)
+		}
+
+	case *RecvEventMultiplayerSessionEnded:
+		if listener.OnEventMultiplayerSessionEnded != nil {
+			listener.OnEventMultiplayerSessionEnded(recv)
+		}
+
+	case *RecvEventRaceEnd:
+		if listener.OnEventRaceEnd != nil {
+			listener.OnEventRaceEnd(recv)
+		}
+
+	case *RecvEventRaceLap:
+		if listener.OnEventRaceLap != nil {
+			listener.OnEventRaceLap(recv)
+		}
+
+	case *Recv:
+		log.Tracef("Unknown recvInfo ID: %d", recv.ID)
+	}
+}
+
+// requestSimObjectData polls the blocks which are due on the tick at now, within the MaxInFlight budget.
+// A block whose request fails stays due and is tried again on the next tick, the first error is returned.
+func (mate *SimMate) requestSimObjectData(now time.Time, tick time.Duration) error {
 	mate.mutex.Lock()
 	defer mate.mutex.Unlock()
 
 	if mate.dirty {
-		count, err := mate.registerSimVars()
+		count, err := mate.packSimVars()
 		if err != nil {
-			return false, err
+			return err
 		}
 		if count > 0 {
-			log.Tracef("Registered %d simvars", count)
+			log.Tracef("Registered %d simvars in %d blocks", count, len(mate.blocks))
 		}
 		mate.dirty = false
 	}
 
-	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
-	const radiusMeters = 0
-	simObjectType := SimObjectTypeUser
-	for _, simVar := range mate.simVarManager.Vars {
-		if !simVar.Pending {
-			simVar.RequestID = NewRequestID()
-		} else {
-			if timestamp-simVar.Timestamp < simVarRequestTimeout {
-				continue
-			}
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+	inFlight := 0
+	due := make([]*simVarBlock, 0, len(mate.blocks))
+	for _, block := range mate.blocks {
+		if block.period != PeriodNever {
+			continue
+		}
+		if block.inFlight(timestamp) {
+			inFlight++
+		} else if block.isDue(now, tick) {
+			due = append(due, block)
 		}
-		mate.RequestDataOnSimObjectType(simVar.RequestID, simVar.DefineID, radiusMeters, simObjectType)
-		simVar.Timestamp = timestamp
-		simVar.Pending = true
 	}
-	return true, nil
-}
-
-func (mate *SimMate) updateSimObjectData(requestID, defineID DWord, value interface{}) {
-	if simVar, updated := mate.simVarManager.Update(requestID, defineID, value); updated {
-		simVar.Pending = false
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].priority != due[j].priority {
+			return due[i].priority > due[j].priority
+		}
+		if !due[i].due.Equal(due[j].due) {
+			return due[i].due.Before(due[j].due)
+		}
+		return due[i].defineID < due[j].defineID
+	})
+	requested := make([]DWord, 0, len(due))
+	var firstErr error
+	for _, block := range due {
+		if mate.MaxInFlight > 0 && inFlight >= mate.MaxInFlight {
+			break
+		}
+		if err := mate.requestBlock(block, timestamp); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if block.due.IsZero() {
+			mate.slot++
+		}
+		block.schedule(now, tick, mate.slot)
+		requested = append(requested, block.defineID)
+		inFlight++
 	}
+	mate.startRound(requested)
+	return firstErr
 }
 
 // Generics. Needed. Badly. Ugh.
+//
+// Deprecated: Declare a tagged struct and use SimConnect.RegisterDataDefinition instead.
 type SimObjectData struct {
 	RecvSimObjectDataByType
 }
@@ -376,7 +744,9 @@ type SimObjectData_string260 struct {
 	Value [260]byte
 }
 
+// SimObjectData_stringv can't be cast from a message because a STRINGV has no fixed size.
+// Use RetrieveString on the data of the message instead.
 type SimObjectData_stringv struct {
 	SimObjectData
-	Value string // TODO: Not sure if this is correct
+	Value string
 }
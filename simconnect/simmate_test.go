@@ -0,0 +1,442 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSimMateScriptedRoundTrip(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	if err := mate.Open("test"); err != nil {
+		t.Fatal(err)
+	}
+	defineID := mate.AddSimVar("INDICATED ALTITUDE", "feet", DataTypeFloat64)
+	if defineID == 0 {
+		t.Fatal("AddSimVar rejected the simvar")
+	}
+
+	if err := mate.requestSimObjectData(time.Now(), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	adds := backend.CallsNamed(scAddToDataDefinition)
+	if len(adds) != 1 || adds[0].Args[1] != "INDICATED ALTITUDE" || adds[0].Args[2] != "feet" {
+		t.Fatalf("AddToDataDefinition calls = %v", adds)
+	}
+	requests := backend.CallsNamed(scRequestDataOnSimObjectType)
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	requestID, blockID := requests[0].Args[0].(DWord), requests[0].Args[1].(DWord)
+	if blockID != adds[0].Args[0].(DWord) {
+		t.Fatalf("request for define ID %d, data definition %d", blockID, adds[0].Args[0])
+	}
+
+	recv := RecvSimObjectDataByType{RecvSimObjectData{
+		Recv:        Recv{ID: RecvIDSimObjectDataByType},
+		RequestID:   requestID,
+		ObjectID:    ObjectIDUser,
+		DefineID:    blockID,
+		EntryNumber: 1,
+		OutOf:       1,
+		DefineCount: 1,
+	}}
+	if err := backend.PushRecv(recv, float64(4500)); err != nil {
+		t.Fatal(err)
+	}
+	dataReady := 0
+	d := mate.NewDispatcher(time.Second, &EventListener{OnDataReady: func() { dataReady++ }}, nil)
+	if count, err := d.drain(); err != nil || count != 1 {
+		t.Fatalf("drain = %d, %v", count, err)
+	}
+
+	value, dataType, ok := mate.SimVarValueAndDataType(defineID)
+	if !ok || dataType != DataTypeFloat64 || value != float64(4500) {
+		t.Fatalf("value = %v (%d, %v), want 4500", value, dataType, ok)
+	}
+	if dataReady != 1 {
+		t.Fatalf("OnDataReady called %d times, want 1", dataReady)
+	}
+	snapshot := mate.Snapshot()
+	if snapshot == nil {
+		t.Fatal("no snapshot")
+	}
+	if value, ok := snapshot.Value(defineID); !ok || value != float64(4500) {
+		t.Fatalf("snapshot value = %v, %v", value, ok)
+	}
+}
+
+func TestAddSimVarIgnoresOptionsOfExistingSimVar(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	defineID := mate.AddSimVar("AIRSPEED INDICATED", "knots", DataTypeFloat64, WithHistory(4))
+	history, _ := mate.History(defineID)
+	history.Add(float64(100), time.Now())
+
+	if again := mate.AddSimVar("airspeed indicated", "knots", DataTypeFloat64, WithHistory(10), WithPeriod(PeriodSecond)); again != defineID {
+		t.Fatalf("AddSimVar returned %d, want %d", again, defineID)
+	}
+	simVar, _ := mate.SimVar(defineID)
+	if simVar.Period != PeriodNever || simVar.HistorySize != 4 {
+		t.Errorf("options were applied again: period %d, history size %d", simVar.Period, simVar.HistorySize)
+	}
+	if kept, _ := mate.History(defineID); kept != history || kept.Len() != 1 {
+		t.Error("history was replaced")
+	}
+
+	derivedID, err := mate.AddDerivedSimVar("AIRSPEED MPH", "mph", "[AIRSPEED INDICATED] * 1.15078")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := mate.AddSimVar("AIRSPEED MPH", "mph", DataTypeFloat64, WithOnChange()); again != derivedID {
+		t.Fatalf("AddSimVar returned %d, want %d", again, derivedID)
+	}
+	if derived, _ := mate.SimVar(derivedID); derived.Period != PeriodNever || derived.Flags != 0 {
+		t.Errorf("derived simvar got period %d, flags %d", derived.Period, derived.Flags)
+	}
+}
+
+func TestTryAddSimVar(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	if _, err := mate.TryAddSimVar("PLANE ALTITUDES", "feet", DataTypeFloat64); !errors.Is(err, ErrUnknownSimVar) {
+		t.Errorf("misspelled name: err = %v, want ErrUnknownSimVar", err)
+	}
+	if _, err := mate.TryAddSimVar("PLANE ALTITUDE", "knots", DataTypeFloat64); !errors.Is(err, ErrIncompatibleUnit) {
+		t.Errorf("altitude in knots: err = %v, want ErrIncompatibleUnit", err)
+	}
+	if defineID := mate.AddSimVar("PLANE ALTITUDES", "feet", DataTypeFloat64); defineID != 0 {
+		t.Errorf("AddSimVar returned %d for a rejected simvar, want 0", defineID)
+	}
+	if _, err := AddVar[float64](mate, "PLANE ALTITUDES", "feet"); !errors.Is(err, ErrUnknownSimVar) {
+		t.Errorf("AddVar: err = %v, want ErrUnknownSimVar", err)
+	}
+	if mate.simVarManager.Count() != 0 {
+		t.Fatalf("%d simvars added, want none", mate.simVarManager.Count())
+	}
+
+	defineID, err := mate.TryAddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	if err != nil || defineID == 0 {
+		t.Fatalf("TryAddSimVar = %d, %v", defineID, err)
+	}
+	if again, err := mate.TryAddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64); err != nil || again != defineID {
+		t.Errorf("adding it again = %d, %v, want %d", again, err, defineID)
+	}
+}
+
+func TestRegisterFailureClearsDefinition(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	for _, name := range []string{"PLANE ALTITUDE", "PLANE LATITUDE", "PLANE LONGITUDE"} {
+		mate.AddSimVar(name, "", DataTypeFloat64)
+	}
+	errFailed := errors.New("failed")
+	backend.SetErrorAfter(scAddToDataDefinition, 1, errFailed)
+	now := time.Now()
+	if err := mate.requestSimObjectData(now, time.Second); !errors.Is(err, errFailed) {
+		t.Fatalf("err = %v, want %v", err, errFailed)
+	}
+	clears := backend.CallsNamed(scClearDataDefinition)
+	if len(clears) != 1 {
+		t.Fatalf("%d ClearDataDefinition calls, want 1", len(clears))
+	}
+	if requests := backend.CallsNamed(scRequestDataOnSimObjectType); len(requests) != 0 {
+		t.Fatalf("requested a partial definition: %v", requests)
+	}
+
+	backend.SetError(scAddToDataDefinition, nil)
+	backend.Reset()
+	if err := mate.requestSimObjectData(now.Add(time.Second), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	adds := backend.CallsNamed(scAddToDataDefinition)
+	if len(adds) != 3 {
+		t.Fatalf("%d AddToDataDefinition calls on retry, want 3", len(adds))
+	}
+	for i, call := range adds {
+		if call.Args[0] != clears[0].Args[0] || call.Args[5] != DWord(i) {
+			t.Errorf("retry call %d = %v", i, call.Args)
+		}
+	}
+	if requests := backend.CallsNamed(scRequestDataOnSimObjectType); len(requests) != 1 {
+		t.Errorf("%d requests after the retry, want 1", len(requests))
+	}
+}
+
+func TestRequestFailureLeavesBlockDue(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	defineID := mate.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	errFailed := errors.New("failed")
+	backend.SetError(scRequestDataOnSimObjectType, errFailed)
+	now := time.Now()
+	if err := mate.requestSimObjectData(now, time.Second); !errors.Is(err, errFailed) {
+		t.Fatalf("err = %v, want %v", err, errFailed)
+	}
+	if simVar, _ := mate.SimVar(defineID); simVar.Pending {
+		t.Error("simvar is pending after a failed request")
+	}
+	if len(mate.round) != 0 {
+		t.Errorf("round waits for %d blocks, want none", len(mate.round))
+	}
+
+	backend.SetError(scRequestDataOnSimObjectType, nil)
+	if err := mate.requestSimObjectData(now.Add(time.Millisecond), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if requests := backend.CallsNamed(scRequestDataOnSimObjectType); len(requests) != 2 {
+		t.Errorf("%d requests, want the failed one and a retry on the next tick", len(requests))
+	}
+	if simVar, _ := mate.SimVar(defineID); !simVar.Pending {
+		t.Error("simvar isn't pending after the retry")
+	}
+}
+
+func TestRemoveSimVarFromSubscribedBlock(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	altitude := mate.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64, WithOnChange())
+	latitude := mate.AddSimVar("PLANE LATITUDE", "degrees", DataTypeFloat64, WithOnChange())
+	longitude := mate.AddSimVar("PLANE LONGITUDE", "degrees", DataTypeFloat64, WithOnChange())
+	now := time.Now()
+	if err := mate.requestSimObjectData(now, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	subscriptions := backend.CallsNamed(scRequestDataOnSimObject)
+	if len(subscriptions) != 1 {
+		t.Fatalf("%d subscriptions, want 1", len(subscriptions))
+	}
+	requestID, blockID := subscriptions[0].Args[0].(DWord), subscriptions[0].Args[1].(DWord)
+
+	// the simulator keeps sending the old layout until the block is registered again
+	if !mate.RemoveSimVar(latitude) {
+		t.Fatal("RemoveSimVar failed")
+	}
+	recv := RecvSimObjectData{
+		Recv:        Recv{ID: RecvIDSimobjectData},
+		RequestID:   requestID,
+		ObjectID:    ObjectIDUser,
+		DefineID:    blockID,
+		Flags:       DataRequestFlagChanged | DataRequestFlagTagged,
+		DefineCount: 2,
+	}
+	if err := backend.PushRecv(recv, taggedBytes(0, 1000.0, 2, -122.3)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mate.NewDispatcher(time.Second, nil, nil).drain(); err != nil {
+		t.Fatal(err)
+	}
+	if value, _, _ := mate.SimVarValueAndDataType(altitude); value != 1000.0 {
+		t.Errorf("altitude = %v, want 1000", value)
+	}
+	if value, _, _ := mate.SimVarValueAndDataType(longitude); value != -122.3 {
+		t.Errorf("longitude = %v, want -122.3", value)
+	}
+
+	backend.Reset()
+	if err := mate.requestSimObjectData(now.Add(time.Second), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	adds := backend.CallsNamed(scAddToDataDefinition)
+	if len(adds) != 2 || adds[0].Args[1] != "PLANE ALTITUDE" || adds[1].Args[1] != "PLANE LONGITUDE" || adds[1].Args[5] != DWord(1) {
+		t.Fatalf("re-registered %v", adds)
+	}
+}
+
+func TestStringVSimVar(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	altitude := mate.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	title := mate.AddSimVar("TITLE", "", DataTypeStringV)
+	atcID := mate.AddSimVar("ATC ID", "", DataTypeStringV)
+	if err := mate.requestSimObjectData(time.Now(), time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// every STRINGV gets a block of its own, the offsets behind it would be unknown
+	blocks := make(map[string]DWord)
+	for _, call := range backend.CallsNamed(scAddToDataDefinition) {
+		blocks[call.Args[1].(string)] = call.Args[0].(DWord)
+		if call.Args[1] != "PLANE ALTITUDE" && call.Args[3].(DWord) != DataTypeStringV {
+			t.Errorf("%s added as %s", call.Args[1], DataTypeToString(call.Args[3].(DWord)))
+		}
+	}
+	if len(blocks) != 3 || blocks["TITLE"] == blocks["ATC ID"] || blocks["TITLE"] == blocks["PLANE ALTITUDE"] {
+		t.Fatalf("blocks = %v, want one per STRINGV", blocks)
+	}
+	requests := make(map[DWord]DWord)
+	for _, call := range backend.CallsNamed(scRequestDataOnSimObjectType) {
+		requests[call.Args[1].(DWord)] = call.Args[0].(DWord)
+	}
+
+	push := func(name string, data []byte) {
+		t.Helper()
+		blockID := blocks[name]
+		recv := RecvSimObjectDataByType{RecvSimObjectData{
+			Recv:        Recv{ID: RecvIDSimObjectDataByType},
+			RequestID:   requests[blockID],
+			ObjectID:    ObjectIDUser,
+			DefineID:    blockID,
+			DefineCount: 1,
+		}}
+		if err := backend.PushRecv(recv, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	push("TITLE", InsertString(nil, "Cessna Skyhawk"))
+	push("ATC ID", []byte("N172SP")) // unterminated
+	push("PLANE ALTITUDE", newGolden(8).float64(0, 4500))
+	if _, err := mate.NewDispatcher(time.Second, nil, nil).drain(); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, dataType, _ := mate.SimVarValueAndDataType(title); value != "Cessna Skyhawk" || dataType != DataTypeStringV {
+		t.Errorf("title = %#v (%s)", value, DataTypeToString(dataType))
+	}
+	if value, _, _ := mate.SimVarValueAndDataType(atcID); value != nil {
+		t.Errorf("unterminated ATC ID = %#v, want no value", value)
+	}
+	if value, _, _ := mate.SimVarValueAndDataType(altitude); value != float64(4500) {
+		t.Errorf("altitude = %v", value)
+	}
+}
+
+func TestSetSimObjectData(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	waypoints := []Waypoint{
+		{Latitude: 47.4, Longitude: -122.3, Altitude: 3000, Flags: 0x4, KtsSpeed: 120},
+		{Latitude: 47.5, Longitude: -122.2, Altitude: 2500, KtsSpeed: 100, PercentThrottle: 60},
+	}
+	waypointData := make([]byte, 0)
+	for _, waypoint := range waypoints {
+		data, _ := EncodeValue(waypoint, DataTypeWaypoint)
+		waypointData = append(waypointData, data...)
+	}
+	tests := []struct {
+		objectID   DWord
+		name       string
+		value      interface{}
+		dataType   DWord
+		arrayCount DWord
+		unitSize   DWord
+		data       []byte
+	}{
+		{ObjectIDUser, "PLANE ALTITUDE", 5000, DataTypeFloat64, 0, 8, newGolden(8).float64(0, 5000)},
+		{ObjectIDUser, "PLANE ALTITUDE", float32(5500.5), DataTypeFloat64, 0, 8, newGolden(8).float64(0, 5500.5)},
+		{ObjectIDUser, "GENERAL ENG THROTTLE LEVER POSITION:1", 75.9, DataTypeInt32, 0, 4, newGolden(4).dword(0, 75)},
+		{ObjectIDUser, "ATC ID", "N172SP", DataTypeString8, 0, 8, newGolden(8).str(0, "N172SP")},
+		{ObjectIDUser, "ATC AIRLINE", "Cessna", DataTypeStringV, 0, 8, newGolden(8).str(0, "Cessna")},
+		{42, "AI WAYPOINT LIST", waypoints, DataTypeWaypoint, 2, 44, waypointData},
+	}
+	for _, test := range tests {
+		backend.Reset()
+		if err := mate.SetSimObjectDataOnObject(test.objectID, test.name, "", test.value, test.dataType); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		calls := backend.CallsNamed(scSetDataOnSimObject)
+		if len(calls) != 1 {
+			t.Fatalf("%s: %d SetDataOnSimObject calls", test.name, len(calls))
+		}
+		args := calls[0].Args
+		if args[1] != test.objectID || args[2] != DWord(0) || args[3] != test.arrayCount || args[4] != test.unitSize {
+			t.Errorf("%s: object %v, flags %v, count %v, unit size %v", test.name, args[1], args[2], args[3], args[4])
+		}
+		if data := args[5].([]byte); string(data) != string(test.data) {
+			t.Errorf("%s: payload % x\nwant % x", test.name, data, test.data)
+		}
+	}
+}
+
+func TestSetSimObjectDataReusesDefinition(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	for _, altitude := range []float64{1000, 2000} {
+		if err := mate.SetSimObjectData("PLANE ALTITUDE", "feet", altitude, DataTypeFloat64); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mate.SetSimObjectData("plane altitude", "feet", 3000.0, DataTypeFloat64); err != nil {
+		t.Fatal(err)
+	}
+	if err := mate.SetSimObjectData("PLANE ALTITUDE", "meters", 1000.0, DataTypeFloat64); err != nil {
+		t.Fatal(err)
+	}
+	adds := backend.CallsNamed(scAddToDataDefinition)
+	sets := backend.CallsNamed(scSetDataOnSimObject)
+	if len(adds) != 2 || len(sets) != 4 {
+		t.Fatalf("%d definitions for %d writes, want 2 for 4", len(adds), len(sets))
+	}
+	if sets[0].Args[0] != adds[0].Args[0] || sets[2].Args[0] != adds[0].Args[0] || sets[3].Args[0] != adds[1].Args[0] {
+		t.Errorf("writes went to %v, %v, %v, %v", sets[0].Args[0], sets[1].Args[0], sets[2].Args[0], sets[3].Args[0])
+	}
+
+	for _, test := range []struct {
+		value    interface{}
+		dataType DWord
+	}{
+		{"high", DataTypeFloat64},
+		{[]float64{}, DataTypeFloat64},
+		{[]string{"a", "b"}, DataTypeStringV},
+		{[]interface{}{1.0, "b"}, DataTypeFloat64},
+	} {
+		if err := mate.SetSimObjectData("PLANE ALTITUDE", "feet", test.value, test.dataType); err == nil {
+			t.Errorf("SetSimObjectData(%#v, %s) succeeded", test.value, DataTypeToString(test.dataType))
+		}
+	}
+	if sets := backend.CallsNamed(scSetDataOnSimObject); len(sets) != 4 {
+		t.Errorf("a rejected value was sent")
+	}
+}
+
+// testMate drives a SimMate through its dispatcher on a fake clock.
+type testMate struct {
+	*SimMate
+	backend *ScriptedBackend
+	clock   *fakeClock
+	d       *Dispatcher
+}
+
+func newTestMate(t *testing.T, listener *EventListener) *testMate {
+	t.Helper()
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	clock := newFakeClock()
+	return &testMate{mate, backend, clock, mate.NewDispatcher(time.Second, listener, clock)}
+}
+
+// tick advances the clock by the poll interval and runs the poll task of the dispatcher.
+func (m *testMate) tick() {
+	m.clock.now = m.clock.now.Add(time.Second)
+	m.d.tasks[0].fn()
+}
+
+// deliver answers the latest request of the simvar's block with the values and dispatches it.
+func (m *testMate) deliver(t *testing.T, defineID DWord, values ...interface{}) {
+	t.Helper()
+	simVar, _ := m.SimVar(defineID)
+	var requestID DWord
+	for _, call := range m.backend.CallsNamed(scRequestDataOnSimObjectType) {
+		if call.Args[1].(DWord) == simVar.BlockID {
+			requestID = call.Args[0].(DWord)
+		}
+	}
+	if requestID == 0 {
+		t.Fatalf("block of simvar %d wasn't requested", defineID)
+	}
+	recv := RecvSimObjectDataByType{RecvSimObjectData{
+		Recv:        Recv{ID: RecvIDSimObjectDataByType},
+		RequestID:   requestID,
+		ObjectID:    ObjectIDUser,
+		DefineID:    simVar.BlockID,
+		EntryNumber: 1,
+		OutOf:       1,
+		DefineCount: DWord(len(values)),
+	}}
+	if err := m.backend.PushRecv(append([]interface{}{recv}, values...)...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.d.drain(); err != nil {
+		t.Fatal(err)
+	}
+}
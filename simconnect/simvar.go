@@ -1,7 +1,20 @@
 package simconnect
 
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grumpypixel/msfs2020-simconnect-go/simconnect/units"
+)
+
+// ErrNoValue is returned when a simvar hasn't been received yet.
+var ErrNoValue = errors.New("no value")
+
 type SimVar struct {
-	DefineID    DWord
+	DefineID    DWord // handle of the simvar
+	BlockID     DWord // define ID of the data definition the simvar is requested with
+	Offset      DWord // byte offset of the simvar within the data of its block
 	RequestID   DWord
 	Name        string
 	Unit        string
@@ -12,6 +25,56 @@ type SimVar struct {
 	Registered  bool
 	Pending     bool
 	Timestamp   int64
+	LastUpdate  time.Time
+	Period      DWord         // PeriodNever if SimMate polls the simvar, otherwise the period it is pushed with
+	Flags       DWord         // DataRequestFlag* of the subscription
+	Interval    time.Duration // polling interval, 0 for the requestDataInterval of the SimMate
+	Priority    int           // polled simvars with a higher priority are requested first, see SimMate.MaxInFlight
+	HistorySize int           // number of samples SimMate keeps, see SimMate.History
+	Expression  string        // expression of a derived simvar, see SimMate.AddDerivedSimVar
+}
+
+// SimVarOption configures a simvar added with SimMate.AddSimVar.
+type SimVarOption func(simVar *SimVar)
+
+// WithPeriod subscribes to the simvar, i.e. the simulator pushes it with the given period
+// (e.g. PeriodSimFrame or PeriodSecond) instead of SimMate polling it.
+func WithPeriod(period DWord) SimVarOption {
+	return func(simVar *SimVar) {
+		simVar.Period = period
+	}
+}
+
+// WithInterval polls the simvar every interval instead of every requestDataInterval, e.g. every minute for "TITLE".
+// The interval is rounded up to a multiple of the requestDataInterval. It has no effect on subscribed simvars.
+func WithInterval(interval time.Duration) SimVarOption {
+	return func(simVar *SimVar) {
+		simVar.Interval = interval
+	}
+}
+
+// WithPriority sets the priority of a polled simvar. When more requests are due than SimMate.MaxInFlight allows,
+// simvars with a higher priority are requested first and the others wait for the next tick. The default priority is 0.
+func WithPriority(priority int) SimVarOption {
+	return func(simVar *SimVar) {
+		simVar.Priority = priority
+	}
+}
+
+// WithHistory keeps the latest size samples of a numeric simvar, see SimMate.History.
+func WithHistory(size int) SimVarOption {
+	return func(simVar *SimVar) {
+		simVar.HistorySize = size
+	}
+}
+
+// WithOnChange subscribes to the simvar, but the simulator only sends it when it changed.
+// Changes are checked every sim frame unless WithPeriod says otherwise.
+// Note that simvars with the same period share a data definition and are sent together if one of them changes.
+func WithOnChange() SimVarOption {
+	return func(simVar *SimVar) {
+		simVar.Flags |= DataRequestFlagChanged
+	}
 }
 
 func NewSimVar(defineID DWord, name string, unit string, dataType DWord) *SimVar {
@@ -25,6 +88,16 @@ func NewSimVar(defineID DWord, name string, unit string, dataType DWord) *SimVar
 	}
 }
 
+// Derived reports whether the simvar is computed from other simvars rather than requested.
+func (simVar *SimVar) Derived() bool {
+	return simVar.Expression != ""
+}
+
+// Subscribed reports whether the simvar is pushed by the simulator rather than polled.
+func (simVar *SimVar) Subscribed() bool {
+	return simVar.Period != PeriodNever
+}
+
 func (simVar *SimVar) ToInt32(defaultValue int32) int32 {
 	if simVar.Value != nil {
 		return ValueToInt32(simVar.Value)
@@ -53,6 +126,23 @@ func (simVar *SimVar) ToFloat64(defaultValue float64) float64 {
 	return defaultValue
 }
 
+// ValueIn returns the value converted from the unit the simvar was added with to the given unit,
+// e.g. "m/s" for a simvar added in knots. See units.Parse for the unit names.
+func (simVar *SimVar) ValueIn(unit string) (float64, error) {
+	if simVar.Value == nil {
+		return 0, fmt.Errorf("%s: %w", simVar.Name, ErrNoValue)
+	}
+	value, ok := ValueToNumber(simVar.Value)
+	if !ok {
+		return 0, fmt.Errorf("%s is not a number", simVar.Name)
+	}
+	converted, err := units.ConvertByName(value, simVar.Unit, unit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", simVar.Name, err)
+	}
+	return converted, nil
+}
+
 func (simVar *SimVar) ToString(defaultValue string) string {
 	if simVar.Value != nil {
 		return ValueToString(simVar.Value)
@@ -0,0 +1,108 @@
+package simconnect
+
+import (
+	"fmt"
+	"time"
+)
+
+// FuelTanks lists the fuel tanks in the order of the FUEL TANK simvars, see FuelTankNames.
+var FuelTanks = []string{
+	"CENTER", "CENTER2", "CENTER3",
+	"LEFT MAIN", "LEFT AUX", "LEFT TIP",
+	"RIGHT MAIN", "RIGHT AUX", "RIGHT TIP",
+	"EXTERNAL1", "EXTERNAL2",
+}
+
+// FuelTankNames returns the simvar names of a fuel tank quantity for all FuelTanks,
+// e.g. "FUEL TANK CENTER LEVEL", "FUEL TANK CENTER2 LEVEL", ... for "LEVEL".
+func FuelTankNames(quantity string) []string {
+	names := make([]string, len(FuelTanks))
+	for i, tank := range FuelTanks {
+		names[i] = "FUEL TANK " + tank + " " + quantity
+	}
+	return names
+}
+
+// VarArray is a typed handle to a family of simvars which are read as one value, e.g. the N1 of all engines.
+type VarArray[T VarType] struct {
+	Vars []*Var[T]
+}
+
+// AddIndexedVar adds the first count indices of an indexed simvar, e.g. AddIndexedVar[float64](mate, "ENG N1 RPM", "percent", 4).
+// The indices are checked against the catalog, see SimVarName.Family and SimVarName.Validate.
+func AddIndexedVar[T VarType](mate *SimMate, name, unit string, count int, opts ...SimVarOption) (*VarArray[T], error) {
+	parsed, err := ParseSimVarName(name)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Indexed() {
+		return nil, fmt.Errorf("%s: family name must not carry an index", name)
+	}
+	family, err := parsed.Family(count)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(family))
+	for i, member := range family {
+		names[i] = member.String()
+	}
+	return AddVarArray[T](mate, names, unit, opts...)
+}
+
+// AddVarArray adds simvars which share a unit as one array, e.g. the tanks of FuelTankNames("LEVEL").
+// If one of the simvars is rejected, the handles created so far are removed again, see Var.Remove.
+func AddVarArray[T VarType](mate *SimMate, names []string, unit string, opts ...SimVarOption) (*VarArray[T], error) {
+	array := &VarArray[T]{Vars: make([]*Var[T], 0, len(names))}
+	for _, name := range names {
+		v, err := AddVar[T](mate, name, unit, opts...)
+		if err != nil {
+			array.Remove()
+			return nil, err
+		}
+		array.Vars = append(array.Vars, v)
+	}
+	return array, nil
+}
+
+// Len returns the number of simvars.
+func (a *VarArray[T]) Len() int {
+	return len(a.Vars)
+}
+
+// Get returns the latest values in the order the simvars were added and the time the oldest of them was received.
+// ok is false unless all simvars have a value.
+func (a *VarArray[T]) Get() (values []T, updated time.Time, ok bool) {
+	values = make([]T, len(a.Vars))
+	for i, v := range a.Vars {
+		value, t, exists := v.Get()
+		if !exists {
+			return nil, time.Time{}, false
+		}
+		values[i] = value
+		if i == 0 || t.Before(updated) {
+			updated = t
+		}
+	}
+	return values, updated, true
+}
+
+// GetIn returns the latest values converted to the given unit, see Var.GetIn.
+func (a *VarArray[T]) GetIn(unit string) ([]float64, error) {
+	values := make([]float64, len(a.Vars))
+	for i, v := range a.Vars {
+		value, _, err := v.GetIn(unit)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Remove removes the handles to all simvars, see Var.Remove.
+func (a *VarArray[T]) Remove() {
+	for _, v := range a.Vars {
+		v.Remove()
+	}
+	a.Vars = nil
+}
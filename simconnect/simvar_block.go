@@ -0,0 +1,331 @@
+package simconnect
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxSimVarBlockSize is the number of data bytes SimMate packs into one data definition.
+const maxSimVarBlockSize = 4096
+
+// simVarBlock is a data definition shared by several simvars.
+// It is requested as a whole and the returned data block is split back into the simvars.
+type simVarBlock struct {
+	defineID   DWord
+	requestID  DWord
+	vars       []*SimVar
+	removed    map[*SimVar]bool // removed simvars which keep their slot until the block is registered again
+	size       int
+	registered bool
+	dirty      bool // a simvar was removed, the definition needs to be rebuilt
+	pending    bool
+	timestamp  int64
+	period     DWord // PeriodNever if the block is polled
+	flags      DWord
+	subscribed bool
+	interval   time.Duration // polling interval, 0 for every tick
+	priority   int
+	due        time.Time // when the block is polled next, zero if it hasn't been polled yet
+}
+
+// simVarGroup is what simvars must have in common to share a block.
+type simVarGroup struct {
+	period   DWord
+	flags    DWord
+	interval time.Duration
+	priority int
+}
+
+func newSimVarBlock(group simVarGroup) *simVarBlock {
+	block := &simVarBlock{
+		defineID: NewDefineID(),
+		vars:     make([]*SimVar, 0),
+		period:   group.period,
+		flags:    group.flags,
+		interval: group.interval,
+		priority: group.priority,
+	}
+	if block.flags&DataRequestFlagChanged != 0 {
+		// only send the datums which changed rather than the whole block
+		block.flags |= DataRequestFlagTagged
+	}
+	return block
+}
+
+// fits reports whether a datum of the given size can be appended. A STRINGV (size < 0) always gets a block of its own
+// because the offsets of the datums behind it would be unknown.
+func (block *simVarBlock) fits(size int) bool {
+	if len(block.vars) == 0 {
+		return true
+	}
+	if size < 0 || block.size < 0 {
+		return false
+	}
+	return block.size+size <= maxSimVarBlockSize
+}
+
+func (block *simVarBlock) add(simVar *SimVar) {
+	size := DataTypeSize(simVar.DataType)
+	simVar.BlockID = block.defineID
+	simVar.Offset = DWord(block.size)
+	if size < 0 {
+		block.size = -1
+	} else {
+		block.size += size
+	}
+	block.vars = append(block.vars, simVar)
+}
+
+// remove marks the simvar for removal. It keeps its slot until the block is registered again, because the simulator
+// goes on sending data in the old layout until then, e.g. tagged data whose datum IDs are the indices of the slots.
+func (block *simVarBlock) remove(simVar *SimVar) {
+	for _, v := range block.vars {
+		if v == simVar {
+			if block.removed == nil {
+				block.removed = make(map[*SimVar]bool)
+			}
+			block.removed[simVar] = true
+			block.dirty = true
+			return
+		}
+	}
+}
+
+// empty reports whether all simvars were removed.
+func (block *simVarBlock) empty() bool {
+	return len(block.vars) == len(block.removed)
+}
+
+// register adds all datums to the data definition and subscribes to it if the block isn't polled.
+// A registered definition is unsubscribed, cleared and rebuilt.
+func (block *simVarBlock) register(simco *SimConnect) error {
+	if err := block.unsubscribe(simco); err != nil {
+		return err
+	}
+	if block.registered {
+		if err := simco.ClearDataDefinition(block.defineID); err != nil {
+			return err
+		}
+		block.registered = false
+	}
+	vars := block.vars
+	block.vars = make([]*SimVar, 0, len(vars))
+	block.size = 0
+	block.dirty = false
+	block.pending = false
+	for _, simVar := range vars {
+		if block.removed[simVar] {
+			continue
+		}
+		block.add(simVar)
+		simVar.Registered = false
+	}
+	block.removed = nil
+	for i, simVar := range block.vars {
+		if err := simco.addToDataDefinition(block.defineID, simVar.Name, simVar.Unit, simVar.DataType, 0, DWord(i)); err != nil {
+			// don't leave a partial definition behind, the next attempt would add the datums twice
+			simco.ClearDataDefinition(block.defineID)
+			return err
+		}
+	}
+	for _, simVar := range block.vars {
+		simVar.Registered = true
+		simVar.Pending = false
+	}
+	block.registered = true
+	return block.subscribe(simco)
+}
+
+func (block *simVarBlock) subscribe(simco *SimConnect) error {
+	if block.period == PeriodNever {
+		return nil
+	}
+	block.requestID = NewRequestID()
+	if err := simco.RequestDataOnSimObject(block.requestID, block.defineID, ObjectIDUser, block.period, block.flags); err != nil {
+		return err
+	}
+	block.subscribed = true
+	for _, simVar := range block.vars {
+		simVar.RequestID = block.requestID
+		simVar.Pending = true
+	}
+	return nil
+}
+
+// unsubscribe stops the simulator from sending the block.
+func (block *simVarBlock) unsubscribe(simco *SimConnect) error {
+	if !block.subscribed {
+		return nil
+	}
+	if err := simco.RequestDataOnSimObject(block.requestID, block.defineID, ObjectIDUser, PeriodNever, 0); err != nil {
+		return err
+	}
+	block.subscribed = false
+	return nil
+}
+
+// decode splits the data block into the values of the simvars. The datum ID of a simvar is its index in the block.
+// Tagged data only contains some of the simvars, the values of the others are nil.
+func (block *simVarBlock) decode(recv *RecvSimObjectData, data []byte) ([]interface{}, error) {
+	values := make([]interface{}, len(block.vars))
+	if recv.Flags&DataRequestFlagTagged != 0 {
+		datums, err := DecodeTagged(data, recv.DefineCount, func(datumID DWord) (DWord, bool) {
+			if int(datumID) >= len(block.vars) {
+				return DataTypeInvalid, false
+			}
+			return block.vars[datumID].DataType, true
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, datum := range datums {
+			values[datum.DatumID] = datum.Value
+		}
+		return values, nil
+	}
+	for i, simVar := range block.vars {
+		if int(simVar.Offset) > len(data) {
+			return nil, fmt.Errorf("%s at offset %d, got %d bytes: %w", simVar.Name, simVar.Offset, len(data), ErrShortBuffer)
+		}
+		value, err := DecodeValue(data[simVar.Offset:], simVar.DataType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", simVar.Name, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// packSimVars puts all simvars which aren't in a block yet into new blocks and (re)registers blocks as needed.
+// It returns the number of registered simvars.
+func (mate *SimMate) packSimVars() (int, error) {
+	open := make(map[simVarGroup]*simVarBlock)
+	for _, simVar := range mate.simVarManager.SimVars() {
+		if simVar.BlockID != 0 || simVar.Derived() {
+			continue
+		}
+		group := simVarGroup{simVar.Period, simVar.Flags, 0, 0}
+		if !simVar.Subscribed() {
+			group.interval, group.priority = simVar.Interval, simVar.Priority
+		}
+		size := DataTypeSize(simVar.DataType)
+		block := open[group]
+		if block == nil || !block.fits(size) {
+			block = newSimVarBlock(group)
+			mate.blocks[block.defineID] = block
+			open[group] = block
+		}
+		block.add(simVar)
+	}
+
+	count := 0
+	for defineID, block := range mate.blocks {
+		if block.registered && !block.dirty {
+			continue
+		}
+		if block.empty() {
+			block.unsubscribe(&mate.SimConnect)
+			if block.registered {
+				mate.ClearDataDefinition(block.defineID)
+			}
+			delete(mate.blocks, defineID)
+			continue
+		}
+		if err := block.register(&mate.SimConnect); err != nil {
+			return count, err
+		}
+		count += len(block.vars)
+	}
+	return count, nil
+}
+
+// inFlight reports whether a request of the block is pending and hasn't timed out yet.
+func (block *simVarBlock) inFlight(timestamp int64) bool {
+	return block.pending && timestamp-block.timestamp < simVarRequestTimeout
+}
+
+// isDue reports whether the polled block is to be requested on the tick at now.
+// Due times are compared with a tolerance of half a tick, so a late tick doesn't make the block skip the next one.
+func (block *simVarBlock) isDue(now time.Time, tick time.Duration) bool {
+	return block.due.IsZero() || !now.Add(tick/2).Before(block.due)
+}
+
+// schedule sets the due time after the block was requested on the tick at now. The first request of a block
+// is made right away, afterwards blocks with the same interval are spread over the ticks of their interval
+// by the given slot rather than all being requested on the same tick.
+func (block *simVarBlock) schedule(now time.Time, tick time.Duration, slot int) {
+	interval := block.interval
+	if interval < tick {
+		interval = tick
+	}
+	if block.due.IsZero() {
+		offset := time.Duration(0)
+		if tick > 0 {
+			if slots := int(interval / tick); slots > 1 {
+				offset = time.Duration(slot%slots) * tick
+			}
+		}
+		block.due = now.Add(interval + offset)
+		return
+	}
+	block.due = block.due.Add(interval)
+	if block.due.Before(now) {
+		block.due = now.Add(interval)
+	}
+}
+
+// requestBlock requests the data of the block, unless there is a request pending which hasn't timed out yet.
+// A block whose request fails isn't marked pending.
+func (mate *SimMate) requestBlock(block *simVarBlock, timestamp int64) error {
+	const radiusMeters = 0
+	if block.inFlight(timestamp) {
+		return nil
+	}
+	if !block.pending {
+		block.requestID = NewRequestID()
+	}
+	if err := mate.RequestDataOnSimObjectType(block.requestID, block.defineID, radiusMeters, SimObjectTypeUser); err != nil {
+		return err
+	}
+	block.timestamp = timestamp
+	block.pending = true
+	for _, simVar := range block.vars {
+		simVar.RequestID = block.requestID
+		simVar.Timestamp = timestamp
+		simVar.Pending = true
+	}
+	return nil
+}
+
+// updateBlock stores the values of a received data block, sampled at the given time of the dispatcher clock.
+// It returns the updated simvars and their values.
+func (mate *SimMate) updateBlock(recv *RecvSimObjectData, data []byte, sampled time.Time) ([]*SimVar, []interface{}, error) {
+	block, exists := mate.blocks[recv.DefineID]
+	if !exists || block.requestID != recv.RequestID || !(block.pending || block.subscribed) {
+		return nil, nil, nil
+	}
+	values, err := block.decode(recv, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	block.pending = false
+	updated := make([]*SimVar, 0, len(block.vars))
+	updatedValues := make([]interface{}, 0, len(block.vars))
+	for i, simVar := range block.vars {
+		if values[i] == nil || block.removed[simVar] {
+			continue
+		}
+		if _, ok := mate.simVarManager.UpdateAt(recv.RequestID, simVar.DefineID, values[i], sampled); ok {
+			// subscriptions keep waiting for data
+			simVar.Pending = block.subscribed
+			updated = append(updated, simVar)
+			updatedValues = append(updatedValues, simVar.Value)
+			if history, exists := mate.histories[simVar.DefineID]; exists {
+				if value, ok := ValueToNumber(simVar.Value); ok {
+					history.Add(value, sampled)
+				}
+			}
+		}
+	}
+	return updated, updatedValues, nil
+}
@@ -0,0 +1,188 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBlock(flags DWord, vars ...*SimVar) *simVarBlock {
+	block := newSimVarBlock(simVarGroup{period: PeriodSimFrame, flags: flags})
+	for _, simVar := range vars {
+		block.add(simVar)
+	}
+	return block
+}
+
+func TestSimVarBlockDecodeTagged(t *testing.T) {
+	block := newTestBlock(DataRequestFlagChanged,
+		NewSimVar(1, "PLANE ALTITUDE", "feet", DataTypeFloat64),
+		NewSimVar(2, "GEAR HANDLE POSITION", "bool", DataTypeInt32),
+		NewSimVar(3, "AIRSPEED INDICATED", "knots", DataTypeFloat64),
+	)
+	if block.flags&DataRequestFlagTagged == 0 {
+		t.Fatal("a block requested on change isn't tagged")
+	}
+	recv := &RecvSimObjectData{Flags: block.flags, DefineCount: 1}
+	values, err := block.decode(recv, taggedBytes(2, 120.0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 || values[0] != nil || values[1] != nil || values[2] != float64(120) {
+		t.Errorf("values = %v, want [<nil> <nil> 120]", values)
+	}
+
+	recv.DefineCount = 1
+	if _, err := block.decode(recv, taggedBytes(3, 1.0)); err == nil {
+		t.Error("datum ID beyond the block was accepted")
+	}
+	recv.DefineCount = 2
+	if _, err := block.decode(recv, taggedBytes(0, 1.0)); err == nil {
+		t.Error("truncated block was accepted")
+	}
+}
+
+func TestSimVarBlockDecodeTaggedStringV(t *testing.T) {
+	block := newTestBlock(DataRequestFlagChanged,
+		NewSimVar(1, "TITLE", "", DataTypeStringV),
+	)
+	recv := &RecvSimObjectData{Flags: block.flags, DefineCount: 1}
+	values, err := block.decode(recv, taggedBytes(0, "Cessna Skyhawk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "Cessna Skyhawk" {
+		t.Errorf("value = %v", values[0])
+	}
+}
+
+func TestSimVarBlockDecodeUntagged(t *testing.T) {
+	block := newTestBlock(0,
+		NewSimVar(1, "PLANE ALTITUDE", "feet", DataTypeFloat64),
+		NewSimVar(2, "GEAR HANDLE POSITION", "bool", DataTypeInt32),
+	)
+	data := newGolden(12).float64(0, 3000).dword(8, 1)
+	values, err := block.decode(&RecvSimObjectData{DefineCount: 2}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != float64(3000) || values[1] != int32(1) {
+		t.Errorf("values = %v", values)
+	}
+	if _, err := block.decode(&RecvSimObjectData{DefineCount: 2}, data[:10]); err == nil {
+		t.Error("truncated block was accepted")
+	}
+}
+
+// pollTick runs the scheduler on the tick at now and returns the define IDs of the requested blocks in order.
+// The data of every block arrives right away unless keepPending is set.
+func pollTick(t *testing.T, mate *SimMate, backend *ScriptedBackend, now time.Time, tick time.Duration, keepPending bool) []DWord {
+	t.Helper()
+	if err := mate.requestSimObjectData(now, tick); err != nil {
+		t.Fatal(err)
+	}
+	requests := backend.CallsNamed(scRequestDataOnSimObjectType)
+	defineIDs := make([]DWord, len(requests))
+	for i, call := range requests {
+		defineIDs[i] = call.Args[1].(DWord)
+	}
+	backend.Reset()
+	if !keepPending {
+		for _, block := range mate.blocks {
+			block.pending = false
+		}
+	}
+	return defineIDs
+}
+
+// blockOf returns the define ID of the block the simvar was packed into.
+func blockOf(t *testing.T, mate *SimMate, defineID DWord) DWord {
+	t.Helper()
+	simVar, exists := mate.SimVar(defineID)
+	if !exists || simVar.BlockID == 0 {
+		t.Fatalf("simvar %d isn't packed", defineID)
+	}
+	return simVar.BlockID
+}
+
+func TestScheduleSpreadsBlocksOfAnInterval(t *testing.T) {
+	const tick = 100 * time.Millisecond
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	// the priorities put the simvars into blocks of their own
+	for i, name := range []string{"PLANE ALTITUDE", "PLANE LATITUDE", "PLANE LONGITUDE", "AIRSPEED INDICATED"} {
+		mate.AddSimVar(name, "", DataTypeFloat64, WithInterval(4*tick), WithPriority(i))
+	}
+	start := time.Now()
+	if first := pollTick(t, mate, backend, start, tick, false); len(first) != 4 {
+		t.Fatalf("first tick requested %d blocks, want all 4", len(first))
+	}
+
+	seen := make(map[DWord]bool)
+	for i := 1; i <= 8; i++ {
+		requested := pollTick(t, mate, backend, start.Add(time.Duration(i)*tick), tick, false)
+		want := 1
+		if i < 4 {
+			want = 0
+		}
+		if len(requested) != want {
+			t.Fatalf("tick %d requested %v, want %d blocks", i, requested, want)
+		}
+		for _, defineID := range requested {
+			if i < 8 && seen[defineID] {
+				t.Errorf("tick %d requested block %d twice within its interval", i, defineID)
+			}
+			seen[defineID] = true
+		}
+	}
+}
+
+func TestSchedulePriorityAndMaxInFlight(t *testing.T) {
+	const tick = 100 * time.Millisecond
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	mate.MaxInFlight = 2
+	low := mate.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	high := mate.AddSimVar("PLANE LATITUDE", "degrees", DataTypeFloat64, WithPriority(10))
+	medium := mate.AddSimVar("PLANE LONGITUDE", "degrees", DataTypeFloat64, WithPriority(5))
+	now := time.Now()
+
+	requested := pollTick(t, mate, backend, now, tick, true)
+	if len(requested) != 2 || requested[0] != blockOf(t, mate, high) || requested[1] != blockOf(t, mate, medium) {
+		t.Fatalf("requested %v, want the high and the medium priority block", requested)
+	}
+	now = now.Add(tick)
+	if requested := pollTick(t, mate, backend, now, tick, true); len(requested) != 0 {
+		t.Fatalf("requested %v with the budget used up", requested)
+	}
+
+	// the data of the high priority block arrives, which frees one request
+	mate.blocks[blockOf(t, mate, high)].pending = false
+	now = now.Add(tick)
+	if requested := pollTick(t, mate, backend, now, tick, false); len(requested) != 1 || requested[0] != blockOf(t, mate, high) {
+		t.Fatalf("requested %v, want the high priority block ahead of the waiting low priority one", requested)
+	}
+
+	mate.MaxInFlight = 0
+	now = now.Add(tick)
+	if requested := pollTick(t, mate, backend, now, tick, false); len(requested) != 3 || requested[2] != blockOf(t, mate, low) {
+		t.Fatalf("requested %v without a budget, want all 3 blocks by priority", requested)
+	}
+}
+
+func TestScheduleWithoutTick(t *testing.T) {
+	backend := NewScriptedBackend()
+	mate := NewSimMateWithBackend(backend)
+	mate.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64, WithInterval(time.Second))
+	mate.AddSimVar("PLANE LATITUDE", "degrees", DataTypeFloat64, WithInterval(time.Second), WithPriority(1))
+	if requested := pollTick(t, mate, backend, time.Now(), 0, false); len(requested) != 2 {
+		t.Errorf("requested %v, want both blocks", requested)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := mate.Run(ctx, 0, nil); err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run without an interval = %v, want an error right away", err)
+	}
+}
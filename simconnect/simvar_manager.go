@@ -4,11 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"sync"
+	"time"
 )
 
 type SimVarManager struct {
 	Vars    []*SimVar
-	nameMap map[string]*SimVar
+	nameMap map[string]*SimVar // keyed on the normalized name, see ParseSimVarName
 	idMap   map[DWord]*SimVar
 	mutex   sync.Mutex
 }
@@ -31,6 +32,8 @@ func (mgr *SimVarManager) SimVars() []*SimVar {
 	return mgr.Vars
 }
 
+// Add adds a simvar unless a simvar with the same name was already added and returns its define ID.
+// Names are compared normalized, so "ENG RPM:1" and "eng rpm:1" are the same simvar.
 func (mgr *SimVarManager) Add(name string, unit string, dataType DWord) DWord {
 	mgr.mutex.Lock()
 	defer mgr.mutex.Unlock()
@@ -42,7 +45,7 @@ func (mgr *SimVarManager) Add(name string, unit string, dataType DWord) DWord {
 	defineID := NewDefineID()
 	simVar := NewSimVar(defineID, name, unit, dataType)
 	mgr.Vars = append(mgr.Vars, simVar)
-	mgr.nameMap[name] = simVar
+	mgr.nameMap[simVarKey(name)] = simVar
 	mgr.idMap[defineID] = simVar
 	return defineID
 }
@@ -54,7 +57,7 @@ func (mgr *SimVarManager) Remove(defineID DWord) bool {
 	if !exists {
 		return false
 	}
-	delete(mgr.nameMap, simVar.Name)
+	delete(mgr.nameMap, simVarKey(simVar.Name))
 	delete(mgr.idMap, simVar.DefineID)
 	vars := mgr.Vars
 	for i, simVar := range vars {
@@ -74,6 +77,11 @@ func (mgr *SimVarManager) GetSimVar(defineID DWord) (*SimVar, bool) {
 }
 
 func (mgr *SimVarManager) Update(requestID, defineID DWord, value interface{}) (*SimVar, bool) {
+	return mgr.UpdateAt(requestID, defineID, value, time.Now())
+}
+
+// UpdateAt works like Update, but records the given sample time as the simvar's LastUpdate.
+func (mgr *SimVarManager) UpdateAt(requestID, defineID DWord, value interface{}, timestamp time.Time) (*SimVar, bool) {
 	mgr.mutex.Lock()
 	defer mgr.mutex.Unlock()
 	if simVar, ok := mgr.simVarWithID(defineID); ok {
@@ -84,6 +92,7 @@ func (mgr *SimVarManager) Update(requestID, defineID DWord, value interface{}) (
 				simVar.Value = mgr.ToString(simVar.DataType, value)
 			}
 			simVar.UpdateCount++
+			simVar.LastUpdate = timestamp
 			return simVar, true
 		}
 	}
@@ -122,16 +131,19 @@ func (mgr *SimVarManager) ToString(dataType DWord, value interface{}) string {
 func (mgr *SimVarManager) SimVarDump(indent string) []string {
 	dump := make([]string, 0)
 	for i, simVar := range mgr.Vars {
-		str := fmt.Sprintf("%s%02d: name: %s unit: %s value: %v type: %s updates: %d reqId: %d defid: %d registered: %v pending: %v",
+		str := fmt.Sprintf("%s%02d: name: %s unit: %s value: %v type: %s updates: %d reqId: %d defid: %d block: %d registered: %v pending: %v",
 			indent, i+1, simVar.Name, simVar.Unit, simVar.Value, DataTypeToString(simVar.DataType), simVar.UpdateCount,
-			simVar.RequestID, simVar.DefineID, simVar.Registered, simVar.Pending)
+			simVar.RequestID, simVar.DefineID, simVar.BlockID, simVar.Registered, simVar.Pending)
+		if simVar.Derived() {
+			str += fmt.Sprintf(" expr: %s", simVar.Expression)
+		}
 		dump = append(dump, str)
 	}
 	return dump
 }
 
 func (mgr *SimVarManager) simVarWithName(name string) (*SimVar, bool) {
-	simVar, exists := mgr.nameMap[name]
+	simVar, exists := mgr.nameMap[simVarKey(name)]
 	return simVar, exists
 }
 
@@ -0,0 +1,112 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidIndex is returned for an index a simvar doesn't take, e.g. "ENG N1 RPM:9" or "PLANE ALTITUDE:1".
+var ErrInvalidIndex = errors.New("invalid index")
+
+// SimVarName is a simvar name split into its base name and index, e.g. "GENERAL ENG RPM" and 1 for "GENERAL ENG RPM:1".
+// Index is 0 if the name has no index. HasIndex tells "EXIT OPEN:0" apart from "EXIT OPEN".
+type SimVarName struct {
+	Name     string
+	Index    int
+	HasIndex bool
+}
+
+// ParseSimVarName parses a simvar name with an optional index. The base name is normalized to upper case
+// with single spaces, so "eng  rpm:1" and "ENG RPM:1" are the same simvar.
+// Local variables like "L:MyVar" are taken verbatim apart from surrounding white space.
+func ParseSimVarName(name string) (SimVarName, error) {
+	name = strings.TrimSpace(name)
+	if isLocalVarName(name) {
+		return SimVarName{Name: name}, nil
+	}
+	parsed := SimVarName{}
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(name[i+1:]))
+		if err != nil || n < 0 {
+			return SimVarName{}, fmt.Errorf("%q: %w %q", name, ErrInvalidIndex, name[i+1:])
+		}
+		name, parsed.Index, parsed.HasIndex = name[:i], n, true
+	}
+	parsed.Name = strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+	if parsed.Name == "" {
+		return SimVarName{}, fmt.Errorf("empty simvar name")
+	}
+	return parsed, nil
+}
+
+// NewSimVarName returns the name of the simvar with the given index, e.g. NewSimVarName("ENG N1 RPM", 2).
+func NewSimVarName(name string, index int) SimVarName {
+	return SimVarName{Name: strings.Join(strings.Fields(strings.ToUpper(name)), " "), Index: index, HasIndex: true}
+}
+
+// String returns the name as SimConnect expects it, e.g. "ENG N1 RPM:2".
+func (n SimVarName) String() string {
+	if !n.Indexed() {
+		return n.Name
+	}
+	return n.Name + ":" + strconv.Itoa(n.Index)
+}
+
+// Indexed reports whether the name carries an index.
+func (n SimVarName) Indexed() bool {
+	return n.HasIndex || n.Index != 0
+}
+
+// Validate checks the index against the catalog: an index is only accepted for indexed simvars
+// and has to be within the range of the simvar, e.g. 1 to 4 for engines or from 0 for exits.
+// Simvars which aren't in the catalog and local variables are not checked.
+func (n SimVarName) Validate() error {
+	if !n.Indexed() {
+		return nil
+	}
+	info, exists := LookupSimVar(n.Name)
+	if !exists {
+		return nil
+	}
+	if !info.Indexed {
+		return fmt.Errorf("%s takes no index: %w %d", info.Name, ErrInvalidIndex, n.Index)
+	}
+	if n.Index < info.MinIndex || (info.MaxIndex > 0 && n.Index > info.MaxIndex) {
+		if info.MaxIndex > 0 {
+			return fmt.Errorf("%s takes an index from %d to %d: %w %d", info.Name, info.MinIndex, info.MaxIndex, ErrInvalidIndex, n.Index)
+		}
+		return fmt.Errorf("%s takes an index from %d: %w %d", info.Name, info.MinIndex, ErrInvalidIndex, n.Index)
+	}
+	return nil
+}
+
+// Family returns the names of the first count indices of an indexed simvar, e.g. one name per engine.
+// The indices start at 1, or at the lowest index of the catalog, e.g. 0 for exits.
+func (n SimVarName) Family(count int) ([]SimVarName, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("%s: family of %d simvars", n.Name, count)
+	}
+	first := 1
+	if info, exists := LookupSimVar(n.Name); exists && info.Indexed {
+		first = info.MinIndex
+	}
+	names := make([]SimVarName, count)
+	for i := range names {
+		names[i] = NewSimVarName(n.Name, first+i)
+		if err := names[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// simVarKey normalizes a simvar name for lookups, see ParseSimVarName. Names which don't parse are used verbatim.
+func simVarKey(name string) string {
+	parsed, err := ParseSimVarName(name)
+	if err != nil {
+		return name
+	}
+	return parsed.String()
+}
@@ -0,0 +1,108 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSimVarName(t *testing.T) {
+	tests := []struct {
+		name string
+		want SimVarName
+	}{
+		{"PLANE ALTITUDE", SimVarName{Name: "PLANE ALTITUDE"}},
+		{" eng  n1 rpm:2 ", SimVarName{Name: "ENG N1 RPM", Index: 2, HasIndex: true}},
+		{"EXIT OPEN:0", SimVarName{Name: "EXIT OPEN", Index: 0, HasIndex: true}},
+		{"L:MyVar", SimVarName{Name: "L:MyVar"}},
+	}
+	for _, test := range tests {
+		got, err := ParseSimVarName(test.name)
+		if err != nil || got != test.want {
+			t.Errorf("ParseSimVarName(%q) = %+v, %v, want %+v", test.name, got, err, test.want)
+		}
+	}
+	for _, name := range []string{"ENG N1 RPM:-1", "ENG N1 RPM:one", ":1", ""} {
+		if got, err := ParseSimVarName(name); err == nil {
+			t.Errorf("ParseSimVarName(%q) = %+v, want an error", name, got)
+		}
+	}
+}
+
+func TestSimVarKeyKeepsIndexZero(t *testing.T) {
+	if simVarKey("exit open:0") != "EXIT OPEN:0" || simVarKey("EXIT OPEN") != "EXIT OPEN" {
+		t.Errorf("keys %q and %q", simVarKey("exit open:0"), simVarKey("EXIT OPEN"))
+	}
+}
+
+func TestSimVarNameValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"ENG N1 RPM", true},
+		{"ENG N1 RPM:1", true},
+		{"ENG N1 RPM:4", true},
+		{"ENG N1 RPM:0", false},
+		{"ENG N1 RPM:5", false},
+		{"KOHLSMAN SETTING MB:1", true},
+		{"ELECTRICAL MAIN BUS VOLTAGE:1", true},
+		{"ELECTRICAL GENALT BUS VOLTAGE:1", true},
+		{"PAYLOAD STATION WEIGHT:12", true},
+		{"PAYLOAD STATION WEIGHT:0", false},
+		{"EXIT OPEN:0", true},
+		{"AUTOPILOT ALTITUDE LOCK VAR:4", false},
+		{"PLANE ALTITUDE:1", false},
+		{"PLANE ALTITUDE:0", false},
+		{"SOME SIMVAR THE CATALOG MISSES:3", true},
+	}
+	for _, test := range tests {
+		parsed, err := ParseSimVarName(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = parsed.Validate()
+		if test.valid && err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidIndex) {
+			t.Errorf("%s: err = %v, want ErrInvalidIndex", test.name, err)
+		}
+	}
+}
+
+func TestSimVarNameFamily(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		want  []string
+	}{
+		{"ENG N1 RPM", 2, []string{"ENG N1 RPM:1", "ENG N1 RPM:2"}},
+		{"EXIT OPEN", 2, []string{"EXIT OPEN:0", "EXIT OPEN:1"}},
+		{"ENG N1 RPM", 5, nil},
+		{"ENG N1 RPM", 0, nil},
+		{"ENG N1 RPM", -1, nil},
+	}
+	for _, test := range tests {
+		family, err := NewSimVarName(test.name, 0).Family(test.count)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("%s x %d: got %v, want an error", test.name, test.count, family)
+			}
+			continue
+		}
+		if err != nil || len(family) != len(test.want) {
+			t.Errorf("%s x %d: got %v, %v", test.name, test.count, family, err)
+			continue
+		}
+		for i, member := range family {
+			if member.String() != test.want[i] {
+				t.Errorf("%s x %d: member %d is %s, want %s", test.name, test.count, i, member, test.want[i])
+			}
+		}
+	}
+
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	if _, err := AddIndexedVar[float64](mate, "ENG N1 RPM", "percent", -1); err == nil {
+		t.Error("AddIndexedVar with a negative count succeeded")
+	}
+}
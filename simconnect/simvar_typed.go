@@ -0,0 +1,183 @@
+package simconnect
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// VarType lists the Go types a Var can hold.
+type VarType interface {
+	int32 | int64 | float32 | float64 | string | LatLogAlt | XYZ | InitPosition | MarkerState | Waypoint
+}
+
+// Var is a typed handle to a simvar managed by a SimMate.
+type Var[T VarType] struct {
+	DefineID DWord
+	Name     string
+	Unit     string
+	DataType DWord
+	mate     *SimMate
+	mutex    sync.Mutex
+	watches  []*varWatch[T]
+	removed  bool
+}
+
+// AddVar adds a simvar to the SimMate and returns a typed handle to it.
+// The datatype is derived from T, strings are requested as string256. See SimMate.AddSimVar for the options.
+func AddVar[T VarType](mate *SimMate, name, unit string, opts ...SimVarOption) (*Var[T], error) {
+	var zero T
+	return AddVarWithDataType[T](mate, name, unit, dataTypeOf(reflect.TypeOf(zero)), opts...)
+}
+
+// AddVarWithDataType works like AddVar, but requests the simvar with the given DataType*, e.g. DataTypeString64 for a string.
+func AddVarWithDataType[T VarType](mate *SimMate, name, unit string, dataType DWord, opts ...SimVarOption) (*Var[T], error) {
+	var zero T
+	t := reflect.TypeOf(zero)
+	if dataTypeOf(t) != dataType && !(t.Kind() == reflect.String && IsStringDataType(dataType)) {
+		return nil, fmt.Errorf("%s can't hold a %s", t, DataTypeToString(dataType))
+	}
+	defineID, err := mate.acquireVar(name, unit, dataType, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Var[T]{
+		DefineID: defineID,
+		Name:     name,
+		Unit:     unit,
+		DataType: dataType,
+		mate:     mate,
+	}, nil
+}
+
+// Get returns the latest value and when it was received. ok is false if there is no value yet.
+func (v *Var[T]) Get() (value T, updated time.Time, ok bool) {
+	simVar, exists := v.mate.SimVar(v.DefineID)
+	if !exists || simVar.Value == nil {
+		return value, updated, false
+	}
+	value, ok = simVar.Value.(T)
+	return value, simVar.LastUpdate, ok
+}
+
+// GetIn returns the latest value converted to the given unit and when it was received, see SimVar.ValueIn.
+func (v *Var[T]) GetIn(unit string) (value float64, updated time.Time, err error) {
+	simVar, exists := v.mate.SimVar(v.DefineID)
+	if !exists {
+		return 0, updated, fmt.Errorf("%s: %w", v.Name, ErrNoValue)
+	}
+	value, err = simVar.ValueIn(unit)
+	return value, simVar.LastUpdate, err
+}
+
+// History returns the samples of the simvar if it was added WithHistory, see SimMate.History.
+func (v *Var[T]) History() (*History, bool) {
+	return v.mate.History(v.DefineID)
+}
+
+// Set sets the simvar on the user object.
+func (v *Var[T]) Set(value T) error {
+	return v.mate.SetSimObjectData(v.Name, v.Unit, value, v.DataType)
+}
+
+// Watch returns a channel which receives every new value.
+// The channel holds one value; if the receiver falls behind, older values are dropped in favor of the latest one.
+// The channel is closed by Remove.
+func (v *Var[T]) Watch() <-chan T {
+	w := &varWatch[T]{ch: make(chan T, 1)}
+	w.unwatch = v.mate.watch(v.DefineID, func(value interface{}, sampled time.Time) {
+		if typed, ok := value.(T); ok {
+			w.send(typed)
+		}
+	})
+	v.mutex.Lock()
+	v.watches = append(v.watches, w)
+	v.mutex.Unlock()
+	return w.ch
+}
+
+// WatchRule calls fn whenever a new value fires the rule, see SimMate.WatchSimVar.
+func (v *Var[T]) WatchRule(rule WatchRule, fn WatchFunc) (func(), error) {
+	return v.mate.WatchSimVar(v.DefineID, rule, fn)
+}
+
+// Remove closes all Watch channels and removes the simvar from the SimMate once no other Var refers to it.
+// A simvar added with AddSimVar before the first Var was created is left to its owner.
+// Remove reports whether the simvar was removed. Calling Remove again does nothing.
+func (v *Var[T]) Remove() bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.removed {
+		return false
+	}
+	v.removed = true
+	for _, w := range v.watches {
+		w.close()
+	}
+	v.watches = nil
+	return v.mate.releaseVar(v.DefineID)
+}
+
+// acquireVar adds the simvar for a new Var and counts the handle if a Var created the simvar.
+func (mate *SimMate) acquireVar(name, unit string, dataType DWord, opts ...SimVarOption) (DWord, error) {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	defineID, created, err := mate.addSimVar(name, unit, dataType, opts...)
+	if err != nil {
+		return 0, err
+	}
+	if simVar, _ := mate.simVarManager.simVarWithID(defineID); simVar.DataType != dataType {
+		return 0, fmt.Errorf("%s was already added as %s", name, DataTypeToString(simVar.DataType))
+	}
+	if _, counted := mate.varRefs[defineID]; created || counted {
+		mate.varRefs[defineID]++
+	}
+	return defineID, nil
+}
+
+// releaseVar drops a Var handle and removes the simvar with the last one.
+func (mate *SimMate) releaseVar(defineID DWord) bool {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	refs, counted := mate.varRefs[defineID]
+	if !counted {
+		return false
+	}
+	if refs > 1 {
+		mate.varRefs[defineID] = refs - 1
+		return false
+	}
+	return mate.removeSimVar(defineID)
+}
+
+type varWatch[T VarType] struct {
+	mutex   sync.Mutex
+	ch      chan T
+	closed  bool
+	unwatch func()
+}
+
+// send replaces a value the receiver hasn't picked up yet, so it never blocks.
+func (w *varWatch[T]) send(value T) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case <-w.ch:
+	default:
+	}
+	w.ch <- value
+}
+
+func (w *varWatch[T]) close() {
+	w.unwatch()
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.ch)
+	}
+}
@@ -0,0 +1,113 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVarRemoveSharedSimVar(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	first, err := AddVar[float64](mate, "PLANE ALTITUDE", "feet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := AddVar[float64](mate, "PLANE ALTITUDE", "feet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.DefineID != second.DefineID {
+		t.Fatalf("define IDs %d and %d, want the same simvar", first.DefineID, second.DefineID)
+	}
+
+	if first.Remove() {
+		t.Error("first Remove removed a simvar still in use")
+	}
+	if _, exists := mate.SimVar(second.DefineID); !exists {
+		t.Fatal("simvar is gone while a handle refers to it")
+	}
+	if !second.Remove() {
+		t.Error("last Remove didn't remove the simvar")
+	}
+	if _, exists := mate.SimVar(second.DefineID); exists {
+		t.Error("simvar outlived its last handle")
+	}
+}
+
+func TestVarRemoveTwice(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	first, err := AddVar[float64](mate, "PLANE ALTITUDE", "feet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := AddVar[float64](mate, "PLANE ALTITUDE", "feet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first.Remove()
+	if first.Remove() {
+		t.Error("second Remove of a handle removed the simvar")
+	}
+	if _, exists := mate.SimVar(second.DefineID); !exists {
+		t.Fatal("simvar is gone while a handle refers to it")
+	}
+	if !second.Remove() {
+		t.Error("last Remove didn't remove the simvar")
+	}
+}
+
+func TestVarRemoveLeavesAddSimVar(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	defineID := mate.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	v, err := AddVar[float64](mate, "PLANE ALTITUDE", "feet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Remove() {
+		t.Error("Remove removed a simvar added with AddSimVar")
+	}
+	if _, exists := mate.SimVar(defineID); !exists {
+		t.Error("simvar added with AddSimVar is gone")
+	}
+}
+
+func TestAddVarArrayRollback(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	kept, err := AddVar[float64](mate, "ENG N1 RPM:1", "percent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ownedID := mate.AddSimVar("ENG N1 RPM:2", "percent", DataTypeFloat64)
+
+	names := []string{"ENG N1 RPM:1", "ENG N1 RPM:2", "ENG N1 RPM:3", "ENG N1 RPMS:4"}
+	if _, err := AddVarArray[float64](mate, names, "percent"); !errors.Is(err, ErrUnknownSimVar) {
+		t.Fatalf("err = %v, want ErrUnknownSimVar", err)
+	}
+	if _, exists := mate.SimVar(kept.DefineID); !exists {
+		t.Error("rollback removed a simvar added with AddVar before")
+	}
+	if _, exists := mate.SimVar(ownedID); !exists {
+		t.Error("rollback removed a simvar added with AddSimVar before")
+	}
+	if mate.simVarManager.Count() != 2 {
+		t.Errorf("%d simvars left, want 2", mate.simVarManager.Count())
+	}
+
+	if !kept.Remove() {
+		t.Error("the rollback released a handle it didn't create")
+	}
+}
+
+func TestAddVarDataTypeMismatch(t *testing.T) {
+	mate := NewSimMateWithBackend(NewScriptedBackend())
+	v, err := AddVar[float64](mate, "PLANE ALTITUDE", "feet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddVar[int32](mate, "PLANE ALTITUDE", "feet"); err == nil {
+		t.Fatal("AddVar accepted a simvar added with another datatype")
+	}
+	if !v.Remove() {
+		t.Error("the rejected handle was counted")
+	}
+}
@@ -0,0 +1,134 @@
+package simconnect
+
+import (
+	"sync"
+	"time"
+)
+
+// Snapshot is a consistent copy of all simvars of a SimMate. A snapshot is published when all simvars polled on a tick
+// have been received, when the round times out on the next tick, or when a subscribed simvar arrives in between rounds.
+// Snapshots are shared between readers and must not be modified.
+type Snapshot struct {
+	Sequence uint64           // increases by one with every snapshot
+	Time     time.Time        // when the snapshot was taken, by the clock of the Dispatcher
+	Vars     map[DWord]SimVar // keyed on the define ID, LastUpdate is the sample time of each simvar
+}
+
+// SimVar returns the simvar with the given define ID.
+func (s *Snapshot) SimVar(defineID DWord) (SimVar, bool) {
+	simVar, exists := s.Vars[defineID]
+	return simVar, exists
+}
+
+// Value returns the value of the simvar. ok is false if the simvar is unknown or has no value yet.
+func (s *Snapshot) Value(defineID DWord) (value interface{}, ok bool) {
+	simVar, exists := s.Vars[defineID]
+	return simVar.Value, exists && simVar.Value != nil
+}
+
+// Updated returns when the simvar was received, the zero time if it has no value yet.
+func (s *Snapshot) Updated(defineID DWord) time.Time {
+	return s.Vars[defineID].LastUpdate
+}
+
+// snapshotWatch delivers snapshots to a channel, dropping older ones the receiver hasn't picked up.
+type snapshotWatch struct {
+	mutex  sync.Mutex
+	ch     chan *Snapshot
+	closed bool
+}
+
+func (w *snapshotWatch) send(snapshot *Snapshot) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case <-w.ch:
+	default:
+	}
+	w.ch <- snapshot
+}
+
+func (w *snapshotWatch) close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.ch)
+	}
+}
+
+// Snapshot returns the latest snapshot, nil if none has been published yet.
+func (mate *SimMate) Snapshot() *Snapshot {
+	mate.mutex.Lock()
+	defer mate.mutex.Unlock()
+	return mate.snapshot
+}
+
+// Snapshots returns a channel which receives every new snapshot and a function which closes it.
+// The channel holds one snapshot; if the receiver falls behind, older snapshots are dropped in favor of the latest one.
+func (mate *SimMate) Snapshots() (<-chan *Snapshot, func()) {
+	w := &snapshotWatch{ch: make(chan *Snapshot, 1)}
+	mate.mutex.Lock()
+	mate.snapshotWatches = append(mate.snapshotWatches, w)
+	mate.mutex.Unlock()
+	return w.ch, func() {
+		mate.mutex.Lock()
+		for i, watch := range mate.snapshotWatches {
+			if watch == w {
+				mate.snapshotWatches = append(mate.snapshotWatches[:i:i], mate.snapshotWatches[i+1:]...)
+				break
+			}
+		}
+		mate.mutex.Unlock()
+		w.close()
+	}
+}
+
+// startRound begins a request round awaiting the data of the given blocks. The caller holds the mutex.
+func (mate *SimMate) startRound(blockIDs []DWord) {
+	mate.round = make(map[DWord]bool, len(blockIDs))
+	for _, blockID := range blockIDs {
+		mate.round[blockID] = true
+	}
+}
+
+// completeRound records the data of a block and reports whether a snapshot is due, i.e. a simvar changed
+// and no block of the round is outstanding. The caller holds the mutex.
+func (mate *SimMate) completeRound(blockID DWord) bool {
+	delete(mate.round, blockID)
+	return mate.changed && len(mate.round) == 0
+}
+
+// takeSnapshot copies all simvars into a new snapshot taken at now, if a simvar changed since the last one.
+// The caller holds the mutex.
+func (mate *SimMate) takeSnapshot(now time.Time) *Snapshot {
+	if !mate.changed {
+		return nil
+	}
+	mate.changed = false
+	mate.round = nil
+	mate.sequence++
+	vars := make(map[DWord]SimVar, len(mate.simVarManager.Vars))
+	for _, simVar := range mate.simVarManager.Vars {
+		vars[simVar.DefineID] = *simVar
+	}
+	mate.snapshot = &Snapshot{
+		Sequence: mate.sequence,
+		Time:     now,
+		Vars:     vars,
+	}
+	return mate.snapshot
+}
+
+// publish hands the snapshot to all channels returned by Snapshots.
+func (mate *SimMate) publish(snapshot *Snapshot) {
+	mate.mutex.Lock()
+	watches := append([]*snapshotWatch(nil), mate.snapshotWatches...)
+	mate.mutex.Unlock()
+	for _, w := range watches {
+		w.send(snapshot)
+	}
+}
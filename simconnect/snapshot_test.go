@@ -0,0 +1,100 @@
+package simconnect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundCompletion(t *testing.T) {
+	dataReady := 0
+	m := newTestMate(t, &EventListener{OnDataReady: func() { dataReady++ }})
+	altitude := m.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	speed := m.AddSimVar("AIRSPEED INDICATED", "knots", DataTypeFloat64, WithPriority(1))
+
+	m.tick()
+	m.deliver(t, altitude, float64(3000))
+	if m.Snapshot() != nil || dataReady != 0 {
+		t.Fatal("snapshot taken while a block of the round is outstanding")
+	}
+	m.deliver(t, speed, float64(120))
+	snapshot := m.Snapshot()
+	if snapshot == nil || dataReady != 1 {
+		t.Fatalf("no snapshot after the round completed, OnDataReady called %d times", dataReady)
+	}
+	if snapshot.Sequence != 1 || !snapshot.Time.Equal(m.clock.now) {
+		t.Errorf("snapshot %d at %s, want 1 at %s", snapshot.Sequence, snapshot.Time, m.clock.now)
+	}
+	if value, ok := snapshot.Value(speed); !ok || value != float64(120) {
+		t.Errorf("airspeed = %v, %v", value, ok)
+	}
+
+	m.tick()
+	m.deliver(t, altitude, float64(3100))
+	m.deliver(t, speed, float64(125))
+	if snapshot := m.Snapshot(); snapshot.Sequence != 2 {
+		t.Errorf("second round has sequence %d, want 2", snapshot.Sequence)
+	}
+	if value, _ := snapshot.Value(altitude); value != float64(3000) {
+		t.Errorf("the first snapshot changed to %v", value)
+	}
+}
+
+func TestSnapshotTimesOutOnTheNextTick(t *testing.T) {
+	m := newTestMate(t, nil)
+	altitude := m.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	m.AddSimVar("AIRSPEED INDICATED", "knots", DataTypeFloat64, WithPriority(1))
+
+	m.tick()
+	m.deliver(t, altitude, float64(3000))
+	if m.Snapshot() != nil {
+		t.Fatal("snapshot taken while a block of the round is outstanding")
+	}
+	m.tick()
+	snapshot := m.Snapshot()
+	if snapshot == nil || snapshot.Sequence != 1 || !snapshot.Time.Equal(m.clock.now) {
+		t.Fatalf("snapshot %+v, want one taken on the tick at %s", snapshot, m.clock.now)
+	}
+	if value, ok := snapshot.Value(altitude); !ok || value != float64(3000) {
+		t.Errorf("altitude = %v, %v", value, ok)
+	}
+
+	m.tick()
+	if m.Snapshot().Sequence != 1 {
+		t.Error("a tick without new data took a snapshot")
+	}
+}
+
+func TestSnapshotsChannel(t *testing.T) {
+	m := newTestMate(t, nil)
+	altitude := m.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	snapshots, stop := m.Snapshots()
+
+	for i := 1; i <= 3; i++ {
+		m.tick()
+		m.deliver(t, altitude, float64(1000*i))
+	}
+	select {
+	case snapshot := <-snapshots:
+		if snapshot.Sequence != 3 {
+			t.Errorf("received snapshot %d, want the latest one", snapshot.Sequence)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no snapshot received")
+	}
+	select {
+	case snapshot := <-snapshots:
+		t.Fatalf("received the dropped snapshot %d", snapshot.Sequence)
+	default:
+	}
+
+	stop()
+	stop()
+	if _, ok := <-snapshots; ok {
+		t.Error("channel is open after stop")
+	}
+	m.tick()
+	m.deliver(t, altitude, float64(5000))
+	if m.Snapshot().Sequence != 4 {
+		t.Errorf("sequence %d after stop, want 4", m.Snapshot().Sequence)
+	}
+}
@@ -0,0 +1,281 @@
+package simconnect
+
+import (
+	"sync"
+)
+
+// Names of the system events for SubscribeToSystemEvent.
+// https://docs.flightsimulator.com/html/Programming_Tools/SimConnect/API_Reference/Events_And_Data/SimConnect_SubscribeToSystemEvent.htm
+const (
+	SystemEvent1Sec                  = "1sec"
+	SystemEvent4Sec                  = "4sec"
+	SystemEvent6Hz                   = "6Hz"
+	SystemEventAircraftLoaded        = "AircraftLoaded"
+	SystemEventCrashed               = "Crashed"
+	SystemEventCrashReset            = "CrashReset"
+	SystemEventFlightLoaded          = "FlightLoaded"
+	SystemEventFlightSaved           = "FlightSaved"
+	SystemEventFlightPlanActivated   = "FlightPlanActivated"
+	SystemEventFlightPlanDeactivated = "FlightPlanDeactivated"
+	SystemEventFrame                 = "Frame"
+	SystemEventObjectAdded           = "ObjectAdded"
+	SystemEventObjectRemoved         = "ObjectRemoved"
+	SystemEventPause                 = "Pause"
+	SystemEventPaused                = "Paused"
+	SystemEventPauseFrame            = "PauseFrame"
+	SystemEventPositionChanged       = "PositionChanged"
+	SystemEventSim                   = "Sim"
+	SystemEventSimStart              = "SimStart"
+	SystemEventSimStop               = "SimStop"
+	SystemEventSound                 = "Sound"
+	SystemEventUnpaused              = "Unpaused"
+	SystemEventView                  = "View"
+)
+
+// SystemEventFunc is called for a system event without data, e.g. "1sec" or "Crashed".
+type SystemEventFunc func()
+
+// SystemStateFunc is called for a system event reporting an on/off state, e.g. "Pause" or "Sim".
+type SystemStateFunc func(on bool)
+
+// SystemViewFunc is called for the "View" event with the ViewSystemEventData* flags of the new view.
+type SystemViewFunc func(view DWord)
+
+// SystemFrameFunc is called for the "Frame" and "PauseFrame" events.
+type SystemFrameFunc func(event *RecvEventFrame)
+
+// SystemFilenameFunc is called for system events reporting a file, e.g. "FlightLoaded" or "AircraftLoaded".
+// See CString for the file name.
+type SystemFilenameFunc func(event *RecvEventFilename)
+
+// SystemObjectFunc is called for the "ObjectAdded" and "ObjectRemoved" events.
+type SystemObjectFunc func(event *RecvEventObjectAddRemove)
+
+// SystemEvents subscribes to system events with typed callbacks. Every subscription has an event ID of its own
+// and returns a function which unsubscribes it. Messages are routed to the callbacks by Dispatch,
+// SimMate does so for its SystemEvents.
+type SystemEvents struct {
+	simco    *SimConnect
+	mutex    sync.Mutex
+	handlers map[DWord]func(value interface{})
+}
+
+// NewSystemEvents creates a SystemEvents for the connection.
+func NewSystemEvents(simco *SimConnect) *SystemEvents {
+	return &SystemEvents{
+		simco:    simco,
+		handlers: make(map[DWord]func(value interface{})),
+	}
+}
+
+// Subscribe subscribes to a system event by name. fn is called with the decoded message,
+// i.e. a *RecvEvent, *RecvEventFrame, *RecvEventFilename or *RecvEventObjectAddRemove.
+func (s *SystemEvents) Subscribe(name string, fn func(value interface{})) (func() error, error) {
+	eventID := NewEventID()
+	s.mutex.Lock()
+	s.handlers[eventID] = fn
+	s.mutex.Unlock()
+	if err := s.simco.SubscribeToSystemEvent(eventID, name); err != nil {
+		s.remove(eventID)
+		return nil, err
+	}
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			s.remove(eventID)
+			err = s.simco.UnsubscribeFromSystemEvent(eventID)
+		})
+		return err
+	}, nil
+}
+
+func (s *SystemEvents) remove(eventID DWord) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.handlers, eventID)
+}
+
+// Dispatch calls the callback of the subscription the message belongs to. It reports whether there was one.
+func (s *SystemEvents) Dispatch(value interface{}) bool {
+	recv := recvEventOf(value)
+	if recv == nil {
+		return false
+	}
+	s.mutex.Lock()
+	handler := s.handlers[recv.EventID]
+	s.mutex.Unlock()
+	if handler == nil {
+		return false
+	}
+	handler(value)
+	return true
+}
+
+// recvEventOf returns the RecvEvent of an event message, nil for other messages.
+func recvEventOf(value interface{}) *RecvEvent {
+	switch recv := value.(type) {
+	case *RecvEvent:
+		return recv
+	case *RecvEventFrame:
+		return &recv.RecvEvent
+	case *RecvEventFilename:
+		return &recv.RecvEvent
+	case *RecvEventObjectAddRemove:
+		return &recv.RecvEvent
+	}
+	return nil
+}
+
+func (s *SystemEvents) subscribeEvent(name string, fn SystemEventFunc) (func() error, error) {
+	return s.Subscribe(name, func(value interface{}) {
+		fn()
+	})
+}
+
+func (s *SystemEvents) subscribeState(name string, fn SystemStateFunc) (func() error, error) {
+	return s.Subscribe(name, func(value interface{}) {
+		fn(recvEventOf(value).Data != 0)
+	})
+}
+
+func (s *SystemEvents) subscribeFrame(name string, fn SystemFrameFunc) (func() error, error) {
+	return s.Subscribe(name, func(value interface{}) {
+		if recv, ok := value.(*RecvEventFrame); ok {
+			fn(recv)
+		}
+	})
+}
+
+func (s *SystemEvents) subscribeFilename(name string, fn SystemFilenameFunc) (func() error, error) {
+	return s.Subscribe(name, func(value interface{}) {
+		if recv, ok := value.(*RecvEventFilename); ok {
+			fn(recv)
+		}
+	})
+}
+
+func (s *SystemEvents) subscribeObject(name string, fn SystemObjectFunc) (func() error, error) {
+	return s.Subscribe(name, func(value interface{}) {
+		if recv, ok := value.(*RecvEventObjectAddRemove); ok {
+			fn(recv)
+		}
+	})
+}
+
+// OnSecond calls fn every second of simulation time.
+func (s *SystemEvents) OnSecond(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEvent1Sec, fn)
+}
+
+// OnFourSeconds calls fn every four seconds of simulation time.
+func (s *SystemEvents) OnFourSeconds(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEvent4Sec, fn)
+}
+
+// OnSixHz calls fn six times per second of simulation time.
+func (s *SystemEvents) OnSixHz(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEvent6Hz, fn)
+}
+
+// OnFrame calls fn every visual frame with the frame rate and simulation speed.
+func (s *SystemEvents) OnFrame(fn SystemFrameFunc) (func() error, error) {
+	return s.subscribeFrame(SystemEventFrame, fn)
+}
+
+// OnPauseFrame calls fn every visual frame while the simulation is paused.
+func (s *SystemEvents) OnPauseFrame(fn SystemFrameFunc) (func() error, error) {
+	return s.subscribeFrame(SystemEventPauseFrame, fn)
+}
+
+// OnPause calls fn when the simulation is paused (true) or unpaused (false).
+func (s *SystemEvents) OnPause(fn SystemStateFunc) (func() error, error) {
+	return s.subscribeState(SystemEventPause, fn)
+}
+
+// OnPaused calls fn when the simulation is paused.
+func (s *SystemEvents) OnPaused(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventPaused, fn)
+}
+
+// OnUnpaused calls fn when the simulation is unpaused.
+func (s *SystemEvents) OnUnpaused(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventUnpaused, fn)
+}
+
+// OnSim calls fn when the simulation starts running (true) or stops (false), e.g. when the user enters a menu.
+func (s *SystemEvents) OnSim(fn SystemStateFunc) (func() error, error) {
+	return s.subscribeState(SystemEventSim, fn)
+}
+
+// OnSimStart calls fn when the simulation starts running.
+func (s *SystemEvents) OnSimStart(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventSimStart, fn)
+}
+
+// OnSimStop calls fn when the simulation stops running.
+func (s *SystemEvents) OnSimStop(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventSimStop, fn)
+}
+
+// OnSound calls fn when the master sound switch is turned on (true) or off (false).
+func (s *SystemEvents) OnSound(fn SystemStateFunc) (func() error, error) {
+	return s.subscribeState(SystemEventSound, fn)
+}
+
+// OnCrashed calls fn when the user aircraft crashes.
+func (s *SystemEvents) OnCrashed(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventCrashed, fn)
+}
+
+// OnCrashReset calls fn when the crash cut-scene has completed.
+func (s *SystemEvents) OnCrashReset(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventCrashReset, fn)
+}
+
+// OnFlightLoaded calls fn with the file name of a flight which has been loaded.
+func (s *SystemEvents) OnFlightLoaded(fn SystemFilenameFunc) (func() error, error) {
+	return s.subscribeFilename(SystemEventFlightLoaded, fn)
+}
+
+// OnFlightSaved calls fn with the file name of a flight which has been saved.
+func (s *SystemEvents) OnFlightSaved(fn SystemFilenameFunc) (func() error, error) {
+	return s.subscribeFilename(SystemEventFlightSaved, fn)
+}
+
+// OnFlightPlanActivated calls fn with the file name of a flight plan which has been activated.
+func (s *SystemEvents) OnFlightPlanActivated(fn SystemFilenameFunc) (func() error, error) {
+	return s.subscribeFilename(SystemEventFlightPlanActivated, fn)
+}
+
+// OnFlightPlanDeactivated calls fn when the active flight plan has been deactivated.
+func (s *SystemEvents) OnFlightPlanDeactivated(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventFlightPlanDeactivated, fn)
+}
+
+// OnAircraftLoaded calls fn with the file name of an aircraft which has been loaded.
+func (s *SystemEvents) OnAircraftLoaded(fn SystemFilenameFunc) (func() error, error) {
+	return s.subscribeFilename(SystemEventAircraftLoaded, fn)
+}
+
+// OnObjectAdded calls fn when an AI object has been added to the simulation.
+func (s *SystemEvents) OnObjectAdded(fn SystemObjectFunc) (func() error, error) {
+	return s.subscribeObject(SystemEventObjectAdded, fn)
+}
+
+// OnObjectRemoved calls fn when an AI object has been removed from the simulation.
+func (s *SystemEvents) OnObjectRemoved(fn SystemObjectFunc) (func() error, error) {
+	return s.subscribeObject(SystemEventObjectRemoved, fn)
+}
+
+// OnPositionChanged calls fn when the user changed the position of the aircraft through a dialog.
+func (s *SystemEvents) OnPositionChanged(fn SystemEventFunc) (func() error, error) {
+	return s.subscribeEvent(SystemEventPositionChanged, fn)
+}
+
+// OnView calls fn when the user changed the view, with the ViewSystemEventData* flags of the new view.
+func (s *SystemEvents) OnView(fn SystemViewFunc) (func() error, error) {
+	return s.Subscribe(SystemEventView, func(value interface{}) {
+		fn(recvEventOf(value).Data)
+	})
+}
@@ -0,0 +1,74 @@
+package simconnect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSystemEvents(t *testing.T) {
+	m := newTestMate(t, nil)
+	var paused []bool
+	unsubscribePause, err := m.SystemEvents().OnPause(func(on bool) { paused = append(paused, on) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flight string
+	if _, err := m.SystemEvents().OnFlightLoaded(func(event *RecvEventFilename) {
+		flight = CString(event.FileName[:])
+	}); err != nil {
+		t.Fatal(err)
+	}
+	subscriptions := m.backend.CallsNamed(scSubscribeToSystemEvent)
+	if len(subscriptions) != 2 || subscriptions[0].Args[1] != SystemEventPause || subscriptions[1].Args[1] != SystemEventFlightLoaded {
+		t.Fatalf("SubscribeToSystemEvent calls = %v", subscriptions)
+	}
+	pauseID, flightID := subscriptions[0].Args[0].(DWord), subscriptions[1].Args[0].(DWord)
+	if pauseID == flightID {
+		t.Fatalf("both subscriptions have event ID %d", pauseID)
+	}
+
+	pause := func(on DWord) RecvEvent {
+		return RecvEvent{Recv: Recv{ID: RecvIDEvent}, EventID: pauseID, Data: on}
+	}
+	loaded := RecvEventFilename{RecvEvent: RecvEvent{Recv: Recv{ID: RecvIDEventFilename}, EventID: flightID}}
+	copy(loaded.FileName[:], "flights\\other\\MainMenu.FLT")
+	for _, recv := range []interface{}{pause(1), loaded, pause(0)} {
+		if err := m.backend.PushRecv(recv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.d.drain(); err != nil {
+		t.Fatal(err)
+	}
+	if len(paused) != 2 || !paused[0] || paused[1] {
+		t.Errorf("OnPause called with %v, want [true false]", paused)
+	}
+	if flight != "flights\\other\\MainMenu.FLT" {
+		t.Errorf("OnFlightLoaded called with %q", flight)
+	}
+
+	if err := unsubscribePause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := unsubscribePause(); err != nil {
+		t.Fatal(err)
+	}
+	if unsubscriptions := m.backend.CallsNamed(scUnsubscribeFromSystemEvent); len(unsubscriptions) != 1 || unsubscriptions[0].Args[0] != pauseID {
+		t.Errorf("UnsubscribeFromSystemEvent calls = %v", unsubscriptions)
+	}
+	if m.SystemEvents().Dispatch(&RecvEvent{EventID: pauseID, Data: 1}) {
+		t.Error("dispatched an event to a subscription which was removed")
+	}
+}
+
+func TestSystemEventsSubscribeFailure(t *testing.T) {
+	backend := NewScriptedBackend()
+	events := NewSystemEvents(&NewSimMateWithBackend(backend).SimConnect)
+	backend.SetError(scSubscribeToSystemEvent, errors.New("no connection"))
+	if _, err := events.OnCrashed(func() { t.Error("OnCrashed called") }); err == nil {
+		t.Fatal("subscribing didn't fail")
+	}
+	if len(events.handlers) != 0 {
+		t.Errorf("%d handlers left after the subscription failed", len(events.handlers))
+	}
+}
@@ -0,0 +1,268 @@
+package units
+
+import "math"
+
+type unitDef struct {
+	name    string
+	dim     Dimension
+	factor  float64
+	offset  float64
+	aliases []string
+}
+
+func (def unitDef) unit() Unit {
+	return Unit{Name: def.name, Dimension: def.dim, factor: def.factor, offset: def.offset}
+}
+
+const (
+	meterPerFoot        = 0.3048
+	meterPerNauticalMi  = 1852.0
+	meterPerStatuteMi   = 1609.344
+	cubicMeterPerGallon = 0.003785411784
+	kilogramPerPound    = 0.45359237
+	kilogramPerSlug     = 14.5939029372
+	pascalPerInHg       = 3386.389
+	pascalPerPSI        = 6894.757293168
+	pascalPerAtmosphere = 101325.0
+	standardGravity     = 9.80665
+	newtonMeterPerFtLb  = 1.3558179483314004
+	zeroCelsius         = 273.15
+)
+
+// table lists the units of references/unique_units.txt plus a few only found in references/units.txt, base units first.
+// The aliases cover references/units.txt.
+var table = []unitDef{
+	// Length, base meter
+	{"meter", Length, 1, 0, []string{"meters", "m"}},
+	{"centimeter", Length, 0.01, 0, []string{"centimeters", "cm"}},
+	{"kilometer", Length, 1000, 0, []string{"kilometers", "km"}},
+	{"millimeter", Length, 0.001, 0, []string{"millimeters"}},
+	{"mile", Length, meterPerStatuteMi, 0, []string{"miles"}},
+	{"nmile", Length, meterPerNauticalMi, 0, []string{"nmiles", "nautical mile", "nautical miles"}},
+	{"decinmile", Length, meterPerNauticalMi / 10, 0, []string{"decinmiles", "decimile", "decimiles"}},
+	{"foot", Length, meterPerFoot, 0, []string{"feet", "ft"}},
+	{"inch", Length, 0.0254, 0, []string{"inches", "in"}},
+	{"yard", Length, 0.9144, 0, []string{"yards"}},
+	{"meter scaler 256", Length, 1.0 / 256, 0, []string{"meters scaler 256"}},
+	{"meter latitude", Length, 1, 0, []string{"meters latitude"}},
+
+	// Area, base square meter
+	{"square meter", Area, 1, 0, []string{"square meters", "sq m", "m2"}},
+	{"square centimeter", Area, 1e-4, 0, []string{"square centimeters", "sq cm", "cm2"}},
+	{"square kilometer", Area, 1e6, 0, []string{"square kilometers", "sq km", "km2"}},
+	{"square millimeter", Area, 1e-6, 0, []string{"square millimeters", "sq mm", "mm2"}},
+	{"square mile", Area, meterPerStatuteMi * meterPerStatuteMi, 0, []string{"square miles"}},
+	{"square feet", Area, meterPerFoot * meterPerFoot, 0, []string{"square foot", "sq ft", "ft2"}},
+	{"square inch", Area, 0.0254 * 0.0254, 0, []string{"square inches", "sq in", "in2"}},
+	{"square yard", Area, 0.9144 * 0.9144, 0, []string{"square yards", "sq yd", "yd2"}},
+
+	// Volume, base cubic meter
+	{"meter cubed", Volume, 1, 0, []string{"meters cubed", "cubic meter", "cubic meters", "cu m", "m3"}},
+	{"liter", Volume, 0.001, 0, []string{"liters"}},
+	{"gallon", Volume, cubicMeterPerGallon, 0, []string{"gallons"}},
+	{"quart", Volume, cubicMeterPerGallon / 4, 0, []string{"quarts"}},
+	{"cubic centimeter", Volume, 1e-6, 0, []string{"cubic centimeters", "cu cm", "cm3"}},
+	{"cubic kilometer", Volume, 1e9, 0, []string{"cubic kilometers", "cu km", "km3"}},
+	{"cubic millimeter", Volume, 1e-9, 0, []string{"cubic millimeters", "cu mm", "mm3"}},
+	{"cubic mile", Volume, meterPerStatuteMi * meterPerStatuteMi * meterPerStatuteMi, 0, []string{"cubic miles"}},
+	{"cubic feet", Volume, meterPerFoot * meterPerFoot * meterPerFoot, 0, []string{"cubic foot", "cu ft", "ft3"}},
+	{"cubic inch", Volume, 0.0254 * 0.0254 * 0.0254, 0, []string{"cubic inches", "cu in", "in3"}},
+	{"cubic yard", Volume, 0.9144 * 0.9144 * 0.9144, 0, []string{"cubic yards", "cu yd", "yd3"}},
+
+	// Temperature, base kelvin
+	{"kelvin", Temperature, 1, 0, nil},
+	{"rankine", Temperature, 5.0 / 9, 0, nil},
+	{"farenheit", Temperature, 5.0 / 9, zeroCelsius - 32*5.0/9, []string{"fahrenheit"}},
+	{"celsius", Temperature, 1, zeroCelsius, nil},
+	{"celsius scaler 16k", Temperature, 1.0 / 16384, zeroCelsius, nil},
+	{"celsius scaler 256", Temperature, 1.0 / 256, zeroCelsius, nil},
+	{"celsius scaler 1/256", Temperature, 256, zeroCelsius, nil},
+
+	// Dimensionless, base ratio
+	{"number", Dimensionless, 1, 0, []string{"numbers", "bool", "boolean"}},
+	{"part", Dimensionless, 1, 0, nil},
+	{"half", Dimensionless, 0.5, 0, []string{"halfs"}},
+	{"third", Dimensionless, 1.0 / 3, 0, []string{"thirds"}},
+	{"percent", Dimensionless, 0.01, 0, nil},
+	{"percent over 100", Dimensionless, 1, 0, nil},
+	{"times", Dimensionless, 1, 0, nil},
+	{"ratio", Dimensionless, 1, 0, nil},
+	{"scaler", Dimensionless, 1, 0, nil},
+	{"percentage", Dimensionless, 0.01, 0, nil},
+	{"percent scaler 16k", Dimensionless, 0.01 / 16384, 0, nil},
+	{"percent scaler 32k", Dimensionless, 0.01 / 32768, 0, nil},
+	{"percent scaler 2pow23", Dimensionless, 0.01 / 8388608, 0, nil},
+	{"position", Dimensionless, 1, 0, nil},
+	{"position 16k", Dimensionless, 1.0 / 16384, 0, nil},
+	{"position 32k", Dimensionless, 1.0 / 32768, 0, nil},
+	{"position 128", Dimensionless, 1.0 / 128, 0, nil},
+
+	// Angle, base radian
+	{"radian", Angle, 1, 0, []string{"radians"}},
+	{"degree", Angle, math.Pi / 180, 0, []string{"degrees"}},
+	{"grad", Angle, math.Pi / 200, 0, []string{"grads"}},
+	{"round", Angle, 2 * math.Pi, 0, []string{"rounds"}},
+	{"angl16", Angle, 2 * math.Pi / 65536, 0, []string{"degrees angl16", "degree angl16"}},
+	{"angl32", Angle, 2 * math.Pi / 4294967296, 0, []string{"degrees angl32", "degree angl32"}},
+	{"degree latitude", Angle, math.Pi / 180, 0, []string{"degrees latitude"}},
+	{"degree longitude", Angle, math.Pi / 180, 0, []string{"degrees longitude"}},
+
+	// Inverse angle, base per radian
+	{"per radian", InverseAngle, 1, 0, nil},
+	{"per degree", InverseAngle, 180 / math.Pi, 0, nil},
+
+	// Angular velocity, base radian per second
+	{"radian per second", AngularVelocity, 1, 0, []string{"radians per second"}},
+	{"revolution per minute", AngularVelocity, 2 * math.Pi / 60, 0, []string{"revolutions per minute", "rpm", "rpms"}},
+	{"degree per second", AngularVelocity, math.Pi / 180, 0, []string{"degrees per second"}},
+	{"rpm 1 over 16k", AngularVelocity, 2 * math.Pi / 60 / 16384, 0, nil},
+
+	// Speed, base meter per second
+	{"meter/second", Speed, 1, 0, []string{"meter per second", "meters per second", "meters/second", "m/s"}},
+	{"meter per minute", Speed, 1.0 / 60, 0, []string{"meters per minute"}},
+	{"feet/second", Speed, meterPerFoot, 0, []string{"feet per second"}},
+	{"feet/minute", Speed, meterPerFoot / 60, 0, []string{"feet per minute", "ft/min"}},
+	{"kilometer/hour", Speed, 1 / 3.6, 0, []string{"kilometer per hour", "kilometers per hour", "kilometers/hour", "kph"}},
+	{"knot", Speed, meterPerNauticalMi / 3600, 0, []string{"knots"}},
+	{"mile per hour", Speed, meterPerStatuteMi / 3600, 0, []string{"miles per hour", "mph"}},
+	{"knot scaler 128", Speed, meterPerNauticalMi / 3600 / 128, 0, []string{"knots scaler 128"}},
+	{"meter per second scaler 256", Speed, 1.0 / 256, 0, []string{"meters per second scaler 256"}},
+
+	// Mach, kept apart from speed because the speed of sound depends on the temperature
+	{"mach", Mach, 1, 0, []string{"machs"}},
+	{"mach 3d2 over 64k", Mach, 3.2 / 65536, 0, nil},
+
+	// Frequency, base hertz
+	{"Hertz", Frequency, 1, 0, []string{"hz"}},
+	{"Kilohertz", Frequency, 1e3, 0, []string{"khz"}},
+	{"Megahertz", Frequency, 1e6, 0, []string{"mhz"}},
+	{"per second", Frequency, 1, 0, nil},
+	{"per minute", Frequency, 1.0 / 60, 0, nil},
+	{"per hour", Frequency, 1.0 / 3600, 0, nil},
+
+	// Pressure, base pascal
+	{"pascal", Pressure, 1, 0, []string{"pascals", "pa", "newton per square meter", "newtons per square meter"}},
+	{"kilopascal", Pressure, 1e3, 0, []string{"kpa"}},
+	{"kilogram force per square centimeter", Pressure, standardGravity * 1e4, 0, []string{"kgfsqcm"}},
+	{"millimeter of mercury", Pressure, 133.322387415, 0, []string{"millimeters of mercury", "mmhg"}},
+	{"centimeter of mercury", Pressure, 1333.22387415, 0, []string{"centimeters of mercury", "cmhg"}},
+	{"inch of mercury", Pressure, pascalPerInHg, 0, []string{"inches of mercury", "inhg"}},
+	{"atmosphere", Pressure, pascalPerAtmosphere, 0, []string{"atmospheres", "atm"}},
+	{"millimeter of water", Pressure, standardGravity, 0, []string{"millimeters of water"}},
+	{"pound-force per square inch", Pressure, pascalPerPSI, 0, []string{"psi"}},
+	{"pound-force per square foot", Pressure, pascalPerPSI / 144, 0, []string{"psf"}},
+	{"bar", Pressure, 1e5, 0, []string{"bars"}},
+	{"boost cmHg", Pressure, 1333.22387415, pascalPerAtmosphere, nil},
+	{"boost inHg", Pressure, pascalPerInHg, pascalPerAtmosphere, nil},
+	{"boost psi", Pressure, pascalPerPSI, pascalPerAtmosphere, nil},
+	{"psf scaler 16k", Pressure, pascalPerPSI / 144 / 16384, 0, nil},
+	{"psi scaler 16k", Pressure, pascalPerPSI / 16384, 0, nil},
+	{"psi 4 over 16k", Pressure, pascalPerPSI * 4 / 16384, 0, nil},
+	{"millibar", Pressure, 100, 0, []string{"millibars", "mbar", "mbars", "hectopascal", "hectopascals"}},
+	{"millibar scaler 16", Pressure, 100.0 / 16, 0, []string{"millibars scaler 16"}},
+
+	// Time, base second
+	{"second", Time, 1, 0, []string{"seconds"}},
+	{"minute", Time, 60, 0, []string{"minutes"}},
+	{"hour", Time, 3600, 0, []string{"hours"}},
+	{"day", Time, 86400, 0, []string{"days"}},
+	{"hour over 10", Time, 360, 0, []string{"hours over 10"}},
+	{"year", Time, 365 * 86400, 0, []string{"years"}},
+
+	// Power, base watt
+	{"Watt", Power, 1, 0, []string{"watts"}},
+	{"ft lb per second", Power, newtonMeterPerFtLb, 0, nil},
+
+	// Volume flow, base cubic meter per second
+	{"meter cubed per second", VolumeFlow, 1, 0, []string{"meters cubed per second"}},
+	{"gallon per hour", VolumeFlow, cubicMeterPerGallon / 3600, 0, []string{"gallons per hour", "gph"}},
+	{"liter per hour", VolumeFlow, 0.001 / 3600, 0, []string{"liters per hour"}},
+
+	// Mass flow, base kilogram per second
+	{"kilogram per second", MassFlow, 1, 0, []string{"kilograms per second"}},
+	{"pound per hour", MassFlow, kilogramPerPound / 3600, 0, []string{"pounds per hour", "pph"}},
+
+	// Mass, base kilogram
+	{"kilogram", Mass, 1, 0, []string{"kilograms", "kg"}},
+	{"pound", Mass, kilogramPerPound, 0, []string{"pounds", "lbs"}},
+	{"pound scaler 256", Mass, kilogramPerPound / 256, 0, []string{"pounds scaler 256"}},
+	{"slug", Mass, kilogramPerSlug, 0, []string{"slugs", "geepound", "geepounds"}},
+
+	// Moment of inertia, base kilogram meter squared
+	{"kilogram meter squared", MomentOfInertia, 1, 0, []string{"kilograms meter squared"}},
+	{"slug feet squared", MomentOfInertia, kilogramPerSlug * meterPerFoot * meterPerFoot, 0, []string{"slugs feet squared"}},
+
+	// Electricity
+	{"ampere", Current, 1, 0, []string{"amperes", "amp", "amps"}},
+	{"volt", Voltage, 1, 0, []string{"volts"}},
+
+	// Acceleration, base meter per second squared
+	{"meter per second squared", Acceleration, 1, 0, []string{"meters per second squared"}},
+	{"GForce", Acceleration, standardGravity, 0, []string{"g force"}},
+	{"G Force 624 scaled", Acceleration, standardGravity / 624, 0, nil},
+	{"feet per second squared", Acceleration, meterPerFoot, 0, []string{"foot per second squared"}},
+
+	// Density, base kilogram per cubic meter
+	{"kilogram per cubic meter", Density, 1, 0, []string{"kilograms per cubic meter"}},
+	{"Slug per cubic feet", Density, kilogramPerSlug / (meterPerFoot * meterPerFoot * meterPerFoot), 0,
+		[]string{"slug per cubic foot", "slugs per cubic feet", "slugs per cubic foot", "slug/ft3"}},
+
+	// Torque, base newton meter
+	{"newton meter", Torque, 1, 0, []string{"newton meters", "nm"}},
+	{"foot pound", Torque, newtonMeterPerFtLb, 0, []string{"foot pounds", "foot-pound", "foot-pounds", "ft-lbs"}},
+	{"lbf-feet", Torque, newtonMeterPerFtLb, 0, nil},
+	{"kilogram meter", Torque, standardGravity, 0, []string{"kilogram meters", "kgf meter", "kgf meters"}},
+	{"poundal feet", Torque, 0.138254954376 * meterPerFoot, 0, nil},
+
+	// Encoded values
+	{"Enum", Encoded, 1, 0, nil},
+	{"Bco16", Encoded, 1, 0, nil},
+	{"mask", Encoded, 1, 0, nil},
+	{"flags", Encoded, 1, 0, nil},
+	{"Frequency BCD32", Encoded, 1, 0, nil},
+	{"Frequency BCD16", Encoded, 1, 0, nil},
+	{"Frequency ADF BCD32", Encoded, 1, 0, nil},
+	{"keyframe", Encoded, 1, 0, []string{"keyframes"}},
+	{"bel", Encoded, 1, 0, []string{"bels"}},
+	{"decibel", Encoded, 1, 0, []string{"decibels"}},
+	{"more_than_a_half", Encoded, 1, 0, nil},
+	{"minute per round", Encoded, 1, 0, []string{"minutes per round"}},
+	{"nice minute per round", Encoded, 1, 0, []string{"nice minutes per round"}},
+	{"fs7 oil quantity", Encoded, 1, 0, nil},
+	{"fs7 charging amps", Encoded, 1, 0, nil},
+	{"celsius fs7 egt", Encoded, 1, 0, nil},
+	{"celsius fs7 oil temp", Encoded, 1, 0, nil},
+	{"psi fs7 oil pressure", Encoded, 1, 0, nil},
+	{"GLOBALP->eng1.oil_tmp", Encoded, 1, 0, nil},
+	{"GLOBALP->eng1.manifold_pressure", Encoded, 1, 0, nil},
+	{"GLOBALP->eng1.oil_prs", Encoded, 1, 0, nil},
+	{"GLOBALP->delta_heading_rate", Encoded, 1, 0, nil},
+	{"GLOBALP->vertical_speed", Encoded, 1, 0, nil},
+	{"degree per second ang16", Encoded, 1, 0, []string{"degrees per second ang16"}},
+	{"inHg 64 over 64k", Encoded, 1, 0, nil},
+}
+
+// abbreviations are accepted by Parse for convenience, but SimConnect doesn't know them.
+var abbreviations = map[string]string{
+	"mm":              "millimeter",
+	"mi":              "mile",
+	"yd":              "yard",
+	"gal":             "gallon",
+	"%":               "percent",
+	"rad":             "radian",
+	"deg":             "degree",
+	"foot per second": "feet/second",
+	"ft/s":            "feet/second",
+	"fps":             "feet/second",
+	"foot per minute": "feet/minute",
+	"fpm":             "feet/minute",
+	"km/h":            "kilometer/hour",
+	"kts":             "knot",
+	"kt":              "knot",
+	"mb":              "millibar",
+	"hpa":             "millibar",
+	"sec":             "second",
+	"min":             "minute",
+	"lb":              "pound",
+	"ft lb":           "foot pound",
+}
@@ -0,0 +1,217 @@
+// Package units parses the unit names SimConnect accepts and converts values between compatible units.
+//
+// Every unit belongs to a Dimension and is defined by a factor and an offset relative to the base unit
+// of its dimension (e.g. meters for Length), so any two units of the same dimension convert into each other.
+// Encoded units like "Enum", "mask" or "Frequency BCD16" have the dimension Encoded and only convert to themselves.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnknownUnit is returned by Parse for a name which is neither a unit nor an alias.
+	ErrUnknownUnit = errors.New("unknown unit")
+	// ErrIncompatible is returned when converting between units of different dimensions.
+	ErrIncompatible = errors.New("incompatible units")
+)
+
+// Dimension is the physical quantity a unit measures.
+type Dimension int
+
+const (
+	Encoded Dimension = iota // a raw or encoded value, e.g. Enum or BCD, which can't be converted
+	Dimensionless
+	Length
+	Area
+	Volume
+	Temperature
+	Angle
+	InverseAngle
+	AngularVelocity
+	Speed
+	Mach
+	Acceleration
+	Frequency
+	Pressure
+	Time
+	Power
+	VolumeFlow
+	MassFlow
+	Mass
+	MomentOfInertia
+	Current
+	Voltage
+	Density
+	Torque
+)
+
+var dimensionNames = map[Dimension]string{
+	Encoded:         "encoded",
+	Dimensionless:   "dimensionless",
+	Length:          "length",
+	Area:            "area",
+	Volume:          "volume",
+	Temperature:     "temperature",
+	Angle:           "angle",
+	InverseAngle:    "inverse angle",
+	AngularVelocity: "angular velocity",
+	Speed:           "speed",
+	Mach:            "mach",
+	Acceleration:    "acceleration",
+	Frequency:       "frequency",
+	Pressure:        "pressure",
+	Time:            "time",
+	Power:           "power",
+	VolumeFlow:      "volume flow",
+	MassFlow:        "mass flow",
+	Mass:            "mass",
+	MomentOfInertia: "moment of inertia",
+	Current:         "current",
+	Voltage:         "voltage",
+	Density:         "density",
+	Torque:          "torque",
+}
+
+func (dim Dimension) String() string {
+	if name, exists := dimensionNames[dim]; exists {
+		return name
+	}
+	return fmt.Sprintf("Dimension(%d)", int(dim))
+}
+
+// Unit is a unit SimConnect accepts. Name is the canonical name as listed in references/unique_units.txt.
+type Unit struct {
+	Name      string
+	Dimension Dimension
+	factor    float64 // base = value*factor + offset
+	offset    float64
+}
+
+func (unit Unit) String() string {
+	return unit.Name
+}
+
+// Compatible reports whether values convert between the two units.
+func (unit Unit) Compatible(other Unit) bool {
+	if unit.Dimension == Encoded || other.Dimension == Encoded {
+		return unit.Name == other.Name
+	}
+	return unit.Dimension == other.Dimension
+}
+
+// ToBase converts a value to the base unit of the dimension, e.g. feet to meters.
+func (unit Unit) ToBase(value float64) float64 {
+	return value*unit.factor + unit.offset
+}
+
+// FromBase converts a value in the base unit of the dimension to this unit.
+func (unit Unit) FromBase(value float64) float64 {
+	return (value - unit.offset) / unit.factor
+}
+
+// Parse returns the unit with the given name, alias or abbreviation, e.g. "knot", "knots" or "kts". Names are case insensitive.
+func Parse(name string) (Unit, error) {
+	key := normalize(name)
+	if unit, exists := unitsByName[key]; exists {
+		return unit, nil
+	}
+	if canonical, exists := abbreviations[key]; exists {
+		return unitsByName[normalize(canonical)], nil
+	}
+	// plurals which aren't listed explicitly, e.g. "square yard" for "square yards"
+	if trimmed := strings.TrimSuffix(key, "s"); trimmed != key {
+		if unit, exists := unitsByName[trimmed]; exists {
+			return unit, nil
+		}
+	}
+	return Unit{}, fmt.Errorf("%w %q", ErrUnknownUnit, name)
+}
+
+// IsSimConnectName reports whether SimConnect accepts the name, i.e. it is a canonical name or an alias
+// listed in references/units.txt. Parse also accepts abbreviations like "kts" which SimConnect doesn't know.
+func IsSimConnectName(name string) bool {
+	_, exists := unitsByName[normalize(name)]
+	return exists
+}
+
+// MustParse is like Parse but panics if the name is unknown. It is meant for unit names known at compile time.
+func MustParse(name string) Unit {
+	unit, err := Parse(name)
+	if err != nil {
+		panic(err)
+	}
+	return unit
+}
+
+// IsKnown reports whether Parse accepts the name.
+func IsKnown(name string) bool {
+	_, err := Parse(name)
+	return err == nil
+}
+
+// Convert converts a value from one unit to another.
+func Convert(value float64, from, to Unit) (float64, error) {
+	if !from.Compatible(to) {
+		return 0, fmt.Errorf("%s (%s) to %s (%s): %w", from.Name, from.Dimension, to.Name, to.Dimension, ErrIncompatible)
+	}
+	if from.Name == to.Name {
+		return value, nil
+	}
+	return to.FromBase(from.ToBase(value)), nil
+}
+
+// ConvertByName parses both unit names and converts the value, e.g. ConvertByName(250, "knots", "m/s").
+func ConvertByName(value float64, from, to string) (float64, error) {
+	fromUnit, err := Parse(from)
+	if err != nil {
+		return 0, err
+	}
+	toUnit, err := Parse(to)
+	if err != nil {
+		return 0, err
+	}
+	return Convert(value, fromUnit, toUnit)
+}
+
+// Units returns all canonical units sorted by name.
+func Units() []Unit {
+	all := make([]Unit, len(table))
+	for i, def := range table {
+		all[i] = def.unit()
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// Names returns all canonical names and aliases SimConnect accepts, in lower case.
+func Names() []string {
+	names := make([]string, 0, len(unitsByName))
+	for name := range unitsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var unitsByName = indexUnits()
+
+func indexUnits() map[string]Unit {
+	byName := make(map[string]Unit)
+	for _, def := range table {
+		unit := def.unit()
+		byName[normalize(def.name)] = unit
+		for _, alias := range def.aliases {
+			byName[normalize(alias)] = unit
+		}
+	}
+	return byName
+}
+
+// normalize lower-cases a name and collapses white space.
+func normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
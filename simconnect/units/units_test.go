@@ -0,0 +1,133 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		canonical string
+		simco     bool // SimConnect accepts the name as well
+	}{
+		{"knot", "knot", true},
+		{"Knots", "knot", true},
+		{"  feet   per  second ", "feet/second", true},
+		{"ft", "foot", true},
+		{"fahrenheit", "farenheit", true},
+		{"hectopascals", "millibar", true},
+		{"kts", "knot", false},
+		{"hPa", "millibar", false},
+		{"%", "percent", false},
+		{"ft/s", "feet/second", false},
+		{"kelvins", "kelvin", false},
+		{"boost psi", "boost psi", true},
+	}
+	for _, test := range tests {
+		unit, err := Parse(test.name)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", test.name, err)
+			continue
+		}
+		if unit.Name != test.canonical {
+			t.Errorf("Parse(%q) = %s, want %s", test.name, unit.Name, test.canonical)
+		}
+		if IsSimConnectName(test.name) != test.simco {
+			t.Errorf("IsSimConnectName(%q) = %t, want %t", test.name, !test.simco, test.simco)
+		}
+	}
+
+	for _, name := range []string{"", "furlong", "knotz"} {
+		if _, err := Parse(name); !errors.Is(err, ErrUnknownUnit) {
+			t.Errorf("Parse(%q) = %v, want ErrUnknownUnit", name, err)
+		}
+	}
+}
+
+func TestConvertByName(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{1, "nautical mile", "meters", 1852},
+		{1000, "feet", "meters", 304.8},
+		{100, "knots", "kph", 185.2},
+		{1, "radian", "degrees", 180 / math.Pi},
+		{0.5, "percent over 100", "percent", 50},
+		{29.92, "inHg", "millibars", 1013.2},
+		{0, "celsius", "fahrenheit", 32},
+		{100, "celsius", "kelvin", 373.15},
+		{-40, "fahrenheit", "celsius", -40},
+		{491.67, "rankine", "celsius", 0},
+		{0, "boost psi", "psi", 14.696},
+		{0, "boost inHg", "inHg", 29.921},
+		{30, "inHg", "boost inHg", 0.079},
+		{3, "Enum", "enum", 3},
+		{123, "Frequency BCD16", "frequency bcd16", 123},
+	}
+	for _, test := range tests {
+		got, err := ConvertByName(test.value, test.from, test.to)
+		if err != nil {
+			t.Errorf("%v %s to %s: %v", test.value, test.from, test.to, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 0.01 {
+			t.Errorf("%v %s = %v %s, want %v", test.value, test.from, got, test.to, test.want)
+		}
+	}
+}
+
+func TestConvertIncompatible(t *testing.T) {
+	tests := []struct {
+		from, to string
+	}{
+		{"feet", "knots"},
+		{"celsius", "psi"},
+		{"enum", "mask"},
+		{"enum", "number"},
+		{"Frequency BCD16", "MHz"},
+	}
+	for _, test := range tests {
+		if _, err := ConvertByName(1, test.from, test.to); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("%s to %s: err = %v, want ErrIncompatible", test.from, test.to, err)
+		}
+	}
+	if _, err := ConvertByName(1, "feet", "furlong"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("unknown unit: err = %v, want ErrUnknownUnit", err)
+	}
+}
+
+func TestEncodedUnits(t *testing.T) {
+	for _, name := range []string{"enum", "mask", "flags", "Frequency BCD16", "Frequency ADF BCD32", "Bco16"} {
+		unit := MustParse(name)
+		if unit.Dimension != Encoded {
+			t.Errorf("%s is %s, want encoded", name, unit.Dimension)
+		}
+		if !unit.Compatible(unit) {
+			t.Errorf("%s isn't compatible with itself", name)
+		}
+	}
+	if MustParse("number").Dimension != Dimensionless || !MustParse("bool").Compatible(MustParse("percent")) {
+		t.Error("bool, number and percent should be dimensionless and convert into each other")
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	for i, name := range names {
+		if !IsSimConnectName(name) {
+			t.Errorf("Names() lists %q which SimConnect doesn't know", name)
+		}
+		if i > 0 && names[i-1] >= name {
+			t.Errorf("Names() isn't sorted: %q before %q", names[i-1], name)
+		}
+	}
+	for _, unit := range Units() {
+		if !IsSimConnectName(unit.Name) {
+			t.Errorf("canonical name %q isn't accepted", unit.Name)
+		}
+	}
+}
@@ -1,25 +1,34 @@
 package simconnect
 
+import "strings"
+
 var (
-	dataTypeMapper map[string]DWord
+	dataTypeMapper     map[string]DWord
+	dataTypeNameMapper map[DWord]string
 )
 
 func init() {
 	dataTypeMapper = stringToDataTypeMapping()
+	dataTypeNameMapper = dataTypeToStringMapping()
 }
 
+// StringToDataType returns the DataType* for a name like "float64", "latlonalt" or "initposition".
+// The name is case insensitive and may carry a "SIMCONNECT_DATATYPE_", "SIMCONNECT_DATA_" or "STRUCT " prefix,
+// so the units of structured simvars such as "SIMCONNECT_DATA_LATLONALT" map to their datatype as well.
 func StringToDataType(dataType string) DWord {
-	if value, exists := dataTypeMapper[dataType]; exists {
+	name := strings.ToLower(strings.TrimSpace(dataType))
+	for _, prefix := range []string{"simconnect_datatype_", "simconnect_data_", "struct "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if value, exists := dataTypeMapper[name]; exists {
 		return value
 	}
 	return DataTypeInvalid
 }
 
 func DataTypeToString(dataType DWord) string {
-	for name, value := range dataTypeMapper {
-		if value == dataType {
-			return name
-		}
+	if name, exists := dataTypeNameMapper[dataType]; exists {
+		return name
 	}
 	return "invalid"
 }
@@ -49,6 +58,21 @@ func ValueToFloat64(value interface{}) float64 {
 	return value.(float64)
 }
 
+// ValueToNumber converts any numeric value as decoded by SimConnect (int32, int64, float32 or float64) to a float64.
+func ValueToNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
 func ValueToString(value interface{}) string {
 	return value.(string)
 }
@@ -70,7 +94,30 @@ func stringToDataTypeMapping() map[string]DWord {
 		"initposition": DataTypeInitPosition,
 		"markerstate":  DataTypeMarkerState,
 		"waypoint":     DataTypeWaypoint,
+		"latlonalt":    DataTypeLatLonAlt,
 		"latlongalt":   DataTypeLatLonAlt,
 		"xyz":          DataTypeXYZ,
 	}
 }
+
+func dataTypeToStringMapping() map[DWord]string {
+	return map[DWord]string{
+		DataTypeInvalid:      "invalid",
+		DataTypeInt32:        "int32",
+		DataTypeInt64:        "int64",
+		DataTypeFloat32:      "float32",
+		DataTypeFloat64:      "float64",
+		DataTypeString8:      "string8",
+		DataTypeString32:     "string32",
+		DataTypeString64:     "string64",
+		DataTypeString128:    "string128",
+		DataTypeString256:    "string256",
+		DataTypeString260:    "string260",
+		DataTypeStringV:      "stringv",
+		DataTypeInitPosition: "initposition",
+		DataTypeMarkerState:  "markerstate",
+		DataTypeWaypoint:     "waypoint",
+		DataTypeLatLonAlt:    "latlonalt",
+		DataTypeXYZ:          "xyz",
+	}
+}
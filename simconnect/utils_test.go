@@ -0,0 +1,42 @@
+package simconnect
+
+import "testing"
+
+func TestStringToDataType(t *testing.T) {
+	tests := []struct {
+		name string
+		want DWord
+	}{
+		{"float64", DataTypeFloat64},
+		{"FLOAT64", DataTypeFloat64},
+		{" stringv ", DataTypeStringV},
+		{"SIMCONNECT_DATATYPE_INT32", DataTypeInt32},
+		{"simconnect_datatype_initposition", DataTypeInitPosition},
+		{"SIMCONNECT_DATA_LATLONALT", DataTypeLatLonAlt},
+		{"SIMCONNECT_DATA_XYZ", DataTypeXYZ},
+		{"SIMCONNECT_DATA_WAYPOINT", DataTypeWaypoint},
+		{"STRUCT LATLONALT", DataTypeLatLonAlt},
+		{"latlongalt", DataTypeLatLonAlt},
+		{"MarkerState", DataTypeMarkerState},
+		{"struct", DataTypeInvalid},
+		{"float", DataTypeInvalid},
+		{"", DataTypeInvalid},
+	}
+	for _, test := range tests {
+		if got := StringToDataType(test.name); got != test.want {
+			t.Errorf("StringToDataType(%q) = %s, want %s", test.name, DataTypeToString(got), DataTypeToString(test.want))
+		}
+	}
+}
+
+func TestDataTypeToStringRoundTrip(t *testing.T) {
+	for dataType := DWord(DataTypeInvalid); dataType <= DataTypeXYZ; dataType++ {
+		name := DataTypeToString(dataType)
+		if got := StringToDataType(name); got != dataType {
+			t.Errorf("StringToDataType(DataTypeToString(%d)) = %d (%q)", dataType, got, name)
+		}
+	}
+	if name := DataTypeToString(DataTypeXYZ + 1); name != "invalid" {
+		t.Errorf("DataTypeToString of an unknown datatype = %q", name)
+	}
+}
@@ -0,0 +1,160 @@
+package simconnect
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// WatchEvent is passed to a WatchFunc when a watch rule fires.
+type WatchEvent struct {
+	DefineID DWord
+	Name     string
+	Old      float64   // the previous value
+	New      float64   // the value which fired the rule
+	Time     time.Time // sample time of the new value
+}
+
+// WatchFunc is called when a watch rule fires.
+type WatchFunc func(event WatchEvent)
+
+// WatchRule decides which values of a simvar fire a watch, see SimMate.WatchSimVar.
+// Rules keep state, so every watch needs a rule of its own.
+type WatchRule interface {
+	// Check is called with every new value and reports whether the watch fires.
+	// The first value of a simvar only initializes the rule and never fires.
+	Check(previous, value float64, first bool) bool
+}
+
+// WatchRuleFunc adapts a function to a WatchRule.
+type WatchRuleFunc func(previous, value float64, first bool) bool
+
+// Check calls the function.
+func (fn WatchRuleFunc) Check(previous, value float64, first bool) bool {
+	return fn(previous, value, first)
+}
+
+// OnChange fires when the value moves more than deadband away from the value it last fired at, or any change for 0.
+func OnChange(deadband float64) WatchRule {
+	var reference float64
+	return WatchRuleFunc(func(previous, value float64, first bool) bool {
+		if first || math.Abs(value-reference) > deadband {
+			reference = value
+			return !first
+		}
+		return false
+	})
+}
+
+// OnCrossAbove fires when the value rises above threshold. It fires again only after the value fell below
+// threshold-hysteresis, so a value jittering around the threshold doesn't fire over and over.
+func OnCrossAbove(threshold, hysteresis float64) WatchRule {
+	above := false
+	return WatchRuleFunc(func(previous, value float64, first bool) bool {
+		switch {
+		case first:
+			above = value > threshold
+		case !above && value > threshold:
+			above = true
+			return true
+		case above && value < threshold-hysteresis:
+			above = false
+		}
+		return false
+	})
+}
+
+// OnCrossBelow fires when the value falls below threshold. It fires again only after the value rose above
+// threshold+hysteresis.
+func OnCrossBelow(threshold, hysteresis float64) WatchRule {
+	below := false
+	return WatchRuleFunc(func(previous, value float64, first bool) bool {
+		switch {
+		case first:
+			below = value < threshold
+		case !below && value < threshold:
+			below = true
+			return true
+		case below && value > threshold+hysteresis:
+			below = false
+		}
+		return false
+	})
+}
+
+// OnEnterRange fires when the value enters the range [min, max].
+func OnEnterRange(min, max float64) WatchRule {
+	return rangeRule(min, max, true)
+}
+
+// OnLeaveRange fires when the value leaves the range [min, max].
+func OnLeaveRange(min, max float64) WatchRule {
+	return rangeRule(min, max, false)
+}
+
+func rangeRule(min, max float64, enter bool) WatchRule {
+	inside := false
+	return WatchRuleFunc(func(previous, value float64, first bool) bool {
+		was := inside
+		inside = value >= min && value <= max
+		return !first && was != inside && inside == enter
+	})
+}
+
+// OnRisingEdge fires when a boolean simvar turns true, i.e. from 0 to any other value.
+func OnRisingEdge() WatchRule {
+	return edgeRule(true, false)
+}
+
+// OnFallingEdge fires when a boolean simvar turns false.
+func OnFallingEdge() WatchRule {
+	return edgeRule(false, true)
+}
+
+// OnEdge fires when a boolean simvar turns true or false.
+func OnEdge() WatchRule {
+	return edgeRule(true, true)
+}
+
+func edgeRule(rising, falling bool) WatchRule {
+	return WatchRuleFunc(func(previous, value float64, first bool) bool {
+		if first {
+			return false
+		}
+		was, is := previous != 0, value != 0
+		return (rising && !was && is) || (falling && was && !is)
+	})
+}
+
+// WatchSimVar calls fn whenever a new value of the simvar fires the rule, e.g.
+//
+//	mate.WatchSimVar(altitudeID, OnCrossBelow(1000, 50), func(event WatchEvent) { callout("one thousand") })
+//
+// Values which aren't numbers are ignored. fn is called from the goroutine running the SimMate.
+// The returned function stops the watch.
+func (mate *SimMate) WatchSimVar(defineID DWord, rule WatchRule, fn WatchFunc) (func(), error) {
+	simVar, exists := mate.SimVar(defineID)
+	if !exists {
+		return nil, fmt.Errorf("unknown simvar %d", defineID)
+	}
+	first := true
+	var previous float64
+	return mate.watch(defineID, func(value interface{}, sampled time.Time) {
+		number, ok := ValueToNumber(value)
+		if !ok {
+			return
+		}
+		fire := rule.Check(previous, number, first)
+		old := previous
+		previous, first = number, false
+		if fire {
+			fn(WatchEvent{
+				DefineID: defineID,
+				Name:     simVar.Name,
+				Old:      old,
+				New:      number,
+				Time:     sampled,
+			})
+		}
+	}), nil
+}
@@ -0,0 +1,76 @@
+package simconnect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   WatchRule
+		values []float64
+		want   []bool
+	}{
+		{"change", OnChange(0), []float64{1, 1, 2, 2, 3}, []bool{false, false, true, false, true}},
+		{"deadband", OnChange(10), []float64{100, 105, 111, 115, 121, 90}, []bool{false, false, true, false, false, true}},
+		{"cross above", OnCrossAbove(100, 0), []float64{90, 101, 99, 101}, []bool{false, true, false, true}},
+		{"cross above starting above", OnCrossAbove(100, 0), []float64{110, 120, 90, 110}, []bool{false, false, false, true}},
+		{"cross above with hysteresis", OnCrossAbove(100, 10), []float64{90, 101, 95, 101, 89, 101}, []bool{false, true, false, false, false, true}},
+		{"cross below", OnCrossBelow(1000, 50), []float64{1200, 999, 1020, 990, 1051, 999}, []bool{false, true, false, false, false, true}},
+		{"enter range", OnEnterRange(10, 20), []float64{5, 10, 15, 21, 20}, []bool{false, true, false, false, true}},
+		{"enter range starting inside", OnEnterRange(10, 20), []float64{15, 16, 25, 15}, []bool{false, false, false, true}},
+		{"leave range", OnLeaveRange(10, 20), []float64{15, 20, 20.5, 30, 10, 9}, []bool{false, false, true, false, false, true}},
+		{"rising edge", OnRisingEdge(), []float64{1, 0, 1, 1, 0}, []bool{false, false, true, false, false}},
+		{"falling edge", OnFallingEdge(), []float64{1, 0, 1, 1, 0}, []bool{false, true, false, false, true}},
+		{"edge", OnEdge(), []float64{0, 0, 1, 1, 0}, []bool{false, false, true, false, true}},
+	}
+	for _, test := range tests {
+		var previous float64
+		for i, value := range test.values {
+			if got := test.rule.Check(previous, value, i == 0); got != test.want[i] {
+				t.Errorf("%s: value %d (%v) fired %v, want %v", test.name, i, value, got, test.want[i])
+			}
+			previous = value
+		}
+	}
+}
+
+func TestWatchSimVar(t *testing.T) {
+	m := newTestMate(t, nil)
+	altitude := m.AddSimVar("PLANE ALTITUDE", "feet", DataTypeFloat64)
+	if _, err := m.WatchSimVar(altitude+100, OnChange(0), func(WatchEvent) {}); err == nil {
+		t.Error("watched an unknown simvar")
+	}
+	var events []WatchEvent
+	stop, err := m.WatchSimVar(altitude, OnCrossBelow(1000, 50), func(event WatchEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []float64{1200, 990, 1020, 980} {
+		m.tick()
+		m.deliver(t, altitude, value)
+	}
+	if len(events) != 1 {
+		t.Fatalf("watch fired %d times, want 1", len(events))
+	}
+	event := events[0]
+	if event.DefineID != altitude || event.Name != "PLANE ALTITUDE" || event.Old != 1200 || event.New != 990 {
+		t.Errorf("event = %+v", event)
+	}
+	if sampled := m.clock.now.Add(-2 * time.Second); !event.Time.Equal(sampled) {
+		t.Errorf("event sampled at %s, want %s", event.Time, sampled)
+	}
+
+	stop()
+	m.tick()
+	m.deliver(t, altitude, float64(1100))
+	m.tick()
+	m.deliver(t, altitude, float64(900))
+	if len(events) != 1 {
+		t.Errorf("stopped watch fired %d more times", len(events)-1)
+	}
+}